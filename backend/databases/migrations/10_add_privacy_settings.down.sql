@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN private_profile;
+ALTER TABLE users DROP COLUMN show_email;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN show_email BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN private_profile BOOLEAN NOT NULL DEFAULT false;
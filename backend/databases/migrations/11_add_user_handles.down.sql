@@ -0,0 +1,3 @@
+DROP TABLE handle_history;
+DROP INDEX users_handle_idx ON users;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,28 @@
+ALTER TABLE users ADD COLUMN handle VARCHAR(30) NULL DEFAULT NULL;
+CREATE UNIQUE INDEX users_handle_idx ON users(handle);
+
+CREATE TABLE handle_history (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	handle VARCHAR(30) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(handle)
+);
+ALTER TABLE handle_history ADD CONSTRAINT handle_history_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- the oldest user with a username that is a valid handle keeps it, lowercased. The reserved words are the ones
+-- of services/handles.go, and user_<id> is left for the generated handles.
+UPDATE users JOIN (
+	SELECT MIN(id) AS id FROM users
+	WHERE deleted_at IS NULL AND username REGEXP '^[A-Za-z][A-Za-z0-9_]{2,29}$'
+		AND LOWER(username) NOT REGEXP '^user_[0-9]+$'
+		AND LOWER(username) NOT IN ('about', 'admin', 'administrator', 'api', 'auth', 'deleted', 'graphql',
+			'help', 'images', 'login', 'logout', 'me', 'moderator', 'null', 'playground', 'privacy', 'query',
+			'register', 'root', 'sales', 'security', 'settings', 'shotify', 'signup', 'staff', 'support',
+			'system', 'terms', 'undefined', 'user', 'users')
+	GROUP BY LOWER(username)
+) AS firsts ON users.id=firsts.id
+SET users.handle=LOWER(users.username);
+
+-- everyone else gets a generated handle, the id makes it unique
+UPDATE users SET handle=CONCAT('user_', id) WHERE handle IS NULL AND deleted_at IS NULL;
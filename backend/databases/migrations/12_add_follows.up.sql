@@ -0,0 +1,11 @@
+CREATE TABLE follows (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	follower_id int NOT NULL,
+	followed_id int NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(follower_id, followed_id)
+);
+ALTER TABLE follows ADD CONSTRAINT follow_follower_fkey FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE follows ADD CONSTRAINT follow_followed_fkey FOREIGN KEY (followed_id) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX follows_follower_idx ON follows(follower_id, created_at);
+CREATE INDEX follows_followed_idx ON follows(followed_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX users_created_idx ON users;
+DROP INDEX images_created_idx ON images;
+DROP INDEX sales_created_idx ON sales;
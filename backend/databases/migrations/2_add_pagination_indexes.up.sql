@@ -0,0 +1,3 @@
+CREATE INDEX users_created_idx ON users(created_at, id);
+CREATE INDEX images_created_idx ON images(created_at, id);
+CREATE INDEX sales_created_idx ON sales(created_at, id);
@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_enabled Boolean NOT NULL DEFAULT false;
+
+CREATE TABLE recovery_codes (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	code_hash CHAR(64) NOT NULL,
+	used Boolean NOT NULL DEFAULT false,
+	UNIQUE(user_id, code_hash)
+);
+
+ALTER TABLE recovery_codes ADD CONSTRAINT recovery_code_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+CREATE TABLE access_tokens (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	scopes VARCHAR(300) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP NULL DEFAULT NULL,
+	expires_at TIMESTAMP NULL DEFAULT NULL,
+	UNIQUE(token_hash)
+);
+
+ALTER TABLE access_tokens ADD CONSTRAINT access_token_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
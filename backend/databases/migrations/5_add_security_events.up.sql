@@ -0,0 +1,12 @@
+CREATE TABLE security_events (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	kind VARCHAR(50) NOT NULL,
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	user_agent VARCHAR(300) NOT NULL DEFAULT '',
+	details VARCHAR(300) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE security_events ADD CONSTRAINT security_event_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX security_events_user_idx ON security_events(user_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX users_deletion_idx ON users;
+ALTER TABLE users DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN deletion_requested_at;
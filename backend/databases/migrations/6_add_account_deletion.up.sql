@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMP NULL DEFAULT NULL;
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL;
+CREATE INDEX users_deletion_idx ON users(deletion_requested_at);
@@ -0,0 +1,14 @@
+CREATE TABLE passkeys (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	credential_id VARBINARY(255) NOT NULL UNIQUE,
+	public_key BLOB NOT NULL,
+	attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+	aaguid VARBINARY(16) NOT NULL,
+	sign_count INT UNSIGNED NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP NULL
+);
+
+ALTER TABLE passkeys ADD CONSTRAINT passkey_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,14 @@
+CREATE TABLE login_events (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	method VARCHAR(20) NOT NULL,
+	success BOOLEAN NOT NULL,
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	user_agent VARCHAR(300) NOT NULL DEFAULT '',
+	device CHAR(64) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE login_events ADD CONSTRAINT login_event_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX login_events_user_idx ON login_events(user_id, created_at);
+CREATE INDEX login_events_device_idx ON login_events(user_id, device);
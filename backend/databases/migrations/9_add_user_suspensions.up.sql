@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP NULL DEFAULT NULL;
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP NULL DEFAULT NULL;
+ALTER TABLE users ADD COLUMN suspension_reason VARCHAR(300) NOT NULL DEFAULT '';
@@ -33,13 +33,86 @@ type Sale struct {
 }
 
 type User struct {
+	ID          int       `db:"id"`
+	CreatedAt   time.Time `db:"created_at"`
+	Username    string    `db:"username"`
+	Role        string    `db:"role"`
+	Bio         string    `db:"bio"`
+	Avatar      string    `db:"avatar"`
+	Email       string    `db:"email"`
+	Password    string    `db:"password"`
+	Verfied     bool      `db:"verified"`
+	TotpSecret  string    `db:"totp_secret"`
+	TotpEnabled bool      `db:"totp_enabled"`
+	//TotpLastCounter is the time step of the last TOTP code accepted, so a code can't be used twice
+	TotpLastCounter int64 `db:"totp_last_counter"`
+	//DeletionRequestedAt is set while the account waits to be purged, DeletedAt once it was
+	DeletionRequestedAt *time.Time `db:"deletion_requested_at"`
+	DeletedAt           *time.Time `db:"deleted_at"`
+	//SuspendedAt is set while an admin suspended the account, without SuspendedUntil the account is banned
+	SuspendedAt      *time.Time `db:"suspended_at"`
+	SuspendedUntil   *time.Time `db:"suspended_until"`
+	SuspensionReason string     `db:"suspension_reason"`
+	//the privacy settings of the profile
+	ShowEmail      bool `db:"show_email"`
+	PrivateProfile bool `db:"private_profile"`
+	//Handle is the unique lowercase name of the profile, it is only missing on deleted accounts
+	Handle *string `db:"handle"`
+}
+
+//AccessToken is a personal access token, only the hash of the token is stored
+type AccessToken struct {
+	ID         int        `db:"id"`
+	UserID     int        `db:"user_id"`
+	Name       string     `db:"name"`
+	TokenHash  string     `db:"token_hash"`
+	Scopes     string     `db:"scopes"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+}
+
+//SecurityEvent is something suspicious that happened to an account, like a reused refresh token
+type SecurityEvent struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Kind      string    `db:"kind"`
+	IP        string    `db:"ip"`
+	UserAgent string    `db:"user_agent"`
+	Details   string    `db:"details"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+//LoginEvent is a login attempt on an account, Device is the fingerprint of the client it came from
+type LoginEvent struct {
 	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Method    string    `db:"method"`
+	Success   bool      `db:"success"`
+	IP        string    `db:"ip"`
+	UserAgent string    `db:"user_agent"`
+	Device    string    `db:"device"`
 	CreatedAt time.Time `db:"created_at"`
-	Username  string    `db:"username"`
-	Role      string    `db:"role"`
-	Bio       string    `db:"bio"`
-	Avatar    string    `db:"avatar"`
-	Email     string    `db:"email"`
-	Password  string    `db:"password"`
-	Verfied   bool      `db:"verified"`
+}
+
+//Passkey is a webauthn credential of a user, the public key is COSE encoded
+type Passkey struct {
+	ID              int        `db:"id"`
+	UserID          int        `db:"user_id"`
+	Name            string     `db:"name"`
+	CredentialID    []byte     `db:"credential_id"`
+	PublicKey       []byte     `db:"public_key"`
+	AttestationType string     `db:"attestation_type"`
+	AAGUID          []byte     `db:"aaguid"`
+	SignCount       uint32     `db:"sign_count"`
+	CreatedAt       time.Time  `db:"created_at"`
+	LastUsedAt      *time.Time `db:"last_used_at"`
+}
+
+//Follow is a user following another one to see their new images in the feed
+type Follow struct {
+	ID         int       `db:"id"`
+	FollowerID int       `db:"follower_id"`
+	FollowedID int       `db:"followed_id"`
+	CreatedAt  time.Time `db:"created_at"`
 }
@@ -3,8 +3,10 @@ package errors
 import (
 	"context"
 	"database/sql"
+	"math"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	_ "github.com/joho/godotenv/autoload"
@@ -19,8 +21,24 @@ var errCodeMap = map[int]string{
 	http.StatusNotFound:            "No results found",
 	http.StatusForbidden:           "You are trying to access a resource that doesn't belong to you",
 	http.StatusInternalServerError: "Sorry! There seems to be a problem on our end",
+	http.StatusTooManyRequests:     "Too many attempts, please try again later",
 }
 
+//RateLimitedType is the type extension of RateLimited errors so clients can tell them apart
+const RateLimitedType = "RATE_LIMITED"
+
+//UnverifiedType is the type extension of Unverified errors, the client should offer resendVerificationEmail
+const UnverifiedType = "UNVERIFIED"
+
+//InvalidPasswordType is the type extension of InvalidPassword errors, the failed rules are in the rules extension
+const InvalidPasswordType = "INVALID_PASSWORD"
+
+//HumanVerificationType is the type extension of HumanVerificationFailed errors, the client should show a captcha
+const HumanVerificationType = "HUMAN_VERIFICATION_FAILED"
+
+//SuspendedType is the type extension of Suspended errors, until is missing when the account is banned
+const SuspendedType = "SUSPENDED"
+
 func NewError(message string, code int) *gqlerror.Error {
 	newErr := &gqlerror.Error{
 		Message: errCodeMap[code],
@@ -125,6 +143,64 @@ func NotFound(message string) *gqlerror.Error {
 	return newErr
 }
 
+//RateLimited tells the client to back off, retryAfter is sent in seconds
+func RateLimited(message string, retryAfter time.Duration) *gqlerror.Error {
+	code := http.StatusTooManyRequests
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	newErr := &gqlerror.Error{
+		Message: errCodeMap[code],
+		Extensions: map[string]interface{}{
+			"code":       code,
+			"type":       RateLimitedType,
+			"retryAfter": seconds,
+		},
+	}
+	if os.Getenv(env) == "dev" {
+		newErr.Path = graphql.GetPath(context.Background())
+		newErr.Message = message
+	}
+	return newErr
+}
+
+//userError is an error the user can act on, unlike the errors above its message is sent as is in every env
+func userError(code int, errType string, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code": code,
+			"type": errType,
+		},
+	}
+}
+
+//Unverified rejects a user who hasn't confirmed their email yet
+func Unverified(message string) *gqlerror.Error {
+	newErr := userError(http.StatusForbidden, UnverifiedType, message)
+	newErr.Extensions["mutation"] = "resendVerificationEmail"
+	return newErr
+}
+
+//InvalidPassword is a BadRequest for a password the policy rejected, it lists the rules the password failed
+func InvalidPassword(message string, rules []string) *gqlerror.Error {
+	newErr := userError(http.StatusBadRequest, InvalidPasswordType, message)
+	newErr.Extensions["rules"] = rules
+	return newErr
+}
+
+//HumanVerificationFailed rejects a request without a valid captcha token
+func HumanVerificationFailed(message string) *gqlerror.Error {
+	return userError(http.StatusForbidden, HumanVerificationType, message)
+}
+
+//Suspended rejects a user whose account an admin suspended or banned
+func Suspended(message string, until *time.Time) *gqlerror.Error {
+	newErr := userError(http.StatusForbidden, SuspendedType, message)
+	if until != nil {
+		newErr.Extensions["until"] = until.UTC().Format(time.RFC3339)
+	}
+	return newErr
+}
+
 func DB(err error) *gqlerror.Error {
 	if err == sql.ErrNoRows {
 		return NotFound(err.Error())
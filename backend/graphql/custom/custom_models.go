@@ -30,12 +30,16 @@ type Sale struct {
 
 type User struct {
 	ID       string     `json:"id"`
+	Handle   string     `json:"handle"`
 	Username string     `json:"username"`
 	Email    string     `json:"email"`
 	Role     Role       `json:"role"`
 	Bio      string     `json:"bio"`
 	Avatar   string     `json:"avatar"`
 	Joined   *time.Time `json:"joined"`
+	//the privacy settings decide which fields other users see
+	ShowEmail      bool `json:"-"`
+	PrivateProfile bool `json:"-"`
 }
 
 type Role string
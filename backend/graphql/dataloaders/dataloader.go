@@ -74,13 +74,22 @@ func newUserByID(ctx context.Context, db *sqlx.DB) *UserLoader {
 			m := make(map[int]*custom.User, len(dbUsers))
 
 			for _, user := range dbUsers {
+				handle := ""
+				if user.Handle != nil {
+					handle = *user.Handle
+				}
+				//the user resolvers hide the private fields from other users
 				m[user.ID] = &custom.User{
-					ID:       fmt.Sprintf("%v", user.ID),
-					Username: user.Username,
-					Email:    user.Email,
-					Avatar:   user.Avatar,
-					Joined:   &user.CreatedAt,
-					Bio:      user.Bio,
+					ID:             fmt.Sprintf("%v", user.ID),
+					Username:       user.Username,
+					Handle:         handle,
+					Email:          user.Email,
+					Role:           custom.Role(user.Role),
+					Avatar:         user.Avatar,
+					Joined:         &user.CreatedAt,
+					Bio:            user.Bio,
+					ShowEmail:      user.ShowEmail,
+					PrivateProfile: user.PrivateProfile,
 				}
 			}
 
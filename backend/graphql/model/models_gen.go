@@ -6,10 +6,63 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/gasser707/go-gql-server/graphql/custom"
 )
 
+type AccessToken struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Scopes   []string   `json:"scopes"`
+	Created  time.Time  `json:"created"`
+	LastUsed *time.Time `json:"lastUsed"`
+	Expires  *time.Time `json:"expires"`
+}
+
+type AdminUser struct {
+	User             *custom.User `json:"user"`
+	Verified         bool         `json:"verified"`
+	TwoFactorEnabled bool         `json:"twoFactorEnabled"`
+	Suspension       *Suspension  `json:"suspension"`
+}
+
+type AdminUserConnection struct {
+	Edges      []*AdminUserEdge `json:"edges"`
+	PageInfo   *PageInfo        `json:"pageInfo"`
+	TotalCount int              `json:"totalCount"`
+}
+
+type AdminUserEdge struct {
+	Cursor string     `json:"cursor"`
+	Node   *AdminUser `json:"node"`
+}
+
+type AdminUserFilterInput struct {
+	Role      *Role   `json:"role"`
+	Email     *string `json:"email"`
+	Verified  *bool   `json:"verified"`
+	Suspended *bool   `json:"suspended"`
+}
+
+type DataExport struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Data        string `json:"data"`
+}
+
+type ImageConnection struct {
+	Edges      []*ImageEdge `json:"edges"`
+	PageInfo   *PageInfo    `json:"pageInfo"`
+	TotalCount int          `json:"totalCount"`
+}
+
+type ImageEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *custom.Image `json:"node"`
+}
+
 type ImageFilterInput struct {
 	ID                   *string         `json:"id"`
 	UserID               *string         `json:"userId"`
@@ -24,11 +77,37 @@ type ImageFilterInput struct {
 	Image                *graphql.Upload `json:"image"`
 }
 
+type LoginEvent struct {
+	ID        string      `json:"id"`
+	Method    LoginMethod `json:"method"`
+	Success   bool        `json:"success"`
+	IP        string      `json:"ip"`
+	UserAgent string      `json:"userAgent"`
+	Time      time.Time   `json:"time"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type LoginResult struct {
+	LoggedIn          bool    `json:"loggedIn"`
+	TwoFactorRequired bool    `json:"twoFactorRequired"`
+	ChallengeToken    *string `json:"challengeToken"`
+}
+
+type NewAccessToken struct {
+	Token       string       `json:"token"`
+	AccessToken *AccessToken `json:"accessToken"`
+}
+
+type NewAccessTokenInput struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays *int     `json:"expiresInDays"`
+}
+
 type NewImageInput struct {
 	Title           string         `json:"title"`
 	Description     string         `json:"description"`
@@ -41,11 +120,73 @@ type NewImageInput struct {
 }
 
 type NewUserInput struct {
-	Username string          `json:"username"`
-	Email    string          `json:"email"`
-	Password string          `json:"password"`
-	Bio      string          `json:"bio"`
-	Avatar   *graphql.Upload `json:"avatar"`
+	Username     string          `json:"username"`
+	Email        string          `json:"email"`
+	Password     string          `json:"password"`
+	Bio          string          `json:"bio"`
+	Avatar       *graphql.Upload `json:"avatar"`
+	CaptchaToken *string         `json:"captchaToken"`
+	Handle       *string         `json:"handle"`
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+type Passkey struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Created  time.Time  `json:"created"`
+	LastUsed *time.Time `json:"lastUsed"`
+}
+
+type PasskeyChallenge struct {
+	ChallengeID string `json:"challengeId"`
+	Options     string `json:"options"`
+}
+
+type PrivacySettings struct {
+	ShowEmail      bool `json:"showEmail"`
+	PrivateProfile bool `json:"privateProfile"`
+}
+
+type PrivacySettingsInput struct {
+	ShowEmail      *bool `json:"showEmail"`
+	PrivateProfile *bool `json:"privateProfile"`
+}
+
+type SaleConnection struct {
+	Edges      []*SaleEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type SaleEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *custom.Sale `json:"node"`
+}
+
+type Session struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"userAgent"`
+	IP        string    `json:"ip"`
+	Created   time.Time `json:"created"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Current   bool      `json:"current"`
+}
+
+type Suspension struct {
+	Reason string     `json:"reason"`
+	Since  time.Time  `json:"since"`
+	Until  *time.Time `json:"until"`
+}
+
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
 }
 
 type UpdateImageInput struct {
@@ -62,17 +203,72 @@ type UpdateImageInput struct {
 
 type UpdateUserInput struct {
 	Username string          `json:"username"`
-	Email    string          `json:"email"`
 	Bio      string          `json:"bio"`
 	Avatar   *graphql.Upload `json:"avatar"`
 }
 
+type UserConnection struct {
+	Edges      []*UserEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type UserEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *custom.User `json:"node"`
+}
+
 type UserFilterInput struct {
 	ID       *string `json:"id"`
 	Username *string `json:"username"`
 	Email    *string `json:"email"`
 }
 
+type LoginMethod string
+
+const (
+	LoginMethodPassword  LoginMethod = "PASSWORD"
+	LoginMethodMagicLink LoginMethod = "MAGIC_LINK"
+	LoginMethodTwoFactor LoginMethod = "TWO_FACTOR"
+	LoginMethodPasskey   LoginMethod = "PASSKEY"
+)
+
+var AllLoginMethod = []LoginMethod{
+	LoginMethodPassword,
+	LoginMethodMagicLink,
+	LoginMethodTwoFactor,
+	LoginMethodPasskey,
+}
+
+func (e LoginMethod) IsValid() bool {
+	switch e {
+	case LoginMethodPassword, LoginMethodMagicLink, LoginMethodTwoFactor, LoginMethodPasskey:
+		return true
+	}
+	return false
+}
+
+func (e LoginMethod) String() string {
+	return string(e)
+}
+
+func (e *LoginMethod) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LoginMethod(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LoginMethod", str)
+	}
+	return nil
+}
+
+func (e LoginMethod) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type Role string
 
 const (
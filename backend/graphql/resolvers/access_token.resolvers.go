@@ -0,0 +1,32 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/gasser707/go-gql-server/graphql/generated"
+	"github.com/gasser707/go-gql-server/graphql/model"
+)
+
+func (r *mutationResolver) CreateAccessToken(ctx context.Context, input model.NewAccessTokenInput) (*model.NewAccessToken, error) {
+	return r.AuthService.CreateAccessToken(ctx, input)
+}
+
+func (r *mutationResolver) RevokeAccessToken(ctx context.Context, id string) (bool, error) {
+	return r.AuthService.RevokeAccessToken(ctx, id)
+}
+
+func (r *queryResolver) MyAccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	return r.AuthService.GetAccessTokens(ctx)
+}
+
+// Mutation returns generated.MutationResolver implementation.
+func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
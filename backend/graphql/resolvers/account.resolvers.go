@@ -0,0 +1,23 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/gasser707/go-gql-server/graphql/model"
+)
+
+func (r *mutationResolver) RequestAccountDeletion(ctx context.Context, password string) (*time.Time, error) {
+	return r.AccountsService.RequestAccountDeletion(ctx, password)
+}
+
+func (r *mutationResolver) CancelAccountDeletion(ctx context.Context) (bool, error) {
+	return r.AccountsService.CancelAccountDeletion(ctx)
+}
+
+func (r *mutationResolver) ExportMyData(ctx context.Context) (*model.DataExport, error) {
+	return r.AccountsService.ExportMyData(ctx)
+}
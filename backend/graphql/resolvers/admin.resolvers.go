@@ -0,0 +1,35 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/gasser707/go-gql-server/graphql/model"
+)
+
+func (r *mutationResolver) SetUserRole(ctx context.Context, userID string, role model.Role) (*model.AdminUser, error) {
+	return r.AdminService.SetUserRole(ctx, userID, role)
+}
+
+func (r *mutationResolver) SuspendUser(ctx context.Context, userID string, reason string, until time.Time) (*model.AdminUser, error) {
+	return r.AdminService.SuspendUser(ctx, userID, reason, until)
+}
+
+func (r *mutationResolver) BanUser(ctx context.Context, userID string, reason string) (*model.AdminUser, error) {
+	return r.AdminService.BanUser(ctx, userID, reason)
+}
+
+func (r *mutationResolver) UnsuspendUser(ctx context.Context, userID string) (*model.AdminUser, error) {
+	return r.AdminService.UnsuspendUser(ctx, userID)
+}
+
+func (r *mutationResolver) ForceLogout(ctx context.Context, userID string) (bool, error) {
+	return r.AdminService.ForceLogout(ctx, userID)
+}
+
+func (r *queryResolver) AdminUsers(ctx context.Context, filter *model.AdminUserFilterInput, first *int, after *string, last *int, before *string) (*model.AdminUserConnection, error) {
+	return r.AdminService.GetUsers(ctx, filter, first, after, last, before)
+}
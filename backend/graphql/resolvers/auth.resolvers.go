@@ -6,11 +6,10 @@ package resolvers
 import (
 	"context"
 
-	"github.com/gasser707/go-gql-server/graphql/generated"
 	"github.com/gasser707/go-gql-server/graphql/model"
 )
 
-func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (bool, error) {
+func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (*model.LoginResult, error) {
 	return r.AuthService.Login(ctx, input)
 }
 
@@ -30,15 +29,70 @@ func (r *mutationResolver) ValidateUser(ctx context.Context, validationToken str
 	return r.AuthService.ValidateUser(ctx, validationToken)
 }
 
-func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
-	return r.AuthService.RequestPasswordReset(ctx, email)
+func (r *mutationResolver) ResendVerificationEmail(ctx context.Context, email string) (bool, error) {
+	return r.AuthService.ResendVerificationEmail(ctx, email)
+}
+
+func (r *mutationResolver) UnlockAccount(ctx context.Context, unlockToken string) (bool, error) {
+	return r.AuthService.UnlockAccount(ctx, unlockToken)
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string, captchaToken *string) (bool, error) {
+	return r.AuthService.RequestPasswordReset(ctx, email, captchaToken)
 }
 
 func (r *mutationResolver) ProcessPasswordReset(ctx context.Context, resetToken string, newPassword string) (bool, error) {
 	return r.AuthService.ProcessPasswordReset(ctx, resetToken, newPassword)
 }
 
-// Mutation returns generated.MutationResolver implementation.
-func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
+func (r *mutationResolver) RevokeSession(ctx context.Context, id string) (bool, error) {
+	return r.AuthService.RevokeSession(ctx, id)
+}
+
+func (r *mutationResolver) EnableTwoFactor(ctx context.Context) (*model.TwoFactorSetup, error) {
+	return r.AuthService.EnableTwoFactor(ctx)
+}
+
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	return r.AuthService.ConfirmTwoFactor(ctx, code)
+}
+
+func (r *mutationResolver) VerifyTwoFactor(ctx context.Context, challengeToken string, code string) (bool, error) {
+	return r.AuthService.VerifyTwoFactor(ctx, challengeToken, code)
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	return r.AuthService.DisableTwoFactor(ctx, code)
+}
+
+func (r *mutationResolver) ChangePassword(ctx context.Context, currentPassword string, newPassword string) (bool, error) {
+	return r.AuthService.ChangePassword(ctx, currentPassword, newPassword)
+}
+
+func (r *mutationResolver) RequestEmailChange(ctx context.Context, newEmail string, password string) (bool, error) {
+	return r.AuthService.RequestEmailChange(ctx, newEmail, password)
+}
+
+func (r *mutationResolver) ConfirmEmailChange(ctx context.Context, token string) (bool, error) {
+	return r.AuthService.ConfirmEmailChange(ctx, token)
+}
+
+func (r *mutationResolver) RequestMagicLink(ctx context.Context, email string) (bool, error) {
+	return r.AuthService.RequestMagicLink(ctx, email)
+}
 
-type mutationResolver struct{ *Resolver }
+func (r *mutationResolver) ConsumeMagicLink(ctx context.Context, token string) (*model.LoginResult, error) {
+	return r.AuthService.ConsumeMagicLink(ctx, token)
+}
+
+func (r *mutationResolver) ReportUnrecognizedLogin(ctx context.Context, token string) (bool, error) {
+	return r.AuthService.ReportUnrecognizedLogin(ctx, token)
+}
+
+func (r *queryResolver) MySessions(ctx context.Context) ([]*model.Session, error) {
+	return r.AuthService.GetSessions(ctx)
+}
+
+func (r *queryResolver) LoginHistory(ctx context.Context, limit *int) ([]*model.LoginEvent, error) {
+	return r.AuthService.GetLoginHistory(ctx, limit)
+}
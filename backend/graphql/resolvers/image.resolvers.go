@@ -33,15 +33,23 @@ func (r *mutationResolver) AutoGenerateLabels(ctx context.Context, id string) ([
 	return r.ImagesService.AutoGenerateLabels(ctx, id)
 }
 
-func (r *queryResolver) Images(ctx context.Context, input *model.ImageFilterInput) ([]*custom.Image, error) {
-	return r.ImagesService.GetImages(ctx, input)
+func (r *queryResolver) Images(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	return r.ImagesService.GetImagesPage(ctx, input, first, after, last, before)
+}
+
+func (r *queryResolver) PublicImages(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	return r.ImagesService.GetPublicImagesPage(ctx, input, first, after, last, before)
+}
+
+func (r *queryResolver) Image(ctx context.Context, id string) (*custom.Image, error) {
+	return r.ImagesService.GetImageById(ctx, id)
+}
+
+func (r *queryResolver) Feed(ctx context.Context, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	return r.ImagesService.GetFeed(ctx, first, after, last, before)
 }
 
 // Image returns generated.ImageResolver implementation.
 func (r *Resolver) Image() generated.ImageResolver { return &imageResolver{r} }
 
-// Query returns generated.QueryResolver implementation.
-func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
-
 type imageResolver struct{ *Resolver }
-type queryResolver struct{ *Resolver }
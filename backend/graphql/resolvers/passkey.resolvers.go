@@ -0,0 +1,34 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/gasser707/go-gql-server/graphql/model"
+)
+
+func (r *mutationResolver) BeginPasskeyRegistration(ctx context.Context) (*model.PasskeyChallenge, error) {
+	return r.AuthService.BeginPasskeyRegistration(ctx)
+}
+
+func (r *mutationResolver) FinishPasskeyRegistration(ctx context.Context, challengeID string, name string, credential string) (*model.Passkey, error) {
+	return r.AuthService.FinishPasskeyRegistration(ctx, challengeID, name, credential)
+}
+
+func (r *mutationResolver) DeletePasskey(ctx context.Context, id string) (bool, error) {
+	return r.AuthService.DeletePasskey(ctx, id)
+}
+
+func (r *mutationResolver) BeginPasskeyLogin(ctx context.Context, email string) (*model.PasskeyChallenge, error) {
+	return r.AuthService.BeginPasskeyLogin(ctx, email)
+}
+
+func (r *mutationResolver) FinishPasskeyLogin(ctx context.Context, challengeID string, credential string) (*model.LoginResult, error) {
+	return r.AuthService.FinishPasskeyLogin(ctx, challengeID, credential)
+}
+
+func (r *queryResolver) MyPasskeys(ctx context.Context) ([]*model.Passkey, error) {
+	return r.AuthService.GetPasskeys(ctx)
+}
@@ -14,10 +14,13 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	UsersService  services.UsersServiceInterface
-	ImagesService services.ImagesServiceInterface
-	AuthService   services.AuthServiceInterface
-	SaleService   sale_svc.SalesServiceInterface
-	EmailService  email_svc.EmailServiceInterface
-	DataLoaders   dataloaders.RetrieverInterface
+	UsersService    services.UsersServiceInterface
+	ImagesService   services.ImagesServiceInterface
+	AuthService     services.AuthServiceInterface
+	AccountsService services.AccountsServiceInterface
+	AdminService    services.AdminServiceInterface
+	FollowsService  services.FollowsServiceInterface
+	SaleService     sale_svc.SalesServiceInterface
+	EmailService    email_svc.EmailServiceInterface
+	DataLoaders     dataloaders.RetrieverInterface
 }
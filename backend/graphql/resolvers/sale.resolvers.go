@@ -9,14 +9,15 @@ import (
 
 	"github.com/gasser707/go-gql-server/graphql/custom"
 	"github.com/gasser707/go-gql-server/graphql/generated"
+	"github.com/gasser707/go-gql-server/graphql/model"
 )
 
 func (r *mutationResolver) BuyImage(ctx context.Context, id string) (*custom.Sale, error) {
 	return r.SaleService.BuyImage(ctx, id)
 }
 
-func (r *queryResolver) Sales(ctx context.Context) ([]*custom.Sale, error) {
-	return r.SaleService.GetSales(ctx)
+func (r *queryResolver) Sales(ctx context.Context, first *int, after *string, last *int, before *string) (*model.SaleConnection, error) {
+	return r.SaleService.GetSales(ctx, first, after, last, before)
 }
 
 func (r *saleResolver) Image(ctx context.Context, sale *custom.Sale) (*custom.Image, error) {
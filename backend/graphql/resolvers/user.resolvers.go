@@ -9,28 +9,98 @@ import (
 	"github.com/gasser707/go-gql-server/graphql/custom"
 	"github.com/gasser707/go-gql-server/graphql/generated"
 	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/services"
 )
 
 func (r *mutationResolver) RegisterUser(ctx context.Context, input model.NewUserInput) (*custom.User, error) {
-	return r.UsersService.RegisterUser(input)
+	return r.UsersService.RegisterUser(ctx, input)
 }
 
 func (r *mutationResolver) UpdateUser(ctx context.Context, input model.UpdateUserInput) (*custom.User, error) {
 	return r.UsersService.UpdateUser(ctx, input)
 }
 
-func (r *queryResolver) Users(ctx context.Context, input *model.UserFilterInput) ([]*custom.User, error) {
-	return r.UsersService.GetUsers(ctx, input)
+func (r *mutationResolver) UpdatePrivacySettings(ctx context.Context, input model.PrivacySettingsInput) (*custom.User, error) {
+	return r.UsersService.UpdatePrivacySettings(ctx, input)
 }
 
-func (r *userResolver) Role(ctx context.Context, user *custom.User) (model.Role, error) {
-	return model.Role(user.Role), nil
+func (r *mutationResolver) ChangeHandle(ctx context.Context, handle string) (*custom.User, error) {
+	return r.UsersService.ChangeHandle(ctx, handle)
+}
+
+func (r *mutationResolver) Follow(ctx context.Context, userID string) (*custom.User, error) {
+	return r.FollowsService.Follow(ctx, userID)
+}
+
+func (r *mutationResolver) Unfollow(ctx context.Context, userID string) (*custom.User, error) {
+	return r.FollowsService.Unfollow(ctx, userID)
+}
+
+func (r *queryResolver) Users(ctx context.Context, input *model.UserFilterInput, first *int, after *string, last *int, before *string) (*model.UserConnection, error) {
+	return r.UsersService.GetUsers(ctx, input, first, after, last, before)
+}
+
+func (r *queryResolver) Me(ctx context.Context) (*custom.User, error) {
+	return r.UsersService.GetMe(ctx)
+}
+
+func (r *queryResolver) UserProfile(ctx context.Context, username string) (*custom.User, error) {
+	return r.UsersService.GetUserProfile(ctx, username)
+}
+
+func (r *queryResolver) UserByHandle(ctx context.Context, handle string) (*custom.User, error) {
+	return r.UsersService.GetUserByHandle(ctx, handle)
+}
+
+func (r *userResolver) Email(ctx context.Context, user *custom.User) (*string, error) {
+	return services.VisibleEmail(ctx, user), nil
+}
+
+func (r *userResolver) Role(ctx context.Context, user *custom.User) (*model.Role, error) {
+	return services.VisibleRole(ctx, user), nil
+}
+
+func (r *userResolver) Bio(ctx context.Context, user *custom.User) (string, error) {
+	if !services.ProfileVisible(ctx, user) {
+		return "", nil
+	}
+	return user.Bio, nil
+}
+
+func (r *userResolver) Avatar(ctx context.Context, user *custom.User) (string, error) {
+	if !services.ProfileVisible(ctx, user) {
+		return "", nil
+	}
+	return user.Avatar, nil
 }
 
 func (r *userResolver) Images(ctx context.Context, user *custom.User) ([]*custom.Image, error) {
+	if !services.ProfileVisible(ctx, user) {
+		return []*custom.Image{}, nil
+	}
 	return r.ImagesService.GetImages(ctx, &model.ImageFilterInput{UserID: &user.ID})
 }
 
+func (r *userResolver) Privacy(ctx context.Context, user *custom.User) (*model.PrivacySettings, error) {
+	return services.VisiblePrivacySettings(ctx, user), nil
+}
+
+func (r *userResolver) Followers(ctx context.Context, user *custom.User, first *int, after *string, last *int, before *string) (*model.UserConnection, error) {
+	page, err := r.FollowsService.GetFollowers(ctx, user, first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+	return page.Connection(r.DataLoaders.Retrieve(ctx).UserByID.LoadAll(page.UserIDs))
+}
+
+func (r *userResolver) Following(ctx context.Context, user *custom.User, first *int, after *string, last *int, before *string) (*model.UserConnection, error) {
+	page, err := r.FollowsService.GetFollowing(ctx, user, first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+	return page.Connection(r.DataLoaders.Retrieve(ctx).UserByID.LoadAll(page.UserIDs))
+}
+
 // User returns generated.UserResolver implementation.
 func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
 
@@ -1,21 +1,168 @@
 package helpers
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
 	customErr "github.com/gasser707/go-gql-server/errors"
+	_ "github.com/joho/godotenv/autoload"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/semaphore"
 )
 
+//Argon2Params are the cost parameters of argon2id hashes, they are encoded in every hash so they can be
+//raised without invalidating the hashes made with the old ones
+type Argon2Params struct {
+	Memory  uint32 //in KiB
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+//DefaultArgon2Params are the minimum OWASP recommends for argon2id, a few of them fit in the memory of the pod
+var DefaultArgon2Params = Argon2Params{Memory: 19 * 1024, Time: 2, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+//argon2Params are the parameters new hashes are made with, PASSWORD_ARGON2_MEMORY (KiB), PASSWORD_ARGON2_TIME
+//and PASSWORD_ARGON2_THREADS override the defaults
+var argon2Params = argon2ParamsFromEnv()
+
+//argon2Budget is how much memory (KiB) the hashes computed at once may use, PASSWORD_ARGON2_MEMORY_BUDGET
+//overrides the default of two hashes with the default parameters. Logins wait for their turn instead of
+//getting the server killed for running out of memory.
+var argon2Budget = argon2BudgetFromEnv()
+
+var argon2Memory = semaphore.NewWeighted(argon2Budget)
+
+const argon2Prefix = "$argon2id$"
+
+func argon2ParamsFromEnv() Argon2Params {
+	params := DefaultArgon2Params
+	for env, field := range map[string]*uint32{
+		"PASSWORD_ARGON2_MEMORY": &params.Memory,
+		"PASSWORD_ARGON2_TIME":   &params.Time,
+	} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil || n == 0 {
+				log.Panicf("%s must be a positive number", env)
+			}
+			*field = uint32(n)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("PASSWORD_ARGON2_THREADS")); v != "" {
+		n, err := strconv.ParseUint(v, 10, 8)
+		if err != nil || n == 0 {
+			log.Panic("PASSWORD_ARGON2_THREADS must be a positive number")
+		}
+		params.Threads = uint8(n)
+	}
+	return params
+}
+
+func argon2BudgetFromEnv() int64 {
+	v := strings.TrimSpace(os.Getenv("PASSWORD_ARGON2_MEMORY_BUDGET"))
+	if v == "" {
+		return 2 * int64(DefaultArgon2Params.Memory)
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Panic("PASSWORD_ARGON2_MEMORY_BUDGET must be a positive number")
+	}
+	return n
+}
+
+//argon2Key computes the key once the memory it needs is free, a hash that needs more than the budget runs alone
+func argon2Key(password string, salt []byte, params Argon2Params) []byte {
+	weight := int64(params.Memory)
+	if weight > argon2Budget {
+		weight = argon2Budget
+	}
+	//Acquire only fails when its context is done
+	_ = argon2Memory.Acquire(context.Background(), weight)
+	defer argon2Memory.Release(weight)
+	return argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+}
+
+//HashPassword hashes the password with argon2id in the PHC string format:
+//$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return HashPasswordWith(password, argon2Params)
+}
+
+func HashPasswordWith(password string, params Argon2Params) (string, error) {
+	salt := make([]byte, params.SaltLen)
+	_, err := rand.Read(salt)
 	if err != nil {
-		return "", customErr.BadRequest(err.Error())
+		return "", customErr.Internal(err.Error())
 	}
-	return string(bytes), nil
+	key := argon2Key(password, salt, params)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version, params.Memory, params.Time,
+		params.Threads, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
+//CheckPasswordHash verifies the password against an argon2id hash, or a bcrypt hash made before argon2id
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	if !strings.HasPrefix(hash, argon2Prefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil
+	}
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	other := argon2Key(password, salt, *params)
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+//PasswordNeedsRehash tells if the hash wasn't made with argon2id and the current parameters,
+//it should be replaced the next time the password is known
+func PasswordNeedsRehash(hash string) bool {
+	params, salt, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory != argon2Params.Memory || params.Time != argon2Params.Time ||
+		params.Threads != argon2Params.Threads || params.KeyLen != argon2Params.KeyLen ||
+		uint32(len(salt)) != argon2Params.SaltLen
+}
+
+func decodeArgon2Hash(hash string) (*Argon2Params, []byte, []byte, error) {
+	//"", "argon2id", "v=19", "m=65536,t=3,p=2", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	params := &Argon2Params{}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(key))
+	return params, salt, key, nil
 }
 
 const UserIdKey = "userId"
+const UserRoleKey = "userRole"
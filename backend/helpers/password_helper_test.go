@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/semaphore"
+)
+
+type PasswordHelperTestSuite struct {
+	suite.Suite
+}
+
+//cheap parameters, the tests only care about the format
+var testParams = Argon2Params{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func (suite *PasswordHelperTestSuite) TestArgon2Hash() {
+	hash, err := HashPasswordWith("secret", testParams)
+	suite.Nil(err)
+	suite.True(strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	suite.True(CheckPasswordHash("secret", hash))
+	suite.False(CheckPasswordHash("Secret", hash))
+
+	//every hash has its own salt
+	other, err := HashPasswordWith("secret", testParams)
+	suite.Nil(err)
+	suite.NotEqual(hash, other)
+}
+
+func (suite *PasswordHelperTestSuite) TestBcryptHash() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	suite.Nil(err)
+	suite.True(CheckPasswordHash("secret", string(hash)))
+	suite.False(CheckPasswordHash("wrong", string(hash)))
+	suite.True(PasswordNeedsRehash(string(hash)))
+}
+
+func (suite *PasswordHelperTestSuite) TestNeedsRehash() {
+	current, err := HashPassword("secret")
+	suite.Nil(err)
+	suite.False(PasswordNeedsRehash(current))
+
+	//hashes made before the cost was raised still verify, but get replaced
+	old, err := HashPasswordWith("secret", testParams)
+	suite.Nil(err)
+	suite.True(CheckPasswordHash("secret", old))
+	suite.True(PasswordNeedsRehash(old))
+}
+
+func (suite *PasswordHelperTestSuite) TestHashesShareTheMemoryBudget() {
+	budget, memory := argon2Budget, argon2Memory
+	defer func() { argon2Budget, argon2Memory = budget, memory }()
+	argon2Budget = int64(testParams.Memory)
+	argon2Memory = semaphore.NewWeighted(argon2Budget)
+
+	//the budget is all in use, a hash waits for it
+	suite.True(argon2Memory.TryAcquire(argon2Budget))
+	done := make(chan bool)
+	go func() {
+		_, err := HashPasswordWith("secret", testParams)
+		suite.Nil(err)
+		done <- true
+	}()
+	select {
+	case <-done:
+		suite.Fail("the hash didn't wait for the memory")
+	case <-time.After(50 * time.Millisecond):
+	}
+	argon2Memory.Release(argon2Budget)
+	<-done
+
+	//a hash that needs more than the whole budget still runs
+	_, err := HashPasswordWith("secret", Argon2Params{Memory: 2 * testParams.Memory, Time: 1, Threads: 1,
+		SaltLen: 16, KeyLen: 32})
+	suite.Nil(err)
+}
+
+func (suite *PasswordHelperTestSuite) TestMalformedHash() {
+	for _, hash := range []string{"", "$argon2id$v=19$m=1024,t=1,p=1$salt", "$argon2id$v=18$m=1024,t=1,p=1$AAAA$AAAA",
+		"$argon2id$v=19$m=x,t=1,p=1$AAAA$AAAA"} {
+		suite.False(CheckPasswordHash("secret", hash))
+		suite.True(PasswordNeedsRehash(hash))
+	}
+}
+
+func TestPasswordHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordHelperTestSuite))
+}
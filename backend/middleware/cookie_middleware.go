@@ -18,6 +18,7 @@ var env = "ENV"
 type CookieAccess struct {
 	Writer        http.ResponseWriter
 	EncodedCookie string
+	DeviceId      string
 }
 
 // method to write cookie
@@ -49,6 +50,24 @@ func (ca *CookieAccess) SetCookie(at string, rt string, sm *securecookie.SecureC
 
 }
 
+//SetDeviceCookie gives the browser a random id it keeps for a year, logins are told apart by it
+func (ca *CookieAccess) SetDeviceCookie(deviceId string) {
+	cookie := &http.Cookie{
+		Name:     utils.DeviceCookieKey,
+		Value:    deviceId,
+		Path:     "/",
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		HttpOnly: true,
+		Expires:  time.Now().Add(time.Hour * 24 * 365),
+	}
+	if os.Getenv(env) == "dev" {
+		cookie.Secure = false
+	}
+	http.SetCookie(ca.Writer, cookie)
+	ca.DeviceId = deviceId
+}
+
 func setValInCtx(ctx *gin.Context, key string, val interface{}) {
 	newCtx := context.WithValue(ctx.Request.Context(), key, val)
 	ctx.Request = ctx.Request.WithContext(newCtx)
@@ -69,9 +88,15 @@ func CookieMiddleware() gin.HandlerFunc {
 		if err == nil {
 			encodedCookie = ca.Value
 		}
+		deviceId := ""
+		device, err := ctx.Request.Cookie(utils.DeviceCookieKey)
+		if err == nil {
+			deviceId = device.Value
+		}
 		cookieA := CookieAccess{
 			Writer:        ctx.Writer,
 			EncodedCookie: encodedCookie,
+			DeviceId:      deviceId,
 		}
 
 		// &cookieA is a pointer so any changes in future is changing cookieA is context
@@ -5,13 +5,18 @@ import (
 	customErr "github.com/gasser707/go-gql-server/errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"os"
+	"strings"
 )
 
 const headerKey = "header-name"
 
 type HeaderAccess struct {
-	Writer    http.ResponseWriter
-	CsrfToken string
+	Writer      http.ResponseWriter
+	CsrfToken   string
+	BearerToken string
+	UserAgent   string
+	IP          string
 }
 
 // method to write headers
@@ -27,12 +32,34 @@ func GetHeaderAccess(ctx context.Context) (*HeaderAccess, error) {
 	return ha, nil
 }
 
+//TrustProxies lets gin take the client ip from X-Forwarded-For only for requests coming from the comma separated
+//CIDRs in TRUSTED_PROXIES (the ingress), otherwise the ip is the address of the connection. The ip keys the rate
+//limits, sessions and login events, so a header the client controls must not pick it.
+func TrustProxies(r *gin.Engine) error {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return r.SetTrustedProxies(proxies)
+}
+
 func HeaderMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		token := ctx.Request.Header.Get("X-CSRF-Token")
+		bearer := ""
+		authorization := ctx.Request.Header.Get("Authorization")
+		if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+			bearer = strings.TrimSpace(authorization[7:])
+		}
 		headerAccess := HeaderAccess{
-			Writer:    ctx.Writer,
-			CsrfToken: token,
+			Writer:      ctx.Writer,
+			CsrfToken:   token,
+			BearerToken: bearer,
+			UserAgent:   ctx.Request.UserAgent(),
+			IP:          ctx.ClientIP(),
 		}
 
 		// &headerAccess is a pointer so any changes in future is changing cookieA is context
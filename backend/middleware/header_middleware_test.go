@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type HeaderMiddlewareTestSuite struct {
+	suite.Suite
+}
+
+//clientIp serves a request from remoteAddr through HeaderMiddleware and returns the ip the rate limits are keyed on
+func (suite *HeaderMiddlewareTestSuite) clientIp(trustedProxies string, remoteAddr string, forwardedFor string) string {
+	os.Setenv("TRUSTED_PROXIES", trustedProxies)
+	defer os.Unsetenv("TRUSTED_PROXIES")
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	suite.Nil(TrustProxies(r))
+	r.Use(HeaderMiddleware())
+	ip := ""
+	r.POST("/query", func(c *gin.Context) {
+		ha, err := GetHeaderAccess(c.Request.Context())
+		suite.Nil(err)
+		ip = ha.IP
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	return ip
+}
+
+func (suite *HeaderMiddlewareTestSuite) TestForgedForwardedForIsIgnored() {
+	suite.Equal("203.0.113.7", suite.clientIp("", "203.0.113.7:4000", ""))
+	suite.Equal("203.0.113.7", suite.clientIp("", "203.0.113.7:4000", "198.51.100.1"))
+	suite.Equal("203.0.113.7", suite.clientIp("", "203.0.113.7:4000", "198.51.100.2"))
+	//a client talking to the server directly isn't a proxy even with proxies configured
+	suite.Equal("203.0.113.7", suite.clientIp("10.0.0.0/8", "203.0.113.7:4000", "198.51.100.1"))
+}
+
+func (suite *HeaderMiddlewareTestSuite) TestTrustedProxyForwardsTheClientIp() {
+	suite.Equal("203.0.113.7", suite.clientIp("10.0.0.0/8", "10.1.2.3:4000", "203.0.113.7"))
+	//the client can prepend anything, the proxy appends the address it got the request from
+	suite.Equal("203.0.113.7", suite.clientIp("10.0.0.0/8", "10.1.2.3:4000", "198.51.100.1, 203.0.113.7"))
+}
+
+func TestHeaderMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(HeaderMiddlewareTestSuite))
+}
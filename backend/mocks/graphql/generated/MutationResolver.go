@@ -63,6 +63,52 @@ func (_m *MutationResolver) BuyImage(ctx context.Context, id string) (*custom.Sa
 	return r0, r1
 }
 
+// ConfirmTwoFactor provides a mock function with given fields: ctx, code
+func (_m *MutationResolver) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	ret := _m.Called(ctx, code)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAccessToken provides a mock function with given fields: ctx, input
+func (_m *MutationResolver) CreateAccessToken(ctx context.Context, input model.NewAccessTokenInput) (*model.NewAccessToken, error) {
+	ret := _m.Called(ctx, input)
+
+	var r0 *model.NewAccessToken
+	if rf, ok := ret.Get(0).(func(context.Context, model.NewAccessTokenInput) *model.NewAccessToken); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.NewAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.NewAccessTokenInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteImages provides a mock function with given fields: ctx, input
 func (_m *MutationResolver) DeleteImages(ctx context.Context, input []string) (bool, error) {
 	ret := _m.Called(ctx, input)
@@ -84,15 +130,61 @@ func (_m *MutationResolver) DeleteImages(ctx context.Context, input []string) (b
 	return r0, r1
 }
 
+// DisableTwoFactor provides a mock function with given fields: ctx, code
+func (_m *MutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	ret := _m.Called(ctx, code)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnableTwoFactor provides a mock function with given fields: ctx
+func (_m *MutationResolver) EnableTwoFactor(ctx context.Context) (*model.TwoFactorSetup, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *model.TwoFactorSetup
+	if rf, ok := ret.Get(0).(func(context.Context) *model.TwoFactorSetup); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TwoFactorSetup)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Login provides a mock function with given fields: ctx, input
-func (_m *MutationResolver) Login(ctx context.Context, input model.LoginInput) (bool, error) {
+func (_m *MutationResolver) Login(ctx context.Context, input model.LoginInput) (*model.LoginResult, error) {
 	ret := _m.Called(ctx, input)
 
-	var r0 bool
-	if rf, ok := ret.Get(0).(func(context.Context, model.LoginInput) bool); ok {
+	var r0 *model.LoginResult
+	if rf, ok := ret.Get(0).(func(context.Context, model.LoginInput) *model.LoginResult); ok {
 		r0 = rf(ctx, input)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LoginResult)
+		}
 	}
 
 	var r1 error
@@ -233,6 +325,69 @@ func (_m *MutationResolver) RequestPasswordReset(ctx context.Context, email stri
 	return r0, r1
 }
 
+// RevokeAccessToken provides a mock function with given fields: ctx, id
+func (_m *MutationResolver) RevokeAccessToken(ctx context.Context, id string) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: ctx, id
+func (_m *MutationResolver) RevokeSession(ctx context.Context, id string) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnlockAccount provides a mock function with given fields: ctx, unlockToken
+func (_m *MutationResolver) UnlockAccount(ctx context.Context, unlockToken string) (bool, error) {
+	ret := _m.Called(ctx, unlockToken)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, unlockToken)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, unlockToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateImage provides a mock function with given fields: ctx, input
 func (_m *MutationResolver) UpdateImage(ctx context.Context, input model.UpdateImageInput) (*custom.Image, error) {
 	ret := _m.Called(ctx, input)
@@ -322,3 +477,24 @@ func (_m *MutationResolver) ValidateUser(ctx context.Context, validationToken st
 
 	return r0, r1
 }
+
+// VerifyTwoFactor provides a mock function with given fields: ctx, challengeToken, code
+func (_m *MutationResolver) VerifyTwoFactor(ctx context.Context, challengeToken string, code string) (bool, error) {
+	ret := _m.Called(ctx, challengeToken, code)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, challengeToken, code)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, challengeToken, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
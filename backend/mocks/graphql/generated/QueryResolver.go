@@ -5,8 +5,6 @@ package mocks
 import (
 	context "context"
 
-	custom "github.com/gasser707/go-gql-server/graphql/custom"
-
 	mock "github.com/stretchr/testify/mock"
 
 	model "github.com/gasser707/go-gql-server/graphql/model"
@@ -17,22 +15,22 @@ type QueryResolver struct {
 	mock.Mock
 }
 
-// Images provides a mock function with given fields: ctx, input
-func (_m *QueryResolver) Images(ctx context.Context, input *model.ImageFilterInput) ([]*custom.Image, error) {
-	ret := _m.Called(ctx, input)
+// Images provides a mock function with given fields: ctx, input, first, after, last, before
+func (_m *QueryResolver) Images(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	ret := _m.Called(ctx, input, first, after, last, before)
 
-	var r0 []*custom.Image
-	if rf, ok := ret.Get(0).(func(context.Context, *model.ImageFilterInput) []*custom.Image); ok {
-		r0 = rf(ctx, input)
+	var r0 *model.ImageConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ImageFilterInput, *int, *string, *int, *string) *model.ImageConnection); ok {
+		r0 = rf(ctx, input, first, after, last, before)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*custom.Image)
+			r0 = ret.Get(0).(*model.ImageConnection)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *model.ImageFilterInput) error); ok {
-		r1 = rf(ctx, input)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ImageFilterInput, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, input, first, after, last, before)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,16 +38,16 @@ func (_m *QueryResolver) Images(ctx context.Context, input *model.ImageFilterInp
 	return r0, r1
 }
 
-// Sales provides a mock function with given fields: ctx
-func (_m *QueryResolver) Sales(ctx context.Context) ([]*custom.Sale, error) {
+// MyAccessTokens provides a mock function with given fields: ctx
+func (_m *QueryResolver) MyAccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
 	ret := _m.Called(ctx)
 
-	var r0 []*custom.Sale
-	if rf, ok := ret.Get(0).(func(context.Context) []*custom.Sale); ok {
+	var r0 []*model.AccessToken
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.AccessToken); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*custom.Sale)
+			r0 = ret.Get(0).([]*model.AccessToken)
 		}
 	}
 
@@ -63,22 +61,68 @@ func (_m *QueryResolver) Sales(ctx context.Context) ([]*custom.Sale, error) {
 	return r0, r1
 }
 
-// Users provides a mock function with given fields: ctx, input
-func (_m *QueryResolver) Users(ctx context.Context, input *model.UserFilterInput) ([]*custom.User, error) {
-	ret := _m.Called(ctx, input)
+// MySessions provides a mock function with given fields: ctx
+func (_m *QueryResolver) MySessions(ctx context.Context) ([]*model.Session, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*model.Session
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Session); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Sales provides a mock function with given fields: ctx, first, after, last, before
+func (_m *QueryResolver) Sales(ctx context.Context, first *int, after *string, last *int, before *string) (*model.SaleConnection, error) {
+	ret := _m.Called(ctx, first, after, last, before)
+
+	var r0 *model.SaleConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *string, *int, *string) *model.SaleConnection); ok {
+		r0 = rf(ctx, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SaleConnection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Users provides a mock function with given fields: ctx, input, first, after, last, before
+func (_m *QueryResolver) Users(ctx context.Context, input *model.UserFilterInput, first *int, after *string, last *int, before *string) (*model.UserConnection, error) {
+	ret := _m.Called(ctx, input, first, after, last, before)
 
-	var r0 []*custom.User
-	if rf, ok := ret.Get(0).(func(context.Context, *model.UserFilterInput) []*custom.User); ok {
-		r0 = rf(ctx, input)
+	var r0 *model.UserConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *model.UserFilterInput, *int, *string, *int, *string) *model.UserConnection); ok {
+		r0 = rf(ctx, input, first, after, last, before)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*custom.User)
+			r0 = ret.Get(0).(*model.UserConnection)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *model.UserFilterInput) error); ok {
-		r1 = rf(ctx, input)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.UserFilterInput, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, input, first, after, last, before)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,124 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	databases "github.com/gasser707/go-gql-server/databases/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AccessTokensRepoInterface is an autogenerated mock type for the AccessTokensRepoInterface type
+type AccessTokensRepoInterface struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: token
+func (_m *AccessTokensRepoInterface) Create(token *databases.AccessToken) (int64, error) {
+	ret := _m.Called(token)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(*databases.AccessToken) int64); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*databases.AccessToken) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id, userId
+func (_m *AccessTokensRepoInterface) Delete(id int, userId int) error {
+	ret := _m.Called(id, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(id, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllByUser provides a mock function with given fields: userId
+func (_m *AccessTokensRepoInterface) DeleteAllByUser(userId int) error {
+	ret := _m.Called(userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllByUser provides a mock function with given fields: userId
+func (_m *AccessTokensRepoInterface) GetAllByUser(userId int) ([]databases.AccessToken, error) {
+	ret := _m.Called(userId)
+
+	var r0 []databases.AccessToken
+	if rf, ok := ret.Get(0).(func(int) []databases.AccessToken); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.AccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByHash provides a mock function with given fields: hash
+func (_m *AccessTokensRepoInterface) GetByHash(hash string) (*databases.AccessToken, error) {
+	ret := _m.Called(hash)
+
+	var r0 *databases.AccessToken
+	if rf, ok := ret.Get(0).(func(string) *databases.AccessToken); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*databases.AccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateLastUsed provides a mock function with given fields: id, lastUsed
+func (_m *AccessTokensRepoInterface) UpdateLastUsed(id int, lastUsed time.Time) error {
+	ret := _m.Called(id, lastUsed)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, time.Time) error); ok {
+		r0 = rf(id, lastUsed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,172 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	databases "github.com/gasser707/go-gql-server/databases/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AccountsRepoInterface is an autogenerated mock type for the AccountsRepoInterface type
+type AccountsRepoInterface struct {
+	mock.Mock
+}
+
+// AnonymizeUser provides a mock function with given fields: id
+func (_m *AccountsRepoInterface) AnonymizeUser(id int) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserById provides a mock function with given fields: id
+func (_m *AccountsRepoInterface) GetUserById(id int) (*databases.User, error) {
+	ret := _m.Called(id)
+
+	var r0 *databases.User
+	if rf, ok := ret.Get(0).(func(int) *databases.User); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*databases.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserImages provides a mock function with given fields: userId
+func (_m *AccountsRepoInterface) GetUserImages(userId int) ([]databases.Image, error) {
+	ret := _m.Called(userId)
+
+	var r0 []databases.Image
+	if rf, ok := ret.Get(0).(func(int) []databases.Image); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserLabels provides a mock function with given fields: userId
+func (_m *AccountsRepoInterface) GetUserLabels(userId int) ([]databases.Label, error) {
+	ret := _m.Called(userId)
+
+	var r0 []databases.Label
+	if rf, ok := ret.Get(0).(func(int) []databases.Label); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.Label)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserSales provides a mock function with given fields: userId
+func (_m *AccountsRepoInterface) GetUserSales(userId int) ([]databases.Sale, error) {
+	ret := _m.Called(userId)
+
+	var r0 []databases.Sale
+	if rf, ok := ret.Get(0).(func(int) []databases.Sale); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.Sale)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsersDueForPurge provides a mock function with given fields: requestedBefore
+func (_m *AccountsRepoInterface) GetUsersDueForPurge(requestedBefore time.Time) ([]databases.User, error) {
+	ret := _m.Called(requestedBefore)
+
+	var r0 []databases.User
+	if rf, ok := ret.Get(0).(func(time.Time) []databases.User); ok {
+		r0 = rf(requestedBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(requestedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeImage provides a mock function with given fields: imgId
+func (_m *AccountsRepoInterface) PurgeImage(imgId int) error {
+	ret := _m.Called(imgId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(imgId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetDeletionRequestedAt provides a mock function with given fields: id, at
+func (_m *AccountsRepoInterface) SetDeletionRequestedAt(id int, at *time.Time) error {
+	ret := _m.Called(id, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, *time.Time) error); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
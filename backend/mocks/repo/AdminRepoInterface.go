@@ -0,0 +1,114 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	databases "github.com/gasser707/go-gql-server/databases/models"
+	mock "github.com/stretchr/testify/mock"
+
+	repo "github.com/gasser707/go-gql-server/repo"
+
+	time "time"
+)
+
+// AdminRepoInterface is an autogenerated mock type for the AdminRepoInterface type
+type AdminRepoInterface struct {
+	mock.Mock
+}
+
+// CreateSecurityEvent provides a mock function with given fields: event
+func (_m *AdminRepoInterface) CreateSecurityEvent(event *databases.SecurityEvent) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*databases.SecurityEvent) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserById provides a mock function with given fields: id
+func (_m *AdminRepoInterface) GetUserById(id int) (*databases.User, error) {
+	ret := _m.Called(id)
+
+	var r0 *databases.User
+	if rf, ok := ret.Get(0).(func(int) *databases.User); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*databases.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsersPage provides a mock function with given fields: filter, page
+func (_m *AdminRepoInterface) GetUsersPage(filter *repo.AdminUserFilter, page *repo.Page) ([]databases.User, *repo.PageInfo, error) {
+	ret := _m.Called(filter, page)
+
+	var r0 []databases.User
+	if rf, ok := ret.Get(0).(func(*repo.AdminUserFilter, *repo.Page) []databases.User); ok {
+		r0 = rf(filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.User)
+		}
+	}
+
+	var r1 *repo.PageInfo
+	if rf, ok := ret.Get(1).(func(*repo.AdminUserFilter, *repo.Page) *repo.PageInfo); ok {
+		r1 = rf(filter, page)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repo.PageInfo)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*repo.AdminUserFilter, *repo.Page) error); ok {
+		r2 = rf(filter, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateRole provides a mock function with given fields: id, role
+func (_m *AdminRepoInterface) UpdateRole(id int, role string) error {
+	ret := _m.Called(id, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, string) error); ok {
+		r0 = rf(id, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSuspension provides a mock function with given fields: id, at, until, reason
+func (_m *AdminRepoInterface) UpdateSuspension(id int, at *time.Time, until *time.Time, reason string) error {
+	ret := _m.Called(id, at, until, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, *time.Time, *time.Time, string) error); ok {
+		r0 = rf(id, at, until, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
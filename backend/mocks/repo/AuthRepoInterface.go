@@ -12,6 +12,78 @@ type AuthRepoInterface struct {
 	mock.Mock
 }
 
+// CountByEmail provides a mock function with given fields: email
+func (_m *AuthRepoInterface) CountByEmail(email string) (int, error) {
+	ret := _m.Called(email)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateLoginEvent provides a mock function with given fields: event
+func (_m *AuthRepoInterface) CreateLoginEvent(event *databases.LoginEvent) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*databases.LoginEvent) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSecurityEvent provides a mock function with given fields: event
+func (_m *AuthRepoInterface) CreateSecurityEvent(event *databases.SecurityEvent) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*databases.SecurityEvent) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLoginEvents provides a mock function with given fields: id, limit
+func (_m *AuthRepoInterface) GetLoginEvents(id string, limit int) ([]databases.LoginEvent, error) {
+	ret := _m.Called(id, limit)
+
+	var r0 []databases.LoginEvent
+	if rf, ok := ret.Get(0).(func(string, int) []databases.LoginEvent); ok {
+		r0 = rf(id, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.LoginEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(id, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByEmail provides a mock function with given fields: email
 func (_m *AuthRepoInterface) GetUserByEmail(email string) (*databases.User, error) {
 	ret := _m.Called(email)
@@ -35,6 +107,78 @@ func (_m *AuthRepoInterface) GetUserByEmail(email string) (*databases.User, erro
 	return r0, r1
 }
 
+// GetUserById provides a mock function with given fields: id
+func (_m *AuthRepoInterface) GetUserById(id string) (*databases.User, error) {
+	ret := _m.Called(id)
+
+	var r0 *databases.User
+	if rf, ok := ret.Get(0).(func(string) *databases.User); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*databases.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsNewLoginDevice provides a mock function with given fields: id, device
+func (_m *AuthRepoInterface) IsNewLoginDevice(id string, device string) (bool, error) {
+	ret := _m.Called(id, device)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(id, device)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(id, device)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceRecoveryCodes provides a mock function with given fields: id, hashes
+func (_m *AuthRepoInterface) ReplaceRecoveryCodes(id string, hashes []string) error {
+	ret := _m.Called(id, hashes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(id, hashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateEmail provides a mock function with given fields: id, email
+func (_m *AuthRepoInterface) UpdateEmail(id string, email string) error {
+	ret := _m.Called(id, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(id, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePassword provides a mock function with given fields: id, password
 func (_m *AuthRepoInterface) UpdatePassword(id string, password string) error {
 	ret := _m.Called(id, password)
@@ -49,6 +193,20 @@ func (_m *AuthRepoInterface) UpdatePassword(id string, password string) error {
 	return r0
 }
 
+// UpdateTwoFactor provides a mock function with given fields: id, secret, enabled
+func (_m *AuthRepoInterface) UpdateTwoFactor(id string, secret string, enabled bool) error {
+	ret := _m.Called(id, secret, enabled)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, bool) error); ok {
+		r0 = rf(id, secret, enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateVerified provides a mock function with given fields: id
 func (_m *AuthRepoInterface) UpdateVerified(id string) error {
 	ret := _m.Called(id)
@@ -62,3 +220,45 @@ func (_m *AuthRepoInterface) UpdateVerified(id string) error {
 
 	return r0
 }
+
+// UseRecoveryCode provides a mock function with given fields: id, hash
+func (_m *AuthRepoInterface) UseRecoveryCode(id string, hash string) (bool, error) {
+	ret := _m.Called(id, hash)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(id, hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(id, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseTotpCounter provides a mock function with given fields: id, counter
+func (_m *AuthRepoInterface) UseTotpCounter(id string, counter int64) (bool, error) {
+	ret := _m.Called(id, counter)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, int64) bool); ok {
+		r0 = rf(id, counter)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(id, counter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
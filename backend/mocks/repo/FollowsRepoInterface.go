@@ -0,0 +1,130 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	databases "github.com/gasser707/go-gql-server/databases/models"
+	mock "github.com/stretchr/testify/mock"
+
+	repo "github.com/gasser707/go-gql-server/repo"
+)
+
+// FollowsRepoInterface is an autogenerated mock type for the FollowsRepoInterface type
+type FollowsRepoInterface struct {
+	mock.Mock
+}
+
+// Follow provides a mock function with given fields: followerId, followedId
+func (_m *FollowsRepoInterface) Follow(followerId int, followedId int) error {
+	ret := _m.Called(followerId, followedId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(followerId, followedId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFollowersPage provides a mock function with given fields: userId, page
+func (_m *FollowsRepoInterface) GetFollowersPage(userId int, page *repo.Page) ([]databases.Follow, *repo.PageInfo, error) {
+	ret := _m.Called(userId, page)
+
+	var r0 []databases.Follow
+	if rf, ok := ret.Get(0).(func(int, *repo.Page) []databases.Follow); ok {
+		r0 = rf(userId, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.Follow)
+		}
+	}
+
+	var r1 *repo.PageInfo
+	if rf, ok := ret.Get(1).(func(int, *repo.Page) *repo.PageInfo); ok {
+		r1 = rf(userId, page)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repo.PageInfo)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int, *repo.Page) error); ok {
+		r2 = rf(userId, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetFollowingPage provides a mock function with given fields: userId, page
+func (_m *FollowsRepoInterface) GetFollowingPage(userId int, page *repo.Page) ([]databases.Follow, *repo.PageInfo, error) {
+	ret := _m.Called(userId, page)
+
+	var r0 []databases.Follow
+	if rf, ok := ret.Get(0).(func(int, *repo.Page) []databases.Follow); ok {
+		r0 = rf(userId, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.Follow)
+		}
+	}
+
+	var r1 *repo.PageInfo
+	if rf, ok := ret.Get(1).(func(int, *repo.Page) *repo.PageInfo); ok {
+		r1 = rf(userId, page)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repo.PageInfo)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int, *repo.Page) error); ok {
+		r2 = rf(userId, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetUserById provides a mock function with given fields: id
+func (_m *FollowsRepoInterface) GetUserById(id int) (*databases.User, error) {
+	ret := _m.Called(id)
+
+	var r0 *databases.User
+	if rf, ok := ret.Get(0).(func(int) *databases.User); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*databases.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Unfollow provides a mock function with given fields: followerId, followedId
+func (_m *FollowsRepoInterface) Unfollow(followerId int, followedId int) error {
+	ret := _m.Called(followerId, followedId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(followerId, followedId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -3,10 +3,10 @@
 package mocks
 
 import (
-	context "context"
-
 	databases "github.com/gasser707/go-gql-server/databases/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repo "github.com/gasser707/go-gql-server/repo"
 )
 
 // ImagesRepoInterface is an autogenerated mock type for the ImagesRepoInterface type
@@ -84,35 +84,12 @@ func (_m *ImagesRepoInterface) DeleteImageLabels(imgId int) error {
 	return r0
 }
 
-// GetAllPublic provides a mock function with given fields: ctx
-func (_m *ImagesRepoInterface) GetAllPublic(ctx context.Context) ([]*databases.Image, error) {
-	ret := _m.Called(ctx)
-
-	var r0 []*databases.Image
-	if rf, ok := ret.Get(0).(func(context.Context) []*databases.Image); ok {
-		r0 = rf(ctx)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*databases.Image)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
 // GetByFilter provides a mock function with given fields: filter
-func (_m *ImagesRepoInterface) GetByFilter(filter string) ([]*databases.Image, error) {
+func (_m *ImagesRepoInterface) GetByFilter(filter *repo.ImageFilter) ([]*databases.Image, error) {
 	ret := _m.Called(filter)
 
 	var r0 []*databases.Image
-	if rf, ok := ret.Get(0).(func(string) []*databases.Image); ok {
+	if rf, ok := ret.Get(0).(func(*repo.ImageFilter) []*databases.Image); ok {
 		r0 = rf(filter)
 	} else {
 		if ret.Get(0) != nil {
@@ -121,7 +98,7 @@ func (_m *ImagesRepoInterface) GetByFilter(filter string) ([]*databases.Image, e
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
+	if rf, ok := ret.Get(1).(func(*repo.ImageFilter) error); ok {
 		r1 = rf(filter)
 	} else {
 		r1 = ret.Error(1)
@@ -208,6 +185,38 @@ func (_m *ImagesRepoInterface) GetImageLabels(imgId int) ([]string, error) {
 	return r0, r1
 }
 
+// GetPage provides a mock function with given fields: filter, page
+func (_m *ImagesRepoInterface) GetPage(filter *repo.ImageFilter, page *repo.Page) ([]*databases.Image, *repo.PageInfo, error) {
+	ret := _m.Called(filter, page)
+
+	var r0 []*databases.Image
+	if rf, ok := ret.Get(0).(func(*repo.ImageFilter, *repo.Page) []*databases.Image); ok {
+		r0 = rf(filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*databases.Image)
+		}
+	}
+
+	var r1 *repo.PageInfo
+	if rf, ok := ret.Get(1).(func(*repo.ImageFilter, *repo.Page) *repo.PageInfo); ok {
+		r1 = rf(filter, page)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repo.PageInfo)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*repo.ImageFilter, *repo.Page) error); ok {
+		r2 = rf(filter, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // InsertImageLabels provides a mock function with given fields: imgId, labels
 func (_m *ImagesRepoInterface) InsertImageLabels(imgId int, labels []*databases.Label) error {
 	ret := _m.Called(imgId, labels)
@@ -0,0 +1,87 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	databases "github.com/gasser707/go-gql-server/databases/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// PasskeysRepoInterface is an autogenerated mock type for the PasskeysRepoInterface type
+type PasskeysRepoInterface struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: passkey
+func (_m *PasskeysRepoInterface) Create(passkey *databases.Passkey) (int64, error) {
+	ret := _m.Called(passkey)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(*databases.Passkey) int64); ok {
+		r0 = rf(passkey)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*databases.Passkey) error); ok {
+		r1 = rf(passkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id, userId
+func (_m *PasskeysRepoInterface) Delete(id int, userId int) error {
+	ret := _m.Called(id, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(id, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllByUser provides a mock function with given fields: userId
+func (_m *PasskeysRepoInterface) GetAllByUser(userId int) ([]databases.Passkey, error) {
+	ret := _m.Called(userId)
+
+	var r0 []databases.Passkey
+	if rf, ok := ret.Get(0).(func(int) []databases.Passkey); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.Passkey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSignCount provides a mock function with given fields: id, signCount, lastUsed
+func (_m *PasskeysRepoInterface) UpdateSignCount(id int, signCount uint32, lastUsed time.Time) error {
+	ret := _m.Called(id, signCount, lastUsed)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, uint32, time.Time) error); ok {
+		r0 = rf(id, signCount, lastUsed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
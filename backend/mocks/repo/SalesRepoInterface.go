@@ -5,6 +5,8 @@ package mocks
 import (
 	databases "github.com/gasser707/go-gql-server/databases/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repo "github.com/gasser707/go-gql-server/repo"
 )
 
 // SalesRepoInterface is an autogenerated mock type for the SalesRepoInterface type
@@ -33,22 +35,22 @@ func (_m *SalesRepoInterface) Create(sale *databases.Sale) (int64, error) {
 	return r0, r1
 }
 
-// GetAll provides a mock function with given fields: userId
-func (_m *SalesRepoInterface) GetAll(userId int) ([]databases.Sale, error) {
-	ret := _m.Called(userId)
+// GetImageById provides a mock function with given fields: imgId, userId
+func (_m *SalesRepoInterface) GetImageById(imgId int, userId int) (*databases.Image, error) {
+	ret := _m.Called(imgId, userId)
 
-	var r0 []databases.Sale
-	if rf, ok := ret.Get(0).(func(int) []databases.Sale); ok {
-		r0 = rf(userId)
+	var r0 *databases.Image
+	if rf, ok := ret.Get(0).(func(int, int) *databases.Image); ok {
+		r0 = rf(imgId, userId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]databases.Sale)
+			r0 = ret.Get(0).(*databases.Image)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(userId)
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(imgId, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -56,25 +58,34 @@ func (_m *SalesRepoInterface) GetAll(userId int) ([]databases.Sale, error) {
 	return r0, r1
 }
 
-// GetImageById provides a mock function with given fields: imgId, userId
-func (_m *SalesRepoInterface) GetImageById(imgId int, userId int) (*databases.Image, error) {
-	ret := _m.Called(imgId, userId)
+// GetPage provides a mock function with given fields: userId, page
+func (_m *SalesRepoInterface) GetPage(userId int, page *repo.Page) ([]databases.Sale, *repo.PageInfo, error) {
+	ret := _m.Called(userId, page)
 
-	var r0 *databases.Image
-	if rf, ok := ret.Get(0).(func(int, int) *databases.Image); ok {
-		r0 = rf(imgId, userId)
+	var r0 []databases.Sale
+	if rf, ok := ret.Get(0).(func(int, *repo.Page) []databases.Sale); ok {
+		r0 = rf(userId, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*databases.Image)
+			r0 = ret.Get(0).([]databases.Sale)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(int, int) error); ok {
-		r1 = rf(imgId, userId)
+	var r1 *repo.PageInfo
+	if rf, ok := ret.Get(1).(func(int, *repo.Page) *repo.PageInfo); ok {
+		r1 = rf(userId, page)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repo.PageInfo)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int, *repo.Page) error); ok {
+		r2 = rf(userId, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
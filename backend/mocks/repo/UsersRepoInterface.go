@@ -5,6 +5,8 @@ package mocks
 import (
 	databases "github.com/gasser707/go-gql-server/databases/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repo "github.com/gasser707/go-gql-server/repo"
 )
 
 // UsersRepoInterface is an autogenerated mock type for the UsersRepoInterface type
@@ -12,6 +14,20 @@ type UsersRepoInterface struct {
 	mock.Mock
 }
 
+// ChangeHandle provides a mock function with given fields: id, handle
+func (_m *UsersRepoInterface) ChangeHandle(id int, handle string) error {
+	ret := _m.Called(id, handle)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, string) error); ok {
+		r0 = rf(id, handle)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CountByEmail provides a mock function with given fields: email
 func (_m *UsersRepoInterface) CountByEmail(email string) (int, error) {
 	ret := _m.Called(email)
@@ -54,22 +70,22 @@ func (_m *UsersRepoInterface) Create(insertedUser *databases.User) (int64, error
 	return r0, r1
 }
 
-// GetAll provides a mock function with given fields:
-func (_m *UsersRepoInterface) GetAll() ([]databases.User, error) {
-	ret := _m.Called()
+// GetByEmail provides a mock function with given fields: email
+func (_m *UsersRepoInterface) GetByEmail(email string) (*databases.User, error) {
+	ret := _m.Called(email)
 
-	var r0 []databases.User
-	if rf, ok := ret.Get(0).(func() []databases.User); ok {
-		r0 = rf()
+	var r0 *databases.User
+	if rf, ok := ret.Get(0).(func(string) *databases.User); ok {
+		r0 = rf(email)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]databases.User)
+			r0 = ret.Get(0).(*databases.User)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -77,13 +93,13 @@ func (_m *UsersRepoInterface) GetAll() ([]databases.User, error) {
 	return r0, r1
 }
 
-// GetByEmail provides a mock function with given fields: email
-func (_m *UsersRepoInterface) GetByEmail(email string) (*databases.User, error) {
-	ret := _m.Called(email)
+// GetByHandle provides a mock function with given fields: handle
+func (_m *UsersRepoInterface) GetByHandle(handle string) (*databases.User, error) {
+	ret := _m.Called(handle)
 
 	var r0 *databases.User
 	if rf, ok := ret.Get(0).(func(string) *databases.User); ok {
-		r0 = rf(email)
+		r0 = rf(handle)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*databases.User)
@@ -92,7 +108,7 @@ func (_m *UsersRepoInterface) GetByEmail(email string) (*databases.User, error)
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+		r1 = rf(handle)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -146,6 +162,59 @@ func (_m *UsersRepoInterface) GetByUsername(username string) ([]databases.User,
 	return r0, r1
 }
 
+// GetPage provides a mock function with given fields: filter, page
+func (_m *UsersRepoInterface) GetPage(filter *repo.UserFilter, page *repo.Page) ([]databases.User, *repo.PageInfo, error) {
+	ret := _m.Called(filter, page)
+
+	var r0 []databases.User
+	if rf, ok := ret.Get(0).(func(*repo.UserFilter, *repo.Page) []databases.User); ok {
+		r0 = rf(filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databases.User)
+		}
+	}
+
+	var r1 *repo.PageInfo
+	if rf, ok := ret.Get(1).(func(*repo.UserFilter, *repo.Page) *repo.PageInfo); ok {
+		r1 = rf(filter, page)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repo.PageInfo)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*repo.UserFilter, *repo.Page) error); ok {
+		r2 = rf(filter, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IsHandleTaken provides a mock function with given fields: handle, userId
+func (_m *UsersRepoInterface) IsHandleTaken(handle string, userId int) (bool, error) {
+	ret := _m.Called(handle, userId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, int) bool); ok {
+		r0 = rf(handle, userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(handle, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: id, updatedUser
 func (_m *UsersRepoInterface) Update(id int, updatedUser *databases.User) error {
 	ret := _m.Called(id, updatedUser)
@@ -159,3 +228,17 @@ func (_m *UsersRepoInterface) Update(id int, updatedUser *databases.User) error
 
 	return r0
 }
+
+// UpdatePrivacy provides a mock function with given fields: id, showEmail, privateProfile
+func (_m *UsersRepoInterface) UpdatePrivacy(id int, showEmail bool, privateProfile bool) error {
+	ret := _m.Called(id, showEmail, privateProfile)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, bool, bool) error); ok {
+		r0 = rf(id, showEmail, privateProfile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
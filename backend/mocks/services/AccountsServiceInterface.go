@@ -0,0 +1,105 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/gasser707/go-gql-server/graphql/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AccountsServiceInterface is an autogenerated mock type for the AccountsServiceInterface type
+type AccountsServiceInterface struct {
+	mock.Mock
+}
+
+// CancelAccountDeletion provides a mock function with given fields: ctx
+func (_m *AccountsServiceInterface) CancelAccountDeletion(ctx context.Context) (bool, error) {
+	ret := _m.Called(ctx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportMyData provides a mock function with given fields: ctx
+func (_m *AccountsServiceInterface) ExportMyData(ctx context.Context) (*model.DataExport, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *model.DataExport
+	if rf, ok := ret.Get(0).(func(context.Context) *model.DataExport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DataExport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedAccounts provides a mock function with given fields:
+func (_m *AccountsServiceInterface) PurgeDeletedAccounts() (int, error) {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestAccountDeletion provides a mock function with given fields: ctx, password
+func (_m *AccountsServiceInterface) RequestAccountDeletion(ctx context.Context, password string) (*time.Time, error) {
+	ret := _m.Called(ctx, password)
+
+	var r0 *time.Time
+	if rf, ok := ret.Get(0).(func(context.Context, string) *time.Time); ok {
+		r0 = rf(ctx, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Time)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,153 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/gasser707/go-gql-server/graphql/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AdminServiceInterface is an autogenerated mock type for the AdminServiceInterface type
+type AdminServiceInterface struct {
+	mock.Mock
+}
+
+// BanUser provides a mock function with given fields: ctx, userId, reason
+func (_m *AdminServiceInterface) BanUser(ctx context.Context, userId string, reason string) (*model.AdminUser, error) {
+	ret := _m.Called(ctx, userId, reason)
+
+	var r0 *model.AdminUser
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.AdminUser); ok {
+		r0 = rf(ctx, userId, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AdminUser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userId, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ForceLogout provides a mock function with given fields: ctx, userId
+func (_m *AdminServiceInterface) ForceLogout(ctx context.Context, userId string) (bool, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsers provides a mock function with given fields: ctx, filter, first, after, last, before
+func (_m *AdminServiceInterface) GetUsers(ctx context.Context, filter *model.AdminUserFilterInput, first *int, after *string, last *int, before *string) (*model.AdminUserConnection, error) {
+	ret := _m.Called(ctx, filter, first, after, last, before)
+
+	var r0 *model.AdminUserConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *model.AdminUserFilterInput, *int, *string, *int, *string) *model.AdminUserConnection); ok {
+		r0 = rf(ctx, filter, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AdminUserConnection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.AdminUserFilterInput, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, filter, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUserRole provides a mock function with given fields: ctx, userId, role
+func (_m *AdminServiceInterface) SetUserRole(ctx context.Context, userId string, role model.Role) (*model.AdminUser, error) {
+	ret := _m.Called(ctx, userId, role)
+
+	var r0 *model.AdminUser
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.Role) *model.AdminUser); ok {
+		r0 = rf(ctx, userId, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AdminUser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.Role) error); ok {
+		r1 = rf(ctx, userId, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SuspendUser provides a mock function with given fields: ctx, userId, reason, until
+func (_m *AdminServiceInterface) SuspendUser(ctx context.Context, userId string, reason string, until time.Time) (*model.AdminUser, error) {
+	ret := _m.Called(ctx, userId, reason, until)
+
+	var r0 *model.AdminUser
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) *model.AdminUser); ok {
+		r0 = rf(ctx, userId, reason, until)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AdminUser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, userId, reason, until)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnsuspendUser provides a mock function with given fields: ctx, userId
+func (_m *AdminServiceInterface) UnsuspendUser(ctx context.Context, userId string) (*model.AdminUser, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *model.AdminUser
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.AdminUser); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AdminUser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
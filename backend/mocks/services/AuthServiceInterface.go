@@ -16,17 +16,393 @@ type AuthServiceInterface struct {
 	mock.Mock
 }
 
-// Login provides a mock function with given fields: ctx, input
-func (_m *AuthServiceInterface) Login(ctx context.Context, input model.LoginInput) (bool, error) {
-	ret := _m.Called(ctx, input)
+// BeginPasskeyLogin provides a mock function with given fields: ctx, email
+func (_m *AuthServiceInterface) BeginPasskeyLogin(ctx context.Context, email string) (*model.PasskeyChallenge, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 *model.PasskeyChallenge
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.PasskeyChallenge); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PasskeyChallenge)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BeginPasskeyRegistration provides a mock function with given fields: ctx
+func (_m *AuthServiceInterface) BeginPasskeyRegistration(ctx context.Context) (*model.PasskeyChallenge, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *model.PasskeyChallenge
+	if rf, ok := ret.Get(0).(func(context.Context) *model.PasskeyChallenge); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PasskeyChallenge)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangePassword provides a mock function with given fields: ctx, currentPassword, newPassword
+func (_m *AuthServiceInterface) ChangePassword(ctx context.Context, currentPassword string, newPassword string) (bool, error) {
+	ret := _m.Called(ctx, currentPassword, newPassword)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, currentPassword, newPassword)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, currentPassword, newPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmEmailChange provides a mock function with given fields: ctx, token
+func (_m *AuthServiceInterface) ConfirmEmailChange(ctx context.Context, token string) (bool, error) {
+	ret := _m.Called(ctx, token)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(context.Context, model.LoginInput) bool); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmTwoFactor provides a mock function with given fields: ctx, code
+func (_m *AuthServiceInterface) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	ret := _m.Called(ctx, code)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConsumeMagicLink provides a mock function with given fields: ctx, token
+func (_m *AuthServiceInterface) ConsumeMagicLink(ctx context.Context, token string) (*model.LoginResult, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 *model.LoginResult
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.LoginResult); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LoginResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAccessToken provides a mock function with given fields: ctx, input
+func (_m *AuthServiceInterface) CreateAccessToken(ctx context.Context, input model.NewAccessTokenInput) (*model.NewAccessToken, error) {
+	ret := _m.Called(ctx, input)
+
+	var r0 *model.NewAccessToken
+	if rf, ok := ret.Get(0).(func(context.Context, model.NewAccessTokenInput) *model.NewAccessToken); ok {
 		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.NewAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.NewAccessTokenInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePasskey provides a mock function with given fields: ctx, id
+func (_m *AuthServiceInterface) DeletePasskey(ctx context.Context, id string) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DisableTwoFactor provides a mock function with given fields: ctx, code
+func (_m *AuthServiceInterface) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	ret := _m.Called(ctx, code)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, code)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnableTwoFactor provides a mock function with given fields: ctx
+func (_m *AuthServiceInterface) EnableTwoFactor(ctx context.Context) (*model.TwoFactorSetup, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *model.TwoFactorSetup
+	if rf, ok := ret.Get(0).(func(context.Context) *model.TwoFactorSetup); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TwoFactorSetup)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FinishPasskeyLogin provides a mock function with given fields: ctx, challengeId, credential
+func (_m *AuthServiceInterface) FinishPasskeyLogin(ctx context.Context, challengeId string, credential string) (*model.LoginResult, error) {
+	ret := _m.Called(ctx, challengeId, credential)
+
+	var r0 *model.LoginResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.LoginResult); ok {
+		r0 = rf(ctx, challengeId, credential)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LoginResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, challengeId, credential)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FinishPasskeyRegistration provides a mock function with given fields: ctx, challengeId, name, credential
+func (_m *AuthServiceInterface) FinishPasskeyRegistration(ctx context.Context, challengeId string, name string, credential string) (*model.Passkey, error) {
+	ret := _m.Called(ctx, challengeId, name, credential)
+
+	var r0 *model.Passkey
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *model.Passkey); ok {
+		r0 = rf(ctx, challengeId, name, credential)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Passkey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, challengeId, name, credential)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccessTokens provides a mock function with given fields: ctx
+func (_m *AuthServiceInterface) GetAccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*model.AccessToken
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.AccessToken); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.AccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoginHistory provides a mock function with given fields: ctx, limit
+func (_m *AuthServiceInterface) GetLoginHistory(ctx context.Context, limit *int) ([]*model.LoginEvent, error) {
+	ret := _m.Called(ctx, limit)
+
+	var r0 []*model.LoginEvent
+	if rf, ok := ret.Get(0).(func(context.Context, *int) []*model.LoginEvent); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.LoginEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPasskeys provides a mock function with given fields: ctx
+func (_m *AuthServiceInterface) GetPasskeys(ctx context.Context) ([]*model.Passkey, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*model.Passkey
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Passkey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Passkey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessions provides a mock function with given fields: ctx
+func (_m *AuthServiceInterface) GetSessions(ctx context.Context) ([]*model.Session, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*model.Session
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Session); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasCredentials provides a mock function with given fields: ctx
+func (_m *AuthServiceInterface) HasCredentials(ctx context.Context) bool {
+	ret := _m.Called(ctx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// Login provides a mock function with given fields: ctx, input
+func (_m *AuthServiceInterface) Login(ctx context.Context, input model.LoginInput) (*model.LoginResult, error) {
+	ret := _m.Called(ctx, input)
+
+	var r0 *model.LoginResult
+	if rf, ok := ret.Get(0).(func(context.Context, model.LoginInput) *model.LoginResult); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LoginResult)
+		}
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, model.LoginInput) error); ok {
 		r1 = rf(ctx, input)
@@ -121,8 +497,92 @@ func (_m *AuthServiceInterface) RefreshCredentials(ctx context.Context) (bool, e
 	return r0, r1
 }
 
-// RequestPasswordReset provides a mock function with given fields: ctx, email
-func (_m *AuthServiceInterface) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+// ReportUnrecognizedLogin provides a mock function with given fields: ctx, token
+func (_m *AuthServiceInterface) ReportUnrecognizedLogin(ctx context.Context, token string) (bool, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestEmailChange provides a mock function with given fields: ctx, newEmail, password
+func (_m *AuthServiceInterface) RequestEmailChange(ctx context.Context, newEmail string, password string) (bool, error) {
+	ret := _m.Called(ctx, newEmail, password)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, newEmail, password)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, newEmail, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestMagicLink provides a mock function with given fields: ctx, email
+func (_m *AuthServiceInterface) RequestMagicLink(ctx context.Context, email string) (bool, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestPasswordReset provides a mock function with given fields: ctx, email, captchaToken
+func (_m *AuthServiceInterface) RequestPasswordReset(ctx context.Context, email string, captchaToken *string) (bool, error) {
+	ret := _m.Called(ctx, email, captchaToken)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, *string) bool); ok {
+		r0 = rf(ctx, email, captchaToken)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *string) error); ok {
+		r1 = rf(ctx, email, captchaToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResendVerificationEmail provides a mock function with given fields: ctx, email
+func (_m *AuthServiceInterface) ResendVerificationEmail(ctx context.Context, email string) (bool, error) {
 	ret := _m.Called(ctx, email)
 
 	var r0 bool
@@ -142,27 +602,97 @@ func (_m *AuthServiceInterface) RequestPasswordReset(ctx context.Context, email
 	return r0, r1
 }
 
-// ValidateCredentials provides a mock function with given fields: c
-func (_m *AuthServiceInterface) ValidateCredentials(c context.Context) (services.IntUserID, model.Role, error) {
-	ret := _m.Called(c)
+// RevokeAccessToken provides a mock function with given fields: ctx, id
+func (_m *AuthServiceInterface) RevokeAccessToken(ctx context.Context, id string) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: ctx, sessionId
+func (_m *AuthServiceInterface) RevokeSession(ctx context.Context, sessionId string) (bool, error) {
+	ret := _m.Called(ctx, sessionId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, sessionId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, sessionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnlockAccount provides a mock function with given fields: ctx, unlockToken
+func (_m *AuthServiceInterface) UnlockAccount(ctx context.Context, unlockToken string) (bool, error) {
+	ret := _m.Called(ctx, unlockToken)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, unlockToken)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, unlockToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateCredentials provides a mock function with given fields: c, scopes
+func (_m *AuthServiceInterface) ValidateCredentials(c context.Context, scopes ...string) (services.IntUserID, model.Role, error) {
+	_va := make([]interface{}, len(scopes))
+	for _i := range scopes {
+		_va[_i] = scopes[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, c)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 services.IntUserID
-	if rf, ok := ret.Get(0).(func(context.Context) services.IntUserID); ok {
-		r0 = rf(c)
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) services.IntUserID); ok {
+		r0 = rf(c, scopes...)
 	} else {
 		r0 = ret.Get(0).(services.IntUserID)
 	}
 
 	var r1 model.Role
-	if rf, ok := ret.Get(1).(func(context.Context) model.Role); ok {
-		r1 = rf(c)
+	if rf, ok := ret.Get(1).(func(context.Context, ...string) model.Role); ok {
+		r1 = rf(c, scopes...)
 	} else {
 		r1 = ret.Get(1).(model.Role)
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context) error); ok {
-		r2 = rf(c)
+	if rf, ok := ret.Get(2).(func(context.Context, ...string) error); ok {
+		r2 = rf(c, scopes...)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -190,3 +720,24 @@ func (_m *AuthServiceInterface) ValidateUser(ctx context.Context, validationToke
 
 	return r0, r1
 }
+
+// VerifyTwoFactor provides a mock function with given fields: ctx, challengeToken, code
+func (_m *AuthServiceInterface) VerifyTwoFactor(ctx context.Context, challengeToken string, code string) (bool, error) {
+	ret := _m.Called(ctx, challengeToken, code)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, challengeToken, code)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, challengeToken, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
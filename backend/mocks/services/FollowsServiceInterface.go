@@ -0,0 +1,109 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	custom "github.com/gasser707/go-gql-server/graphql/custom"
+	mock "github.com/stretchr/testify/mock"
+
+	services "github.com/gasser707/go-gql-server/services"
+)
+
+// FollowsServiceInterface is an autogenerated mock type for the FollowsServiceInterface type
+type FollowsServiceInterface struct {
+	mock.Mock
+}
+
+// Follow provides a mock function with given fields: ctx, userId
+func (_m *FollowsServiceInterface) Follow(ctx context.Context, userId string) (*custom.User, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *custom.User); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*custom.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFollowers provides a mock function with given fields: ctx, user, first, after, last, before
+func (_m *FollowsServiceInterface) GetFollowers(ctx context.Context, user *custom.User, first *int, after *string, last *int, before *string) (*services.FollowsPage, error) {
+	ret := _m.Called(ctx, user, first, after, last, before)
+
+	var r0 *services.FollowsPage
+	if rf, ok := ret.Get(0).(func(context.Context, *custom.User, *int, *string, *int, *string) *services.FollowsPage); ok {
+		r0 = rf(ctx, user, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*services.FollowsPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *custom.User, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, user, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFollowing provides a mock function with given fields: ctx, user, first, after, last, before
+func (_m *FollowsServiceInterface) GetFollowing(ctx context.Context, user *custom.User, first *int, after *string, last *int, before *string) (*services.FollowsPage, error) {
+	ret := _m.Called(ctx, user, first, after, last, before)
+
+	var r0 *services.FollowsPage
+	if rf, ok := ret.Get(0).(func(context.Context, *custom.User, *int, *string, *int, *string) *services.FollowsPage); ok {
+		r0 = rf(ctx, user, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*services.FollowsPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *custom.User, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, user, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Unfollow provides a mock function with given fields: ctx, userId
+func (_m *FollowsServiceInterface) Unfollow(ctx context.Context, userId string) (*custom.User, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *custom.User); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*custom.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -60,6 +60,29 @@ func (_m *ImagesServiceInterface) DeleteImages(ctx context.Context, input []stri
 	return r0, r1
 }
 
+// GetFeed provides a mock function with given fields: ctx, first, after, last, before
+func (_m *ImagesServiceInterface) GetFeed(ctx context.Context, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	ret := _m.Called(ctx, first, after, last, before)
+
+	var r0 *model.ImageConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *string, *int, *string) *model.ImageConnection); ok {
+		r0 = rf(ctx, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ImageConnection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImageById provides a mock function with given fields: ctx, ID
 func (_m *ImagesServiceInterface) GetImageById(ctx context.Context, ID string) (*custom.Image, error) {
 	ret := _m.Called(ctx, ID)
@@ -106,6 +129,52 @@ func (_m *ImagesServiceInterface) GetImages(ctx context.Context, input *model.Im
 	return r0, r1
 }
 
+// GetImagesPage provides a mock function with given fields: ctx, input, first, after, last, before
+func (_m *ImagesServiceInterface) GetImagesPage(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	ret := _m.Called(ctx, input, first, after, last, before)
+
+	var r0 *model.ImageConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ImageFilterInput, *int, *string, *int, *string) *model.ImageConnection); ok {
+		r0 = rf(ctx, input, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ImageConnection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ImageFilterInput, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, input, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPublicImagesPage provides a mock function with given fields: ctx, input, first, after, last, before
+func (_m *ImagesServiceInterface) GetPublicImagesPage(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int, before *string) (*model.ImageConnection, error) {
+	ret := _m.Called(ctx, input, first, after, last, before)
+
+	var r0 *model.ImageConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ImageFilterInput, *int, *string, *int, *string) *model.ImageConnection); ok {
+		r0 = rf(ctx, input, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ImageConnection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ImageFilterInput, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, input, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateImage provides a mock function with given fields: ctx, input
 func (_m *ImagesServiceInterface) UpdateImage(ctx context.Context, input *model.UpdateImageInput) (*custom.Image, error) {
 	ret := _m.Called(ctx, input)
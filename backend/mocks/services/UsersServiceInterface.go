@@ -16,6 +16,75 @@ type UsersServiceInterface struct {
 	mock.Mock
 }
 
+// ChangeHandle provides a mock function with given fields: ctx, handle
+func (_m *UsersServiceInterface) ChangeHandle(ctx context.Context, handle string) (*custom.User, error) {
+	ret := _m.Called(ctx, handle)
+
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *custom.User); ok {
+		r0 = rf(ctx, handle)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*custom.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, handle)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMe provides a mock function with given fields: ctx
+func (_m *UsersServiceInterface) GetMe(ctx context.Context) (*custom.User, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context) *custom.User); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*custom.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByHandle provides a mock function with given fields: ctx, handle
+func (_m *UsersServiceInterface) GetUserByHandle(ctx context.Context, handle string) (*custom.User, error) {
+	ret := _m.Called(ctx, handle)
+
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *custom.User); ok {
+		r0 = rf(ctx, handle)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*custom.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, handle)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserById provides a mock function with given fields: ID
 func (_m *UsersServiceInterface) GetUserById(ID string) (*custom.User, error) {
 	ret := _m.Called(ID)
@@ -39,21 +108,67 @@ func (_m *UsersServiceInterface) GetUserById(ID string) (*custom.User, error) {
 	return r0, r1
 }
 
-// GetUsers provides a mock function with given fields: ctx, input
-func (_m *UsersServiceInterface) GetUsers(ctx context.Context, input *model.UserFilterInput) ([]*custom.User, error) {
+// GetUserProfile provides a mock function with given fields: ctx, username
+func (_m *UsersServiceInterface) GetUserProfile(ctx context.Context, username string) (*custom.User, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *custom.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*custom.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsers provides a mock function with given fields: ctx, input, first, after, last, before
+func (_m *UsersServiceInterface) GetUsers(ctx context.Context, input *model.UserFilterInput, first *int, after *string, last *int, before *string) (*model.UserConnection, error) {
+	ret := _m.Called(ctx, input, first, after, last, before)
+
+	var r0 *model.UserConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *model.UserFilterInput, *int, *string, *int, *string) *model.UserConnection); ok {
+		r0 = rf(ctx, input, first, after, last, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserConnection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.UserFilterInput, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, input, first, after, last, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterUser provides a mock function with given fields: ctx, input
+func (_m *UsersServiceInterface) RegisterUser(ctx context.Context, input model.NewUserInput) (*custom.User, error) {
 	ret := _m.Called(ctx, input)
 
-	var r0 []*custom.User
-	if rf, ok := ret.Get(0).(func(context.Context, *model.UserFilterInput) []*custom.User); ok {
+	var r0 *custom.User
+	if rf, ok := ret.Get(0).(func(context.Context, model.NewUserInput) *custom.User); ok {
 		r0 = rf(ctx, input)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*custom.User)
+			r0 = ret.Get(0).(*custom.User)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *model.UserFilterInput) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, model.NewUserInput) error); ok {
 		r1 = rf(ctx, input)
 	} else {
 		r1 = ret.Error(1)
@@ -62,13 +177,13 @@ func (_m *UsersServiceInterface) GetUsers(ctx context.Context, input *model.User
 	return r0, r1
 }
 
-// RegisterUser provides a mock function with given fields: input
-func (_m *UsersServiceInterface) RegisterUser(input model.NewUserInput) (*custom.User, error) {
-	ret := _m.Called(input)
+// UpdatePrivacySettings provides a mock function with given fields: ctx, input
+func (_m *UsersServiceInterface) UpdatePrivacySettings(ctx context.Context, input model.PrivacySettingsInput) (*custom.User, error) {
+	ret := _m.Called(ctx, input)
 
 	var r0 *custom.User
-	if rf, ok := ret.Get(0).(func(model.NewUserInput) *custom.User); ok {
-		r0 = rf(input)
+	if rf, ok := ret.Get(0).(func(context.Context, model.PrivacySettingsInput) *custom.User); ok {
+		r0 = rf(ctx, input)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*custom.User)
@@ -76,8 +191,8 @@ func (_m *UsersServiceInterface) RegisterUser(input model.NewUserInput) (*custom
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(model.NewUserInput) error); ok {
-		r1 = rf(input)
+	if rf, ok := ret.Get(1).(func(context.Context, model.PrivacySettingsInput) error); ok {
+		r1 = rf(ctx, input)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -2,13 +2,47 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
 
 // EmailAdaptorInterface is an autogenerated mock type for the EmailAdaptorInterface type
 type EmailAdaptorInterface struct {
 	mock.Mock
 }
 
+// SendAccountLockedEmail provides a mock function with given fields: sender, to, name, unlockLink
+func (_m *EmailAdaptorInterface) SendAccountLockedEmail(sender string, to []string, name string, unlockLink string) {
+	_m.Called(sender, to, name, unlockLink)
+}
+
+// SendEmailChangeEmail provides a mock function with given fields: sender, to, name, confirmLink
+func (_m *EmailAdaptorInterface) SendEmailChangeEmail(sender string, to []string, name string, confirmLink string) {
+	_m.Called(sender, to, name, confirmLink)
+}
+
+// SendEmailChangedEmail provides a mock function with given fields: sender, to, name, resetLink
+func (_m *EmailAdaptorInterface) SendEmailChangedEmail(sender string, to []string, name string, resetLink string) {
+	_m.Called(sender, to, name, resetLink)
+}
+
+// SendMagicLinkEmail provides a mock function with given fields: sender, to, name, loginLink
+func (_m *EmailAdaptorInterface) SendMagicLinkEmail(sender string, to []string, name string, loginLink string) {
+	_m.Called(sender, to, name, loginLink)
+}
+
+// SendNewDeviceEmail provides a mock function with given fields: sender, to, name, device, ip, at, notMeLink
+func (_m *EmailAdaptorInterface) SendNewDeviceEmail(sender string, to []string, name string, device string, ip string, at time.Time, notMeLink string) {
+	_m.Called(sender, to, name, device, ip, at, notMeLink)
+}
+
+// SendPasswordChangedEmail provides a mock function with given fields: sender, to, name, resetLink
+func (_m *EmailAdaptorInterface) SendPasswordChangedEmail(sender string, to []string, name string, resetLink string) {
+	_m.Called(sender, to, name, resetLink)
+}
+
 // SendReceiptEmail provides a mock function with given fields: sender, to, sellerName, buyerName, imageID, imageTitle, paymentMethod
 func (_m *EmailAdaptorInterface) SendReceiptEmail(sender string, to []string, sellerName string, buyerName string, imageID string, imageTitle string, paymentMethod string) {
 	_m.Called(sender, to, sellerName, buyerName, imageID, imageTitle, paymentMethod)
@@ -19,7 +53,7 @@ func (_m *EmailAdaptorInterface) SendResetPassEmail(sender string, to []string,
 	_m.Called(sender, to, name, resetLink)
 }
 
-// SendWelcomeEmail provides a mock function with given fields: sender, to, name
-func (_m *EmailAdaptorInterface) SendWelcomeEmail(sender string, to []string, name string) {
-	_m.Called(sender, to, name)
+// SendWelcomeEmail provides a mock function with given fields: sender, to, name, verificationLink
+func (_m *EmailAdaptorInterface) SendWelcomeEmail(sender string, to []string, name string, verificationLink string) {
+	_m.Called(sender, to, name, verificationLink)
 }
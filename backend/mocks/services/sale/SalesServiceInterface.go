@@ -7,6 +7,8 @@ import (
 
 	custom "github.com/gasser707/go-gql-server/graphql/custom"
 	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/gasser707/go-gql-server/graphql/model"
 )
 
 // SalesServiceInterface is an autogenerated mock type for the SalesServiceInterface type
@@ -37,22 +39,22 @@ func (_m *SalesServiceInterface) BuyImage(ctx context.Context, id string) (*cust
 	return r0, r1
 }
 
-// GetSales provides a mock function with given fields: ctx
-func (_m *SalesServiceInterface) GetSales(ctx context.Context) ([]*custom.Sale, error) {
-	ret := _m.Called(ctx)
+// GetSales provides a mock function with given fields: ctx, first, after, last, before
+func (_m *SalesServiceInterface) GetSales(ctx context.Context, first *int, after *string, last *int, before *string) (*model.SaleConnection, error) {
+	ret := _m.Called(ctx, first, after, last, before)
 
-	var r0 []*custom.Sale
-	if rf, ok := ret.Get(0).(func(context.Context) []*custom.Sale); ok {
-		r0 = rf(ctx)
+	var r0 *model.SaleConnection
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *string, *int, *string) *model.SaleConnection); ok {
+		r0 = rf(ctx, first, after, last, before)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*custom.Sale)
+			r0 = ret.Get(0).(*model.SaleConnection)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *int, *string, *int, *string) error); ok {
+		r1 = rf(ctx, first, after, last, before)
 	} else {
 		r1 = ret.Error(1)
 	}
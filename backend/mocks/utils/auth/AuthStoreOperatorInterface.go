@@ -12,6 +12,20 @@ type AuthStoreOperatorInterface struct {
 	mock.Mock
 }
 
+// ConsumeTokenId provides a mock function with given fields: jti, expiresAt
+func (_m *AuthStoreOperatorInterface) ConsumeTokenId(jti string, expiresAt int64) error {
+	ret := _m.Called(jti, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
+		r0 = rf(jti, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateAuthTokens provides a mock function with given fields: _a0, _a1
 func (_m *AuthStoreOperatorInterface) CreateAuthTokens(_a0 string, _a1 *auth.TokenDetails) error {
 	ret := _m.Called(_a0, _a1)
@@ -54,6 +68,20 @@ func (_m *AuthStoreOperatorInterface) DeleteRefresh(_a0 string) error {
 	return r0
 }
 
+// DeleteSession provides a mock function with given fields: userId, sessionId
+func (_m *AuthStoreOperatorInterface) DeleteSession(userId string, sessionId string) error {
+	ret := _m.Called(userId, sessionId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userId, sessionId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteTokens provides a mock function with given fields: _a0
 func (_m *AuthStoreOperatorInterface) DeleteTokens(_a0 *auth.AccessDetails) error {
 	ret := _m.Called(_a0)
@@ -109,3 +137,138 @@ func (_m *AuthStoreOperatorInterface) FetchRefresh(refreshUuid string) (string,
 
 	return r0, r1
 }
+
+// FetchSessions provides a mock function with given fields: userId
+func (_m *AuthStoreOperatorInterface) FetchSessions(userId string) ([]*auth.Session, error) {
+	ret := _m.Called(userId)
+
+	var r0 []*auth.Session
+	if rf, ok := ret.Get(0).(func(string) []*auth.Session); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*auth.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsRotatedRefresh provides a mock function with given fields: userId, familyId, refreshUuid
+func (_m *AuthStoreOperatorInterface) IsRotatedRefresh(userId string, familyId string, refreshUuid string) (bool, error) {
+	ret := _m.Called(userId, familyId, refreshUuid)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(userId, familyId, refreshUuid)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(userId, familyId, refreshUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeFamily provides a mock function with given fields: userId, familyId
+func (_m *AuthStoreOperatorInterface) RevokeFamily(userId string, familyId string) error {
+	ret := _m.Called(userId, familyId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userId, familyId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateRefresh provides a mock function with given fields: userId, familyId, refreshUuid, expiresAt
+func (_m *AuthStoreOperatorInterface) RotateRefresh(userId string, familyId string, refreshUuid string, expiresAt int64) error {
+	ret := _m.Called(userId, familyId, refreshUuid, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, int64) error); ok {
+		r0 = rf(userId, familyId, refreshUuid, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveChallenge provides a mock function with given fields: id, data, expiresAt
+func (_m *AuthStoreOperatorInterface) SaveChallenge(id string, data string, expiresAt int64) error {
+	ret := _m.Called(id, data, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) error); ok {
+		r0 = rf(id, data, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSession provides a mock function with given fields: session, td
+func (_m *AuthStoreOperatorInterface) SaveSession(session *auth.Session, td *auth.TokenDetails) error {
+	ret := _m.Called(session, td)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*auth.Session, *auth.TokenDetails) error); ok {
+		r0 = rf(session, td)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TakeChallenge provides a mock function with given fields: id
+func (_m *AuthStoreOperatorInterface) TakeChallenge(id string) (string, error) {
+	ret := _m.Called(id)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TouchSession provides a mock function with given fields: userId, sessionId
+func (_m *AuthStoreOperatorInterface) TouchSession(userId string, sessionId string) error {
+	ret := _m.Called(userId, sessionId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userId, sessionId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,28 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HumanVerifierInterface is an autogenerated mock type for the HumanVerifierInterface type
+type HumanVerifierInterface struct {
+	mock.Mock
+}
+
+// Verify provides a mock function with given fields: ctx, token, remoteIp
+func (_m *HumanVerifierInterface) Verify(ctx context.Context, token string, remoteIp string) error {
+	ret := _m.Called(ctx, token, remoteIp)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, token, remoteIp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
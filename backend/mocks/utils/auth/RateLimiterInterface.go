@@ -0,0 +1,76 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	auth "github.com/gasser707/go-gql-server/utils/auth"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RateLimiterInterface is an autogenerated mock type for the RateLimiterInterface type
+type RateLimiterInterface struct {
+	mock.Mock
+}
+
+// Allow provides a mock function with given fields: policy, subject
+func (_m *RateLimiterInterface) Allow(policy auth.Policy, subject string) error {
+	ret := _m.Called(policy, subject)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(auth.Policy, string) error); ok {
+		r0 = rf(policy, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Clear provides a mock function with given fields: policy, subject
+func (_m *RateLimiterInterface) Clear(policy auth.Policy, subject string) error {
+	ret := _m.Called(policy, subject)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(auth.Policy, string) error); ok {
+		r0 = rf(policy, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Refund provides a mock function with given fields: policy, subject
+func (_m *RateLimiterInterface) Refund(policy auth.Policy, subject string) error {
+	ret := _m.Called(policy, subject)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(auth.Policy, string) error); ok {
+		r0 = rf(policy, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Take provides a mock function with given fields: policy, subject
+func (_m *RateLimiterInterface) Take(policy auth.Policy, subject string) (bool, error) {
+	ret := _m.Called(policy, subject)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(auth.Policy, string) bool); ok {
+		r0 = rf(policy, subject)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(auth.Policy, string) error); ok {
+		r1 = rf(policy, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
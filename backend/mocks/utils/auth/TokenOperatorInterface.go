@@ -17,6 +17,27 @@ type TokenOperatorInterface struct {
 	mock.Mock
 }
 
+// CreateEmailChangeToken provides a mock function with given fields: userId, newEmail
+func (_m *TokenOperatorInterface) CreateEmailChangeToken(userId string, newEmail string) (string, error) {
+	ret := _m.Called(userId, newEmail)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(userId, newEmail)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userId, newEmail)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateStatelessToken provides a mock function with given fields: userId, kind
 func (_m *TokenOperatorInterface) CreateStatelessToken(userId string, kind auth.StatelessToken) (string, error) {
 	ret := _m.Called(userId, kind)
@@ -38,13 +59,13 @@ func (_m *TokenOperatorInterface) CreateStatelessToken(userId string, kind auth.
 	return r0, r1
 }
 
-// CreateTokens provides a mock function with given fields: userId, userRole
-func (_m *TokenOperatorInterface) CreateTokens(userId string, userRole model.Role) (*auth.TokenDetails, error) {
-	ret := _m.Called(userId, userRole)
+// CreateTokens provides a mock function with given fields: userId, userRole, sessionId
+func (_m *TokenOperatorInterface) CreateTokens(userId string, userRole model.Role, sessionId string) (*auth.TokenDetails, error) {
+	ret := _m.Called(userId, userRole, sessionId)
 
 	var r0 *auth.TokenDetails
-	if rf, ok := ret.Get(0).(func(string, model.Role) *auth.TokenDetails); ok {
-		r0 = rf(userId, userRole)
+	if rf, ok := ret.Get(0).(func(string, model.Role, string) *auth.TokenDetails); ok {
+		r0 = rf(userId, userRole, sessionId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*auth.TokenDetails)
@@ -52,8 +73,8 @@ func (_m *TokenOperatorInterface) CreateTokens(userId string, userRole model.Rol
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, model.Role) error); ok {
-		r1 = rf(userId, userRole)
+	if rf, ok := ret.Get(1).(func(string, model.Role, string) error); ok {
+		r1 = rf(userId, userRole, sessionId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -108,14 +129,16 @@ func (_m *TokenOperatorInterface) ExtractRefreshMetadata(ctx context.Context) (*
 }
 
 // ExtractStatelessTokenMetadata provides a mock function with given fields: ctx, tokenString, kind
-func (_m *TokenOperatorInterface) ExtractStatelessTokenMetadata(ctx context.Context, tokenString string, kind auth.StatelessToken) (string, error) {
+func (_m *TokenOperatorInterface) ExtractStatelessTokenMetadata(ctx context.Context, tokenString string, kind auth.StatelessToken) (*auth.StatelessDetails, error) {
 	ret := _m.Called(ctx, tokenString, kind)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(context.Context, string, auth.StatelessToken) string); ok {
+	var r0 *auth.StatelessDetails
+	if rf, ok := ret.Get(0).(func(context.Context, string, auth.StatelessToken) *auth.StatelessDetails); ok {
 		r0 = rf(ctx, tokenString, kind)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.StatelessDetails)
+		}
 	}
 
 	var r1 error
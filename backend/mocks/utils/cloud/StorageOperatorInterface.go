@@ -13,6 +13,27 @@ type StorageOperatorInterface struct {
 	mock.Mock
 }
 
+// ChangeImagePath provides a mock function with given fields: oldPath, newPath
+func (_m *StorageOperatorInterface) ChangeImagePath(oldPath string, newPath string) (string, error) {
+	ret := _m.Called(oldPath, newPath)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(oldPath, newPath)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(oldPath, newPath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteImage provides a mock function with given fields: path
 func (_m *StorageOperatorInterface) DeleteImage(path string) error {
 	ret := _m.Called(path)
@@ -27,20 +48,20 @@ func (_m *StorageOperatorInterface) DeleteImage(path string) error {
 	return r0
 }
 
-// UploadImage provides a mock function with given fields: img, imgName, productId
-func (_m *StorageOperatorInterface) UploadImage(img io.Reader, imgName string, productId string) (string, error) {
-	ret := _m.Called(img, imgName, productId)
+// UploadImage provides a mock function with given fields: img, imgName, userId
+func (_m *StorageOperatorInterface) UploadImage(img io.Reader, imgName string, userId string) (string, error) {
+	ret := _m.Called(img, imgName, userId)
 
 	var r0 string
 	if rf, ok := ret.Get(0).(func(io.Reader, string, string) string); ok {
-		r0 = rf(img, imgName, productId)
+		r0 = rf(img, imgName, userId)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(io.Reader, string, string) error); ok {
-		r1 = rf(img, imgName, productId)
+		r1 = rf(img, imgName, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccessTokensRepoInterface interface {
+	Create(token *dbModels.AccessToken) (int64, error)
+	GetByHash(hash string) (*dbModels.AccessToken, error)
+	GetAllByUser(userId int) ([]dbModels.AccessToken, error)
+	Delete(id int, userId int) error
+	DeleteAllByUser(userId int) error
+	UpdateLastUsed(id int, lastUsed time.Time) error
+}
+
+var _ AccessTokensRepoInterface = &accessTokensRepo{}
+var _ AccessTokensRepoInterface = &mysqlAccessTokensRepo{}
+
+type accessTokensRepo struct {
+	repo AccessTokensRepoInterface
+}
+
+type mysqlAccessTokensRepo struct {
+	db *sqlx.DB
+}
+
+func NewAccessTokensRepo(db *sqlx.DB) *accessTokensRepo {
+	mysqlRepo := &mysqlAccessTokensRepo{
+		db,
+	}
+	return &accessTokensRepo{
+		repo: mysqlRepo,
+	}
+}
+
+func (ar *accessTokensRepo) Create(token *dbModels.AccessToken) (int64, error) {
+	return ar.repo.Create(token)
+}
+
+func (ar *accessTokensRepo) GetByHash(hash string) (*dbModels.AccessToken, error) {
+	return ar.repo.GetByHash(hash)
+}
+
+func (ar *accessTokensRepo) GetAllByUser(userId int) ([]dbModels.AccessToken, error) {
+	return ar.repo.GetAllByUser(userId)
+}
+
+func (ar *accessTokensRepo) Delete(id int, userId int) error {
+	return ar.repo.Delete(id, userId)
+}
+
+func (ar *accessTokensRepo) DeleteAllByUser(userId int) error {
+	return ar.repo.DeleteAllByUser(userId)
+}
+
+func (ar *accessTokensRepo) UpdateLastUsed(id int, lastUsed time.Time) error {
+	return ar.repo.UpdateLastUsed(id, lastUsed)
+}
+
+func (r *mysqlAccessTokensRepo) Create(token *dbModels.AccessToken) (int64, error) {
+	result, err := r.db.NamedExec(`INSERT INTO access_tokens(user_id, name, token_hash, scopes, created_at, expires_at)
+		VALUES (:user_id, :name, :token_hash, :scopes, :created_at, :expires_at)`, token)
+	if err != nil {
+		return -1, customErr.DB(err)
+	}
+	tokenId, _ := result.LastInsertId()
+	return tokenId, nil
+}
+
+func (r *mysqlAccessTokensRepo) GetByHash(hash string) (*dbModels.AccessToken, error) {
+	token := dbModels.AccessToken{}
+	err := r.db.Get(&token, "SELECT * FROM access_tokens WHERE token_hash=?", hash)
+	if err != nil {
+		return nil, customErr.NoAuth("invalid access token")
+	}
+	return &token, nil
+}
+
+func (r *mysqlAccessTokensRepo) GetAllByUser(userId int) ([]dbModels.AccessToken, error) {
+	tokens := []dbModels.AccessToken{}
+	err := r.db.Select(&tokens, "SELECT * FROM access_tokens WHERE user_id=? ORDER BY created_at DESC, id DESC", userId)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return tokens, nil
+}
+
+func (r *mysqlAccessTokensRepo) Delete(id int, userId int) error {
+	result, err := r.db.Exec(`DELETE FROM access_tokens WHERE id=? AND user_id=?`, id, userId)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	if affected == 0 {
+		return customErr.NotFound("access token not found")
+	}
+	return nil
+}
+
+func (r *mysqlAccessTokensRepo) DeleteAllByUser(userId int) error {
+	_, err := r.db.Exec(`DELETE FROM access_tokens WHERE user_id=?`, userId)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+func (r *mysqlAccessTokensRepo) UpdateLastUsed(id int, lastUsed time.Time) error {
+	_, err := r.db.Exec(`UPDATE access_tokens SET last_used_at=? WHERE id=?`, lastUsed, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccountsRepoInterface interface {
+	GetUserById(id int) (*dbModels.User, error)
+	SetDeletionRequestedAt(id int, at *time.Time) error
+	GetUsersDueForPurge(requestedBefore time.Time) ([]dbModels.User, error)
+	GetUserImages(userId int) ([]dbModels.Image, error)
+	GetUserLabels(userId int) ([]dbModels.Label, error)
+	GetUserSales(userId int) ([]dbModels.Sale, error)
+	PurgeImage(imgId int) error
+	AnonymizeUser(id int) error
+}
+
+var _ AccountsRepoInterface = &accountsRepo{}
+var _ AccountsRepoInterface = &mysqlAccountsRepo{}
+
+type accountsRepo struct {
+	repo AccountsRepoInterface
+}
+
+type mysqlAccountsRepo struct {
+	db *sqlx.DB
+}
+
+func NewAccountsRepo(db *sqlx.DB) *accountsRepo {
+	mysqlRepo := &mysqlAccountsRepo{
+		db,
+	}
+	return &accountsRepo{
+		repo: mysqlRepo,
+	}
+}
+
+func (ar *accountsRepo) GetUserById(id int) (*dbModels.User, error) {
+	return ar.repo.GetUserById(id)
+}
+
+func (ar *accountsRepo) SetDeletionRequestedAt(id int, at *time.Time) error {
+	return ar.repo.SetDeletionRequestedAt(id, at)
+}
+
+func (ar *accountsRepo) GetUsersDueForPurge(requestedBefore time.Time) ([]dbModels.User, error) {
+	return ar.repo.GetUsersDueForPurge(requestedBefore)
+}
+
+func (ar *accountsRepo) GetUserImages(userId int) ([]dbModels.Image, error) {
+	return ar.repo.GetUserImages(userId)
+}
+
+func (ar *accountsRepo) GetUserLabels(userId int) ([]dbModels.Label, error) {
+	return ar.repo.GetUserLabels(userId)
+}
+
+func (ar *accountsRepo) GetUserSales(userId int) ([]dbModels.Sale, error) {
+	return ar.repo.GetUserSales(userId)
+}
+
+func (ar *accountsRepo) PurgeImage(imgId int) error {
+	return ar.repo.PurgeImage(imgId)
+}
+
+func (ar *accountsRepo) AnonymizeUser(id int) error {
+	return ar.repo.AnonymizeUser(id)
+}
+
+func (r *mysqlAccountsRepo) GetUserById(id int) (*dbModels.User, error) {
+	user := dbModels.User{}
+	err := r.db.Get(&user, "SELECT * FROM users WHERE id=? AND deleted_at IS NULL", id)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return &user, nil
+}
+
+func (r *mysqlAccountsRepo) SetDeletionRequestedAt(id int, at *time.Time) error {
+	_, err := r.db.Exec(`UPDATE users SET deletion_requested_at=? WHERE id=? AND deleted_at IS NULL`, at, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+func (r *mysqlAccountsRepo) GetUsersDueForPurge(requestedBefore time.Time) ([]dbModels.User, error) {
+	users := []dbModels.User{}
+	err := r.db.Select(&users, `SELECT * FROM users WHERE deletion_requested_at<=? AND deleted_at IS NULL`,
+		requestedBefore)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return users, nil
+}
+
+func (r *mysqlAccountsRepo) GetUserImages(userId int) ([]dbModels.Image, error) {
+	images := []dbModels.Image{}
+	err := r.db.Select(&images, "SELECT * FROM images WHERE user_id=? ORDER BY id", userId)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return images, nil
+}
+
+func (r *mysqlAccountsRepo) GetUserLabels(userId int) ([]dbModels.Label, error) {
+	labels := []dbModels.Label{}
+	err := r.db.Select(&labels, `SELECT labels.* FROM labels JOIN images ON labels.image_id=images.id
+		WHERE images.user_id=? ORDER BY labels.id`, userId)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return labels, nil
+}
+
+func (r *mysqlAccountsRepo) GetUserSales(userId int) ([]dbModels.Sale, error) {
+	sales := []dbModels.Sale{}
+	err := r.db.Select(&sales, "SELECT * FROM sales WHERE buyer_id=? OR seller_id=? ORDER BY id", userId, userId)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return sales, nil
+}
+
+//PurgeImage drops the labels of the image and the image itself, an image that was sold stays for the
+//buyers' sales but loses everything that described it
+func (r *mysqlAccountsRepo) PurgeImage(imgId int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	_, err = tx.Exec("DELETE FROM labels WHERE image_id=?", imgId)
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	sold := 0
+	err = tx.Get(&sold, "SELECT COUNT(*) FROM sales WHERE image_id=?", imgId)
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	if sold == 0 {
+		_, err = tx.Exec("DELETE FROM images WHERE id=?", imgId)
+	} else {
+		_, err = tx.Exec(`UPDATE images SET url='', title='', description='', forSale=false, private=true,
+			archived=true WHERE id=?`, imgId)
+	}
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//AnonymizeUser turns the user into a tombstone, the row stays so the other side of their sales keeps
+//its records but nothing in it points to the person anymore
+func (r *mysqlAccountsRepo) AnonymizeUser(id int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	for _, query := range []string{
+		"DELETE FROM access_tokens WHERE user_id=?",
+		"DELETE FROM recovery_codes WHERE user_id=?",
+		"DELETE FROM security_events WHERE user_id=?",
+		"DELETE FROM passkeys WHERE user_id=?",
+		"DELETE FROM login_events WHERE user_id=?",
+		"DELETE FROM handle_history WHERE user_id=?",
+		"DELETE FROM follows WHERE ? IN (follower_id, followed_id)",
+	} {
+		_, err = tx.Exec(query, id)
+		if err != nil {
+			tx.Rollback()
+			return customErr.DB(err)
+		}
+	}
+	_, err = tx.Exec(`UPDATE users SET username=?, email=?, password='', bio='', avatar='', verified=false,
+		totp_secret='', totp_enabled=false, handle=NULL, deleted_at=? WHERE id=?`,
+		"deleted user", fmt.Sprintf("deleted-%d@deleted.invalid", id), time.Now(), id)
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
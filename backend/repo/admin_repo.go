@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"strings"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type AdminRepoInterface interface {
+	GetUserById(id int) (*dbModels.User, error)
+	GetUsersPage(filter *AdminUserFilter, page *Page) ([]dbModels.User, *PageInfo, error)
+	UpdateRole(id int, role string) error
+	UpdateSuspension(id int, at *time.Time, until *time.Time, reason string) error
+	CreateSecurityEvent(event *dbModels.SecurityEvent) error
+}
+
+//AdminUserFilter narrows down the users an admin lists, Now decides which suspensions are over
+type AdminUserFilter struct {
+	Role      *string
+	Email     *string
+	Verified  *bool
+	Suspended *bool
+	Now       time.Time
+}
+
+//Where compiles the filter to a where clause using ? bindvars
+func (f *AdminUserFilter) Where() (string, []interface{}) {
+	conds := []string{"users.deleted_at IS NULL"}
+	args := []interface{}{}
+	if f.Role != nil {
+		conds = append(conds, "users.role=?")
+		args = append(args, *f.Role)
+	}
+	if f.Email != nil {
+		conds = append(conds, "users.email=?")
+		args = append(args, *f.Email)
+	}
+	if f.Verified != nil {
+		conds = append(conds, "users.verified=?")
+		args = append(args, *f.Verified)
+	}
+	if f.Suspended != nil {
+		suspended := "users.suspended_at IS NOT NULL AND (users.suspended_until IS NULL OR users.suspended_until>?)"
+		if *f.Suspended {
+			conds = append(conds, "("+suspended+")")
+		} else {
+			conds = append(conds, "NOT ("+suspended+")")
+		}
+		args = append(args, f.Now)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+var _ AdminRepoInterface = &adminRepo{}
+var _ AdminRepoInterface = &mysqlAdminRepo{}
+
+type adminRepo struct {
+	repo AdminRepoInterface
+}
+
+type mysqlAdminRepo struct {
+	db *sqlx.DB
+}
+
+func NewAdminRepo(db *sqlx.DB) *adminRepo {
+	mysqlRepo := &mysqlAdminRepo{
+		db,
+	}
+	return &adminRepo{
+		repo: mysqlRepo,
+	}
+}
+
+func (ar *adminRepo) GetUserById(id int) (*dbModels.User, error) {
+	return ar.repo.GetUserById(id)
+}
+
+func (ar *adminRepo) GetUsersPage(filter *AdminUserFilter, page *Page) ([]dbModels.User, *PageInfo, error) {
+	return ar.repo.GetUsersPage(filter, page)
+}
+
+func (ar *adminRepo) UpdateRole(id int, role string) error {
+	return ar.repo.UpdateRole(id, role)
+}
+
+func (ar *adminRepo) UpdateSuspension(id int, at *time.Time, until *time.Time, reason string) error {
+	return ar.repo.UpdateSuspension(id, at, until, reason)
+}
+
+func (ar *adminRepo) CreateSecurityEvent(event *dbModels.SecurityEvent) error {
+	return ar.repo.CreateSecurityEvent(event)
+}
+
+func (r *mysqlAdminRepo) GetUserById(id int) (*dbModels.User, error) {
+	user := dbModels.User{}
+	err := r.db.Get(&user, "SELECT * FROM users WHERE id=? AND deleted_at IS NULL", id)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return &user, nil
+}
+
+func (r *mysqlAdminRepo) GetUsersPage(filter *AdminUserFilter, page *Page) ([]dbModels.User, *PageInfo, error) {
+	if filter == nil {
+		filter = &AdminUserFilter{}
+	}
+	where, args := filter.Where()
+	users := []dbModels.User{}
+	info, err := selectPage(r.db, &users, "users", where, args, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, info, nil
+}
+
+func (r *mysqlAdminRepo) UpdateRole(id int, role string) error {
+	_, err := r.db.Exec("UPDATE users SET role=? WHERE id=? AND deleted_at IS NULL", role, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//UpdateSuspension suspends the user until the given time, forever without one, a nil at lifts the suspension
+func (r *mysqlAdminRepo) UpdateSuspension(id int, at *time.Time, until *time.Time, reason string) error {
+	_, err := r.db.Exec(`UPDATE users SET suspended_at=?, suspended_until=?, suspension_reason=?
+		WHERE id=? AND deleted_at IS NULL`, at, until, reason, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+func (r *mysqlAdminRepo) CreateSecurityEvent(event *dbModels.SecurityEvent) error {
+	_, err := r.db.NamedExec(`INSERT INTO security_events(user_id, kind, ip, user_agent, details, created_at)
+		VALUES (:user_id, :kind, :ip, :user_agent, :details, :created_at)`, event)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminUserFilterTestSuite struct {
+	suite.Suite
+}
+
+func (suite *AdminUserFilterTestSuite) TestWhere() {
+	now := time.Now()
+	where, args := (&AdminUserFilter{}).Where()
+	suite.Equal("users.deleted_at IS NULL", where)
+	suite.Empty(args)
+
+	where, args = (&AdminUserFilter{Role: strPtr("USER"), Verified: boolPtr(false), Suspended: boolPtr(true),
+		Now: now}).Where()
+	suite.Equal("users.deleted_at IS NULL AND users.role=? AND users.verified=? AND (users.suspended_at IS NOT NULL"+
+		" AND (users.suspended_until IS NULL OR users.suspended_until>?))", where)
+	suite.Equal([]interface{}{"USER", false, now}, args)
+
+	where, _ = (&AdminUserFilter{Suspended: boolPtr(false), Now: now}).Where()
+	suite.Contains(where, "AND NOT (users.suspended_at IS NOT NULL")
+}
+
+func TestAdminUserFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminUserFilterTestSuite))
+}
@@ -10,6 +10,17 @@ type AuthRepoInterface interface {
 	GetUserByEmail(email string) (*dbModels.User, error)
 	UpdatePassword(id string, password string) error
 	UpdateVerified(id string) error
+	GetUserById(id string) (*dbModels.User, error)
+	UpdateTwoFactor(id string, secret string, enabled bool) error
+	ReplaceRecoveryCodes(id string, hashes []string) error
+	UseRecoveryCode(id string, hash string) (bool, error)
+	UseTotpCounter(id string, counter int64) (bool, error)
+	CreateSecurityEvent(event *dbModels.SecurityEvent) error
+	CreateLoginEvent(event *dbModels.LoginEvent) error
+	IsNewLoginDevice(id string, device string) (bool, error)
+	GetLoginEvents(id string, limit int) ([]dbModels.LoginEvent, error)
+	CountByEmail(email string) (int, error)
+	UpdateEmail(id string, email string) error
 }
 
 var _ AuthRepoInterface = &authRepo{}
@@ -44,15 +55,56 @@ func (ar *authRepo) UpdateVerified(id string) error {
 	return ar.repo.UpdateVerified(id)
 }
 
+func (ar *authRepo) GetUserById(id string) (*dbModels.User, error) {
+	return ar.repo.GetUserById(id)
+}
+
+func (ar *authRepo) UpdateTwoFactor(id string, secret string, enabled bool) error {
+	return ar.repo.UpdateTwoFactor(id, secret, enabled)
+}
+
+func (ar *authRepo) ReplaceRecoveryCodes(id string, hashes []string) error {
+	return ar.repo.ReplaceRecoveryCodes(id, hashes)
+}
+
+func (ar *authRepo) UseRecoveryCode(id string, hash string) (bool, error) {
+	return ar.repo.UseRecoveryCode(id, hash)
+}
+
+func (ar *authRepo) UseTotpCounter(id string, counter int64) (bool, error) {
+	return ar.repo.UseTotpCounter(id, counter)
+}
+
+func (ar *authRepo) CreateSecurityEvent(event *dbModels.SecurityEvent) error {
+	return ar.repo.CreateSecurityEvent(event)
+}
+
+func (ar *authRepo) CreateLoginEvent(event *dbModels.LoginEvent) error {
+	return ar.repo.CreateLoginEvent(event)
+}
+
+func (ar *authRepo) IsNewLoginDevice(id string, device string) (bool, error) {
+	return ar.repo.IsNewLoginDevice(id, device)
+}
+
+func (ar *authRepo) GetLoginEvents(id string, limit int) ([]dbModels.LoginEvent, error) {
+	return ar.repo.GetLoginEvents(id, limit)
+}
+
+func (ar *authRepo) CountByEmail(email string) (int, error) {
+	return ar.repo.CountByEmail(email)
+}
+
+func (ar *authRepo) UpdateEmail(id string, email string) error {
+	return ar.repo.UpdateEmail(id, email)
+}
+
 func (r *mysqlAuthRepo) GetUserByEmail(email string) (*dbModels.User, error) {
 	user := dbModels.User{}
-	err := r.db.Get(&user, "SELECT * FROM users WHERE email=?", email)
+	err := r.db.Get(&user, "SELECT * FROM users WHERE email=? AND deleted_at IS NULL", email)
 	if err != nil {
 		return nil, customErr.BadRequest(err.Error())
 	}
-	if !user.Verfied {
-		return nil, customErr.UnProcessable("your account in unverified! go to http://localhost:8025 to verify it")
-	}
 	return &user, nil
 }
 
@@ -71,3 +123,133 @@ func (r *mysqlAuthRepo) UpdateVerified(id string) error {
 	}
 	return nil
 }
+
+func (r *mysqlAuthRepo) GetUserById(id string) (*dbModels.User, error) {
+	user := dbModels.User{}
+	err := r.db.Get(&user, "SELECT * FROM users WHERE id=? AND deleted_at IS NULL", id)
+	if err != nil {
+		return nil, customErr.NotFound(err.Error())
+	}
+	return &user, nil
+}
+
+func (r *mysqlAuthRepo) UpdateTwoFactor(id string, secret string, enabled bool) error {
+	_, err := r.db.Exec(`UPDATE users SET totp_secret=?, totp_enabled=? WHERE id=?`, secret, enabled, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//ReplaceRecoveryCodes drops the old recovery codes of the user and stores the new hashes
+func (r *mysqlAuthRepo) ReplaceRecoveryCodes(id string, hashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	_, err = tx.Exec(`DELETE FROM recovery_codes WHERE user_id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	for _, hash := range hashes {
+		_, err = tx.Exec(`INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)`, id, hash)
+		if err != nil {
+			tx.Rollback()
+			return customErr.DB(err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//UseRecoveryCode marks the code as used, it returns false if the code doesn't exist or was already used
+func (r *mysqlAuthRepo) UseRecoveryCode(id string, hash string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE recovery_codes SET used=true WHERE user_id=? AND code_hash=? AND used=false`, id, hash)
+	if err != nil {
+		return false, customErr.DB(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, customErr.DB(err)
+	}
+	return affected == 1, nil
+}
+
+//UseTotpCounter records the time step of the TOTP code that was accepted, it returns false if a code of the same
+//or a later step was already used
+func (r *mysqlAuthRepo) UseTotpCounter(id string, counter int64) (bool, error) {
+	res, err := r.db.Exec(`UPDATE users SET totp_last_counter=? WHERE id=? AND totp_last_counter<?`, counter, id,
+		counter)
+	if err != nil {
+		return false, customErr.DB(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, customErr.DB(err)
+	}
+	return affected == 1, nil
+}
+
+func (r *mysqlAuthRepo) CreateSecurityEvent(event *dbModels.SecurityEvent) error {
+	_, err := r.db.NamedExec(`INSERT INTO security_events(user_id, kind, ip, user_agent, details, created_at)
+		VALUES (:user_id, :kind, :ip, :user_agent, :details, :created_at)`, event)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+func (r *mysqlAuthRepo) CreateLoginEvent(event *dbModels.LoginEvent) error {
+	_, err := r.db.NamedExec(`INSERT INTO login_events(user_id, method, success, ip, user_agent, device, created_at)
+		VALUES (:user_id, :method, :success, :ip, :user_agent, :device, :created_at)`, event)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//IsNewLoginDevice tells if the user logged in before but never from the device, the very first login of a
+//user isn't from a new device
+func (r *mysqlAuthRepo) IsNewLoginDevice(id string, device string) (bool, error) {
+	counts := struct {
+		Total      int `db:"total"`
+		FromDevice int `db:"from_device"`
+	}{}
+	err := r.db.Get(&counts, `SELECT COUNT(*) AS total, COALESCE(SUM(device=?), 0) AS from_device
+		FROM login_events WHERE user_id=? AND success=true`, device, id)
+	if err != nil {
+		return false, customErr.DB(err)
+	}
+	return counts.Total > 0 && counts.FromDevice == 0, nil
+}
+
+func (r *mysqlAuthRepo) GetLoginEvents(id string, limit int) ([]dbModels.LoginEvent, error) {
+	events := []dbModels.LoginEvent{}
+	err := r.db.Select(&events, `SELECT * FROM login_events WHERE user_id=? ORDER BY created_at DESC, id DESC
+		LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return events, nil
+}
+
+func (r *mysqlAuthRepo) CountByEmail(email string) (int, error) {
+	c := 0
+	err := r.db.Get(&c, "SELECT COUNT(*) FROM users WHERE email=?", email)
+	if err != nil {
+		return -1, customErr.DB(err)
+	}
+	return c, nil
+}
+
+func (r *mysqlAuthRepo) UpdateEmail(id string, email string) error {
+	_, err := r.db.Exec(`UPDATE users SET email=? WHERE id=?`, email, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
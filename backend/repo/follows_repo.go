@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type FollowsRepoInterface interface {
+	GetUserById(id int) (*dbModels.User, error)
+	Follow(followerId int, followedId int) error
+	Unfollow(followerId int, followedId int) error
+	GetFollowersPage(userId int, page *Page) ([]dbModels.Follow, *PageInfo, error)
+	GetFollowingPage(userId int, page *Page) ([]dbModels.Follow, *PageInfo, error)
+}
+
+var _ FollowsRepoInterface = &followsRepo{}
+var _ FollowsRepoInterface = &mysqlFollowsRepo{}
+
+type followsRepo struct {
+	repo FollowsRepoInterface
+}
+
+type mysqlFollowsRepo struct {
+	db *sqlx.DB
+}
+
+func NewFollowsRepo(db *sqlx.DB) *followsRepo {
+	mysqlRepo := &mysqlFollowsRepo{
+		db,
+	}
+	return &followsRepo{
+		repo: mysqlRepo,
+	}
+}
+
+func (r *followsRepo) GetUserById(id int) (*dbModels.User, error) {
+	return r.repo.GetUserById(id)
+}
+
+func (r *followsRepo) Follow(followerId int, followedId int) error {
+	return r.repo.Follow(followerId, followedId)
+}
+
+func (r *followsRepo) Unfollow(followerId int, followedId int) error {
+	return r.repo.Unfollow(followerId, followedId)
+}
+
+func (r *followsRepo) GetFollowersPage(userId int, page *Page) ([]dbModels.Follow, *PageInfo, error) {
+	return r.repo.GetFollowersPage(userId, page)
+}
+
+func (r *followsRepo) GetFollowingPage(userId int, page *Page) ([]dbModels.Follow, *PageInfo, error) {
+	return r.repo.GetFollowingPage(userId, page)
+}
+
+func (r *mysqlFollowsRepo) GetUserById(id int) (*dbModels.User, error) {
+	user := dbModels.User{}
+	err := r.db.Get(&user, "SELECT * FROM users WHERE id=? AND deleted_at IS NULL", id)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return &user, nil
+}
+
+//Follow does nothing when the user already follows the other one, so following keeps its first date
+func (r *mysqlFollowsRepo) Follow(followerId int, followedId int) error {
+	_, err := r.db.Exec("INSERT IGNORE INTO follows(follower_id, followed_id, created_at) VALUES (?, ?, ?)",
+		followerId, followedId, time.Now())
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+func (r *mysqlFollowsRepo) Unfollow(followerId int, followedId int) error {
+	_, err := r.db.Exec("DELETE FROM follows WHERE follower_id=? AND followed_id=?", followerId, followedId)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//GetFollowersPage lists who follows the user, the ones who followed last first
+func (r *mysqlFollowsRepo) GetFollowersPage(userId int, page *Page) ([]dbModels.Follow, *PageInfo, error) {
+	follows := []dbModels.Follow{}
+	info, err := selectPage(r.db, &follows, "follows", "follows.followed_id=? AND follows.follower_id IN "+
+		"(SELECT users.id FROM users WHERE users.deleted_at IS NULL)", []interface{}{userId}, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	return follows, info, nil
+}
+
+//GetFollowingPage lists who the user follows, the ones they followed last first
+func (r *mysqlFollowsRepo) GetFollowingPage(userId int, page *Page) ([]dbModels.Follow, *PageInfo, error) {
+	follows := []dbModels.Follow{}
+	info, err := selectPage(r.db, &follows, "follows", "follows.follower_id=? AND follows.followed_id IN "+
+		"(SELECT users.id FROM users WHERE users.deleted_at IS NULL)", []interface{}{userId}, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	return follows, info, nil
+}
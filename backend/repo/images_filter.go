@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"strconv"
+	"strings"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/jmoiron/sqlx"
+)
+
+//AnonymousViewer is the viewer id of visitors who aren't logged in, they only see public images
+const AnonymousViewer = 0
+
+//ImageFilter is a typed images search that compiles to a parameterized query
+type ImageFilter struct {
+	ViewerID int
+	//ViewerIsAdmin lets admins see the images of private profiles
+	ViewerIsAdmin        bool
+	ID                   *int
+	UserID               *int
+	Title                *string
+	Labels               []string
+	MatchAll             bool
+	Private              *bool
+	ForSale              *bool
+	PriceLimit           *float64
+	Archived             *bool
+	DiscountPercentLimit *int
+	//FollowerID keeps the images of the users they follow, for the feed
+	FollowerID *int
+}
+
+//NewImageFilter converts the graphql filter input into an ImageFilter for the viewer.
+//The image upload field is resolved to labels by the service layer.
+func NewImageFilter(input *model.ImageFilterInput, viewerId int) (*ImageFilter, error) {
+	filter := &ImageFilter{ViewerID: viewerId}
+	if input == nil {
+		return filter, nil
+	}
+	if input.ID != nil {
+		id, err := strconv.Atoi(*input.ID)
+		if err != nil {
+			return nil, customErr.BadRequest(err.Error())
+		}
+		filter.ID = &id
+	}
+	if input.UserID != nil {
+		userId, err := strconv.Atoi(*input.UserID)
+		if err != nil {
+			return nil, customErr.BadRequest(err.Error())
+		}
+		filter.UserID = &userId
+	}
+	for _, l := range input.Labels {
+		filter.Labels = append(filter.Labels, strings.ToLower(l))
+	}
+	filter.MatchAll = input.MatchAll != nil && *input.MatchAll
+	filter.Title = input.Title
+	filter.Private = input.Private
+	filter.ForSale = input.ForSale
+	filter.PriceLimit = input.PriceLimit
+	filter.Archived = input.Archived
+	filter.DiscountPercentLimit = input.DiscountPercentLimit
+	return filter, nil
+}
+
+//isOwner reports whether the filter is restricted to the viewer's own images
+func (f *ImageFilter) isOwner() bool {
+	return f.ViewerID != AnonymousViewer && f.UserID != nil && *f.UserID == f.ViewerID
+}
+
+//Where compiles the filter to a where clause using ? bindvars, slices are already expanded
+func (f *ImageFilter) Where() (string, []interface{}, error) {
+	conds := []string{}
+	args := []interface{}{}
+
+	if f.ID != nil {
+		conds = append(conds, "images.id=?")
+		args = append(args, *f.ID)
+	}
+	if f.UserID != nil {
+		conds = append(conds, "images.user_id=?")
+		args = append(args, *f.UserID)
+	}
+	if f.FollowerID != nil {
+		conds = append(conds, "images.user_id IN (SELECT follows.followed_id FROM follows JOIN users ON "+
+			"users.id=follows.followed_id WHERE follows.follower_id=? AND users.deleted_at IS NULL)")
+		args = append(args, *f.FollowerID)
+	}
+	if f.isOwner() {
+		if f.Private != nil {
+			conds = append(conds, "images.private=?")
+			args = append(args, *f.Private)
+		}
+		if f.Archived != nil {
+			conds = append(conds, "images.archived=?")
+			args = append(args, *f.Archived)
+		}
+	} else {
+		//other users' private and archived images are never listed
+		conds = append(conds, "images.private=False", "images.archived=False")
+		//and neither are the images of private profiles, except the viewer's own ones
+		if !f.ViewerIsAdmin {
+			conds = append(conds, "(images.user_id=? OR images.user_id IN "+
+				"(SELECT users.id FROM users WHERE users.private_profile=False))")
+			args = append(args, f.ViewerID)
+		}
+	}
+	if len(f.Labels) > 0 {
+		if f.MatchAll {
+			conds = append(conds, "images.id IN (SELECT image_id FROM labels WHERE tag IN (?) "+
+				"GROUP BY image_id HAVING COUNT(DISTINCT tag)=?)")
+			args = append(args, f.Labels, countDistinct(f.Labels))
+		} else {
+			conds = append(conds, "images.id IN (SELECT image_id FROM labels WHERE tag IN (?))")
+			args = append(args, f.Labels)
+		}
+	}
+	if f.ForSale != nil {
+		conds = append(conds, "images.forSale=?")
+		args = append(args, *f.ForSale)
+	}
+	if f.PriceLimit != nil {
+		conds = append(conds, "images.price<=?")
+		args = append(args, *f.PriceLimit)
+	}
+	if f.DiscountPercentLimit != nil {
+		conds = append(conds, "images.discountPercent<=?")
+		args = append(args, *f.DiscountPercentLimit)
+	}
+	if f.Title != nil {
+		conds = append(conds, `LOWER(images.title) LIKE ?`)
+		args = append(args, "%"+escapeLike(strings.ToLower(*f.Title))+"%")
+	}
+
+	query, args, err := sqlx.In(strings.Join(conds, " AND "), args...)
+	if err != nil {
+		return "", nil, customErr.Internal(err.Error())
+	}
+	return query, args, nil
+}
+
+//ToSql compiles the filter to a full select query using ? bindvars
+func (f *ImageFilter) ToSql() (string, []interface{}, error) {
+	where, args, err := f.Where()
+	if err != nil {
+		return "", nil, err
+	}
+	return "SELECT images.* FROM images WHERE " + where, args, nil
+}
+
+func countDistinct(list []string) int {
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		seen[item] = true
+	}
+	return len(seen)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImageFilterTestSuite struct {
+	suite.Suite
+}
+
+func strPtr(s string) *string     { return &s }
+func boolPtr(b bool) *bool        { return &b }
+func floatPtr(f float64) *float64 { return &f }
+func intPtr(i int) *int           { return &i }
+
+const publicOnly = "images.private=False AND images.archived=False AND (images.user_id=? OR images.user_id IN " +
+	"(SELECT users.id FROM users WHERE users.private_profile=False))"
+
+func (suite *ImageFilterTestSuite) TestToSql() {
+	tests := []struct {
+		name  string
+		input *model.ImageFilterInput
+		query string
+		args  []interface{}
+	}{
+		{
+			name:  "no filter lists public images",
+			input: nil,
+			query: "SELECT images.* FROM images WHERE " + publicOnly,
+			args:  []interface{}{1},
+		},
+		{
+			name:  "id",
+			input: &model.ImageFilterInput{ID: strPtr("3")},
+			query: "SELECT images.* FROM images WHERE images.id=? AND " + publicOnly,
+			args:  []interface{}{3, 1},
+		},
+		{
+			name:  "other user hides private and archived images",
+			input: &model.ImageFilterInput{UserID: strPtr("2"), Private: boolPtr(true), Archived: boolPtr(true)},
+			query: "SELECT images.* FROM images WHERE images.user_id=? AND " + publicOnly,
+			args:  []interface{}{2, 1},
+		},
+		{
+			name:  "owner can filter private and archived images",
+			input: &model.ImageFilterInput{UserID: strPtr("1"), Private: boolPtr(true), Archived: boolPtr(false)},
+			query: "SELECT images.* FROM images WHERE images.user_id=? AND images.private=? AND images.archived=?",
+			args:  []interface{}{1, true, false},
+		},
+		{
+			name:  "labels match any",
+			input: &model.ImageFilterInput{Labels: []string{"Cat", "dog"}},
+			query: "SELECT images.* FROM images WHERE " + publicOnly +
+				" AND images.id IN (SELECT image_id FROM labels WHERE tag IN (?, ?))",
+			args: []interface{}{1, "cat", "dog"},
+		},
+		{
+			name:  "labels match all",
+			input: &model.ImageFilterInput{Labels: []string{"cat", "dog", "cat"}, MatchAll: boolPtr(true)},
+			query: "SELECT images.* FROM images WHERE " + publicOnly +
+				" AND images.id IN (SELECT image_id FROM labels WHERE tag IN (?, ?, ?) GROUP BY image_id HAVING COUNT(DISTINCT tag)=?)",
+			args: []interface{}{1, "cat", "dog", "cat", 2},
+		},
+		{
+			name: "price, discount and sale",
+			input: &model.ImageFilterInput{ForSale: boolPtr(true), PriceLimit: floatPtr(9.5),
+				DiscountPercentLimit: intPtr(20)},
+			query: "SELECT images.* FROM images WHERE " + publicOnly +
+				" AND images.forSale=? AND images.price<=? AND images.discountPercent<=?",
+			args: []interface{}{1, true, 9.5, 20},
+		},
+		{
+			name:  "title is bound and escaped",
+			input: &model.ImageFilterInput{Title: strPtr("O'Neil 100%_")},
+			query: "SELECT images.* FROM images WHERE " + publicOnly + " AND LOWER(images.title) LIKE ?",
+			args:  []interface{}{1, `%o'neil 100\%\_%`},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			filter, err := NewImageFilter(tt.input, 1)
+			suite.Nil(err)
+			query, args, err := filter.ToSql()
+			suite.Nil(err)
+			suite.Equal(tt.query, query)
+			suite.Equal(tt.args, args)
+		})
+	}
+}
+
+func (suite *ImageFilterTestSuite) TestAnonymousViewerIsNeverOwner() {
+	filter, err := NewImageFilter(&model.ImageFilterInput{UserID: strPtr("0"), Private: boolPtr(true)}, AnonymousViewer)
+	suite.Nil(err)
+	query, args, err := filter.ToSql()
+	suite.Nil(err)
+	suite.Equal("SELECT images.* FROM images WHERE images.user_id=? AND "+publicOnly, query)
+	suite.Equal([]interface{}{0, 0}, args)
+}
+
+func (suite *ImageFilterTestSuite) TestFeedOnlyListsPublicImages() {
+	//the feed never lists private or archived images
+	filter := &ImageFilter{ViewerID: 1, FollowerID: intPtr(1)}
+	query, args, err := filter.ToSql()
+	suite.Nil(err)
+	suite.Equal("SELECT images.* FROM images WHERE images.user_id IN (SELECT follows.followed_id FROM follows "+
+		"JOIN users ON users.id=follows.followed_id WHERE follows.follower_id=? AND users.deleted_at IS NULL) AND "+
+		publicOnly, query)
+	suite.Equal([]interface{}{1, 1}, args)
+}
+
+func (suite *ImageFilterTestSuite) TestAdminsSeePrivateProfiles() {
+	filter, err := NewImageFilter(&model.ImageFilterInput{UserID: strPtr("2")}, 1)
+	suite.Nil(err)
+	filter.ViewerIsAdmin = true
+	query, args, err := filter.ToSql()
+	suite.Nil(err)
+	suite.Equal("SELECT images.* FROM images WHERE images.user_id=? AND images.private=False AND "+
+		"images.archived=False", query)
+	suite.Equal([]interface{}{2}, args)
+}
+
+func (suite *ImageFilterTestSuite) TestInvalidIds() {
+	_, err := NewImageFilter(&model.ImageFilterInput{UserID: strPtr("1 OR 1=1")}, 1)
+	suite.NotNil(err)
+	_, err = NewImageFilter(&model.ImageFilterInput{ID: strPtr("x")}, 1)
+	suite.NotNil(err)
+}
+
+func TestImageFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(ImageFilterTestSuite))
+}
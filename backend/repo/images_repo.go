@@ -1,7 +1,6 @@
 package repo
 
 import (
-	"context"
 	"fmt"
 
 	dbModels "github.com/gasser707/go-gql-server/databases/models"
@@ -10,9 +9,9 @@ import (
 )
 
 type ImagesRepoInterface interface {
-	GetById(imgId int, userId int) (*dbModels.Image, []string, error)
-	GetAllPublic(ctx context.Context) ([]*dbModels.Image, error)
-	GetByFilter(filter string) ([]*dbModels.Image, error)
+	GetById(imgId int, userId int, isAdmin bool) (*dbModels.Image, []string, error)
+	GetByFilter(filter *ImageFilter) ([]*dbModels.Image, error)
+	GetPage(filter *ImageFilter, page *Page) ([]*dbModels.Image, *PageInfo, error)
 	GetImageIfOwner(imgId int, userId int) (*dbModels.Image, error)
 	Create(dbImg *dbModels.Image) (imgId int64, err error)
 	Update(id int, img *dbModels.Image) error
@@ -45,22 +44,21 @@ func NewImagesRepo(db *sqlx.DB) *imagesRepo {
 	}
 }
 
-func (r *imagesRepo) GetById(imgId int, userId int) (*dbModels.Image, []string, error) {
-	return r.repo.GetById(imgId, userId)
+func (r *imagesRepo) GetById(imgId int, userId int, isAdmin bool) (*dbModels.Image, []string, error) {
+	return r.repo.GetById(imgId, userId, isAdmin)
 }
 
 func (r *imagesRepo) GetImageIfOwner(imgId int, userId int) (*dbModels.Image, error) {
 	return r.repo.GetImageIfOwner(imgId, userId)
 }
 
-func (r *imagesRepo) GetAllPublic(ctx context.Context) ([]*dbModels.Image, error) {
-	return r.repo.GetAllPublic(ctx)
+func (r *imagesRepo) GetByFilter(filter *ImageFilter) ([]*dbModels.Image, error) {
+	return r.repo.GetByFilter(filter)
 
 }
 
-func (r *imagesRepo) GetByFilter(filter string) ([]*dbModels.Image, error) {
-	return r.repo.GetByFilter(filter)
-
+func (r *imagesRepo) GetPage(filter *ImageFilter, page *Page) ([]*dbModels.Image, *PageInfo, error) {
+	return r.repo.GetPage(filter, page)
 }
 
 func (r *imagesRepo) Create(dbImg *dbModels.Image) (imgId int64, err error) {
@@ -102,13 +100,24 @@ func (r *imagesRepo) checkUserBought(imgId int, userId int) bool {
 	return r.repo.checkUserBought(imgId, userId)
 }
 
-func (r *mysqlImagesRepo) GetById(imgId int, userId int) (*dbModels.Image, []string, error) {
+func (r *mysqlImagesRepo) GetById(imgId int, userId int, isAdmin bool) (*dbModels.Image, []string, error) {
 	img := dbModels.Image{}
 	err := r.db.Get(&img, "SELECT * FROM images WHERE id=?", imgId)
 	if err != nil {
 		return nil, nil, customErr.DB(err)
-	} else if ok := r.checkUserBought(imgId, int(userId)); img.UserID != int(userId) && (img.Private || img.Archived) && !ok {
-		return nil, nil, customErr.Forbidden(err.Error())
+	}
+	if userId == AnonymousViewer || img.UserID != userId {
+		privateProfile := false
+		err = r.db.Get(&privateProfile, "SELECT private_profile FROM users WHERE id=?", img.UserID)
+		if err != nil {
+			return nil, nil, customErr.DB(err)
+		}
+		//private and archived images are only shown to their owner and the users who bought them, and so are
+		//the images of private profiles, which admins see too
+		hidden := img.Private || img.Archived || (privateProfile && !isAdmin)
+		if hidden && (userId == AnonymousViewer || !r.checkUserBought(imgId, userId)) {
+			return nil, nil, customErr.Forbidden("this image is private")
+		}
 	}
 	labels, err := r.GetImageLabels(imgId)
 	if err != nil {
@@ -126,22 +135,30 @@ func (r *mysqlImagesRepo) GetImageIfOwner(imgId int, userId int) (*dbModels.Imag
 	return &img, nil
 }
 
-func (r *mysqlImagesRepo) GetAllPublic(ctx context.Context) ([]*dbModels.Image, error) {
+func (r *mysqlImagesRepo) GetByFilter(filter *ImageFilter) ([]*dbModels.Image, error) {
+	query, args, err := filter.ToSql()
+	if err != nil {
+		return nil, err
+	}
 	dbImgs := []*dbModels.Image{}
-	err := r.db.Select(&dbImgs, "SELECT * FROM images WHERE private=False AND archived=False")
+	err = r.db.Select(&dbImgs, r.db.Rebind(query), args...)
 	if err != nil {
 		return nil, customErr.DB(err)
 	}
 	return dbImgs, nil
 }
 
-func (r *mysqlImagesRepo) GetByFilter(filter string) ([]*dbModels.Image, error) {
+func (r *mysqlImagesRepo) GetPage(filter *ImageFilter, page *Page) ([]*dbModels.Image, *PageInfo, error) {
+	where, args, err := filter.Where()
+	if err != nil {
+		return nil, nil, err
+	}
 	dbImgs := []*dbModels.Image{}
-	err := r.db.Select(&dbImgs, filter)
+	info, err := selectPage(r.db, &dbImgs, "images", where, args, page)
 	if err != nil {
-		return nil, customErr.DB(err)
+		return nil, nil, err
 	}
-	return dbImgs, nil
+	return dbImgs, info, nil
 }
 
 func (r *mysqlImagesRepo) Create(dbImg *dbModels.Image) (imgId int64, err error) {
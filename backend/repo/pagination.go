@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+//Page holds relay style pagination arguments, rows are ordered newest first on (created_at, id)
+type Page struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+}
+
+//PageInfo describes the page that was fetched
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	TotalCount      int
+}
+
+//Cursor is the decoded form of the opaque cursor handed to clients
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+func NewPage(first *int, after *string, last *int, before *string) (*Page, error) {
+	if first != nil && last != nil {
+		return nil, customErr.BadRequest("first and last can't be used together")
+	}
+	if (first != nil && *first < 0) || (last != nil && *last < 0) {
+		return nil, customErr.BadRequest("first and last must be positive")
+	}
+	return &Page{First: first, After: after, Last: last, Before: before}, nil
+}
+
+func EncodeCursor(createdAt time.Time, id int) string {
+	raw := fmt.Sprintf("%d:%d", createdAt.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(cursor string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, customErr.BadRequest("invalid cursor")
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return nil, customErr.BadRequest("invalid cursor")
+	}
+	nano, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, customErr.BadRequest("invalid cursor")
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, customErr.BadRequest("invalid cursor")
+	}
+	return &Cursor{CreatedAt: time.Unix(0, nano).UTC(), ID: id}, nil
+}
+
+//backward is true when the client pages from the end of the list
+func (p *Page) backward() bool {
+	return p.Last != nil
+}
+
+func (p *Page) limit() int {
+	size := DefaultPageSize
+	if p.First != nil {
+		size = *p.First
+	} else if p.Last != nil {
+		size = *p.Last
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+	return size
+}
+
+//query wraps the where clause of table with the cursor conditions, ordering and limit.
+//One extra row is fetched to know if there are more rows after the page.
+func (p *Page) query(table string, where string, args []interface{}) (string, []interface{}, error) {
+	if where == "" {
+		where = "TRUE"
+	}
+	queryArgs := append([]interface{}{}, args...)
+	if p.After != nil {
+		c, err := DecodeCursor(*p.After)
+		if err != nil {
+			return "", nil, err
+		}
+		where += fmt.Sprintf(" AND (%[1]s.created_at<? OR (%[1]s.created_at=? AND %[1]s.id<?))", table)
+		queryArgs = append(queryArgs, c.CreatedAt, c.CreatedAt, c.ID)
+	}
+	if p.Before != nil {
+		c, err := DecodeCursor(*p.Before)
+		if err != nil {
+			return "", nil, err
+		}
+		where += fmt.Sprintf(" AND (%[1]s.created_at>? OR (%[1]s.created_at=? AND %[1]s.id>?))", table)
+		queryArgs = append(queryArgs, c.CreatedAt, c.CreatedAt, c.ID)
+	}
+	order := "DESC"
+	if p.backward() {
+		order = "ASC"
+	}
+	query := fmt.Sprintf("SELECT %[1]s.* FROM %[1]s WHERE %[2]s ORDER BY %[1]s.created_at %[3]s, %[1]s.id %[3]s LIMIT %[4]d",
+		table, where, order, p.limit()+1)
+	return query, queryArgs, nil
+}
+
+//info returns how many of the fetched rows belong to the page and the page info
+func (p *Page) info(fetched int) (int, *PageInfo) {
+	keep := fetched
+	hasMore := fetched > p.limit()
+	if hasMore {
+		keep = p.limit()
+	}
+	info := &PageInfo{}
+	if p.backward() {
+		info.HasPreviousPage = hasMore
+		info.HasNextPage = p.Before != nil
+	} else {
+		info.HasNextPage = hasMore
+		info.HasPreviousPage = p.After != nil
+	}
+	return keep, info
+}
+
+//selectPage fetches a page of table into dest, a pointer to a slice of db models
+func selectPage(db *sqlx.DB, dest interface{}, table string, where string, args []interface{},
+	page *Page) (*PageInfo, error) {
+	if page == nil {
+		page = &Page{}
+	}
+	query, queryArgs, err := page.query(table, where, args)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Select(dest, db.Rebind(query), queryArgs...)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	keep, info := page.info(rows.Len())
+	rows.Set(rows.Slice(0, keep))
+	if page.backward() {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, keep-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if where == "" {
+		where = "TRUE"
+	}
+	err = db.Get(&info.TotalCount, db.Rebind(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, where)), args...)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return info, nil
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PaginationTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PaginationTestSuite) TestCursorRoundTrip() {
+	createdAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	cursor := EncodeCursor(createdAt, 42)
+
+	decoded, err := DecodeCursor(cursor)
+	suite.Nil(err)
+	suite.Equal(&Cursor{CreatedAt: createdAt, ID: 42}, decoded)
+
+	_, err = DecodeCursor("not a cursor")
+	suite.NotNil(err)
+}
+
+func (suite *PaginationTestSuite) TestNewPage() {
+	one := 1
+	_, err := NewPage(&one, nil, &one, nil)
+	suite.NotNil(err)
+
+	negative := -1
+	_, err = NewPage(&negative, nil, nil, nil)
+	suite.NotNil(err)
+}
+
+func (suite *PaginationTestSuite) TestQuery() {
+	createdAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	cursor := EncodeCursor(createdAt, 7)
+	first, last := 10, 5
+
+	tests := []struct {
+		name  string
+		page  *Page
+		query string
+		args  []interface{}
+	}{
+		{
+			name:  "default page",
+			page:  &Page{},
+			query: "SELECT sales.* FROM sales WHERE sales.buyer_id=? ORDER BY sales.created_at DESC, sales.id DESC LIMIT 21",
+			args:  []interface{}{1},
+		},
+		{
+			name: "first after",
+			page: &Page{First: &first, After: &cursor},
+			query: "SELECT sales.* FROM sales WHERE sales.buyer_id=? AND (sales.created_at<? OR " +
+				"(sales.created_at=? AND sales.id<?)) ORDER BY sales.created_at DESC, sales.id DESC LIMIT 11",
+			args: []interface{}{1, createdAt, createdAt, 7},
+		},
+		{
+			name: "last before",
+			page: &Page{Last: &last, Before: &cursor},
+			query: "SELECT sales.* FROM sales WHERE sales.buyer_id=? AND (sales.created_at>? OR " +
+				"(sales.created_at=? AND sales.id>?)) ORDER BY sales.created_at ASC, sales.id ASC LIMIT 6",
+			args: []interface{}{1, createdAt, createdAt, 7},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			query, args, err := tt.page.query("sales", "sales.buyer_id=?", []interface{}{1})
+			suite.Nil(err)
+			suite.Equal(tt.query, query)
+			suite.Equal(tt.args, args)
+		})
+	}
+}
+
+func (suite *PaginationTestSuite) TestInfo() {
+	first, last := 2, 2
+	cursor := EncodeCursor(time.Now(), 1)
+
+	keep, info := (&Page{First: &first}).info(3)
+	suite.Equal(2, keep)
+	suite.Equal(&PageInfo{HasNextPage: true}, info)
+
+	keep, info = (&Page{First: &first, After: &cursor}).info(2)
+	suite.Equal(2, keep)
+	suite.Equal(&PageInfo{HasPreviousPage: true}, info)
+
+	keep, info = (&Page{Last: &last, Before: &cursor}).info(3)
+	suite.Equal(2, keep)
+	suite.Equal(&PageInfo{HasNextPage: true, HasPreviousPage: true}, info)
+}
+
+func TestPaginationTestSuite(t *testing.T) {
+	suite.Run(t, new(PaginationTestSuite))
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type PasskeysRepoInterface interface {
+	Create(passkey *dbModels.Passkey) (int64, error)
+	GetAllByUser(userId int) ([]dbModels.Passkey, error)
+	Delete(id int, userId int) error
+	UpdateSignCount(id int, signCount uint32, lastUsed time.Time) error
+}
+
+var _ PasskeysRepoInterface = &passkeysRepo{}
+var _ PasskeysRepoInterface = &mysqlPasskeysRepo{}
+
+type passkeysRepo struct {
+	repo PasskeysRepoInterface
+}
+
+type mysqlPasskeysRepo struct {
+	db *sqlx.DB
+}
+
+func NewPasskeysRepo(db *sqlx.DB) *passkeysRepo {
+	mysqlRepo := &mysqlPasskeysRepo{
+		db,
+	}
+	return &passkeysRepo{
+		repo: mysqlRepo,
+	}
+}
+
+func (pr *passkeysRepo) Create(passkey *dbModels.Passkey) (int64, error) {
+	return pr.repo.Create(passkey)
+}
+
+func (pr *passkeysRepo) GetAllByUser(userId int) ([]dbModels.Passkey, error) {
+	return pr.repo.GetAllByUser(userId)
+}
+
+func (pr *passkeysRepo) Delete(id int, userId int) error {
+	return pr.repo.Delete(id, userId)
+}
+
+func (pr *passkeysRepo) UpdateSignCount(id int, signCount uint32, lastUsed time.Time) error {
+	return pr.repo.UpdateSignCount(id, signCount, lastUsed)
+}
+
+func (r *mysqlPasskeysRepo) Create(passkey *dbModels.Passkey) (int64, error) {
+	result, err := r.db.NamedExec(`INSERT INTO passkeys(user_id, name, credential_id, public_key, attestation_type,
+		aaguid, sign_count, created_at) VALUES (:user_id, :name, :credential_id, :public_key, :attestation_type,
+		:aaguid, :sign_count, :created_at)`, passkey)
+	if err != nil {
+		return -1, customErr.DB(err)
+	}
+	passkeyId, _ := result.LastInsertId()
+	return passkeyId, nil
+}
+
+func (r *mysqlPasskeysRepo) GetAllByUser(userId int) ([]dbModels.Passkey, error) {
+	passkeys := []dbModels.Passkey{}
+	err := r.db.Select(&passkeys, "SELECT * FROM passkeys WHERE user_id=? ORDER BY created_at DESC, id DESC", userId)
+	if err != nil {
+		return nil, customErr.DB(err)
+	}
+	return passkeys, nil
+}
+
+func (r *mysqlPasskeysRepo) Delete(id int, userId int) error {
+	result, err := r.db.Exec(`DELETE FROM passkeys WHERE id=? AND user_id=?`, id, userId)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	if affected == 0 {
+		return customErr.NotFound("passkey not found")
+	}
+	return nil
+}
+
+func (r *mysqlPasskeysRepo) UpdateSignCount(id int, signCount uint32, lastUsed time.Time) error {
+	_, err := r.db.Exec(`UPDATE passkeys SET sign_count=?, last_used_at=? WHERE id=?`, signCount, lastUsed, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type SalesRepoInterface interface {
-	GetAll(userId int) ([]dbModels.Sale, error)
+	GetPage(userId int, page *Page) ([]dbModels.Sale, *PageInfo, error)
 	Create(sale *dbModels.Sale) (int64, error)
 	GetImageById(imgId int, userId int) (*dbModels.Image, error)
 }
@@ -32,8 +32,8 @@ func NewSalesRepo(db *sqlx.DB) *salesRepo {
 	}
 }
 
-func (sr *salesRepo) GetAll(userId int) ([]dbModels.Sale, error) {
-	return sr.repo.GetAll(userId)
+func (sr *salesRepo) GetPage(userId int, page *Page) ([]dbModels.Sale, *PageInfo, error) {
+	return sr.repo.GetPage(userId, page)
 }
 
 func (sr *salesRepo) Create(sale *dbModels.Sale) (id int64, err error) {
@@ -44,13 +44,14 @@ func (sr *salesRepo) GetImageById(imgId int, userId int) (*dbModels.Image, error
 	return sr.repo.GetImageById(imgId, userId)
 }
 
-func (r *mysqlSalesRepo) GetAll(userId int) ([]dbModels.Sale, error) {
+func (r *mysqlSalesRepo) GetPage(userId int, page *Page) ([]dbModels.Sale, *PageInfo, error) {
 	dbSales := []dbModels.Sale{}
-	err := r.db.Select(&dbSales, "SELECT * FROM sales WHERE buyer_id=? OR seller_id=?", userId, userId)
+	info, err := selectPage(r.db, &dbSales, "sales", "(sales.buyer_id=? OR sales.seller_id=?)",
+		[]interface{}{userId, userId}, page)
 	if err != nil {
-		return nil, customErr.DB(err)
+		return nil, nil, err
 	}
-	return dbSales, nil
+	return dbSales, info, nil
 }
 
 func (r *mysqlSalesRepo) Create(sale *dbModels.Sale) (id int64, err error) {
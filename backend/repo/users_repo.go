@@ -1,20 +1,62 @@
 package repo
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+
 	dbModels "github.com/gasser707/go-gql-server/databases/models"
 	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
+//HandleHistoryRetention is how long an old handle keeps redirecting to its user and stays reserved for them
+const HandleHistoryRetention = 90 * 24 * time.Hour
+
+//MaxHandleHistory is how many old handles a user keeps, changing again frees the oldest one
+const MaxHandleHistory = 5
+
 type UsersRepoInterface interface {
 	GetById(id int) (*dbModels.User, error)
 	GetByEmail(email string) (*dbModels.User, error)
 	GetByUsername(username string) ([]dbModels.User, error)
-	GetAll() ([]dbModels.User, error)
+	GetByHandle(handle string) (*dbModels.User, error)
+	IsHandleTaken(handle string, userId int) (bool, error)
+	ChangeHandle(id int, handle string) error
+	GetPage(filter *UserFilter, page *Page) ([]dbModels.User, *PageInfo, error)
 	CountByEmail(email string) (int, error)
 	Create(insertedUser *dbModels.User) (int64, error)
 	Update(id int, updatedUser *dbModels.User) error
+	UpdatePrivacy(id int, showEmail bool, privateProfile bool) error
+}
+
+//UserFilter narrows down the users listed in a page
+type UserFilter struct {
+	ID       *int
+	Username *string
+	Email    *string
+}
+
+//Where compiles the filter to a where clause using ? bindvars
+func (f *UserFilter) Where() (string, []interface{}) {
+	//deleted accounts are only kept as tombstones for the sales of others
+	conds := []string{"users.deleted_at IS NULL"}
+	args := []interface{}{}
+	if f.ID != nil {
+		conds = append(conds, "users.id=?")
+		args = append(args, *f.ID)
+	}
+	if f.Username != nil {
+		conds = append(conds, "users.username=?")
+		args = append(args, *f.Username)
+	}
+	if f.Email != nil {
+		conds = append(conds, "users.email=?")
+		args = append(args, *f.Email)
+	}
+	return strings.Join(conds, " AND "), args
 }
 
 var _ UsersRepoInterface = &usersRepo{}
@@ -50,8 +92,20 @@ func (r *usersRepo) GetByUsername(username string) ([]dbModels.User, error) {
 	return r.repo.GetByUsername(username)
 }
 
-func (r *usersRepo) GetAll() ([]dbModels.User, error) {
-	return r.repo.GetAll()
+func (r *usersRepo) GetByHandle(handle string) (*dbModels.User, error) {
+	return r.repo.GetByHandle(handle)
+}
+
+func (r *usersRepo) IsHandleTaken(handle string, userId int) (bool, error) {
+	return r.repo.IsHandleTaken(handle, userId)
+}
+
+func (r *usersRepo) ChangeHandle(id int, handle string) error {
+	return r.repo.ChangeHandle(id, handle)
+}
+
+func (r *usersRepo) GetPage(filter *UserFilter, page *Page) ([]dbModels.User, *PageInfo, error) {
+	return r.repo.GetPage(filter, page)
 }
 
 func (r *usersRepo) CountByEmail(email string) (int, error) {
@@ -67,6 +121,10 @@ func (r *usersRepo) Update(id int, updatedUser *dbModels.User) error {
 	return r.repo.Update(id, updatedUser)
 }
 
+func (r *usersRepo) UpdatePrivacy(id int, showEmail bool, privateProfile bool) error {
+	return r.repo.UpdatePrivacy(id, showEmail, privateProfile)
+}
+
 func (r *mysqlUsersRepo) GetById(id int) (*dbModels.User, error) {
 	user := dbModels.User{}
 	err := r.db.Get(&user, "SELECT * FROM users WHERE id=?", id)
@@ -87,20 +145,108 @@ func (r *mysqlUsersRepo) GetByEmail(email string) (*dbModels.User, error) {
 
 func (r *mysqlUsersRepo) GetByUsername(username string) ([]dbModels.User, error) {
 	users := []dbModels.User{}
-	err := r.db.Get(&users, "SELECT * FROM users WHERE username=?", username)
+	err := r.db.Select(&users, "SELECT * FROM users WHERE username=? AND deleted_at IS NULL ORDER BY id", username)
 	if err != nil {
 		return nil, customErr.DB(err)
 	}
 	return users, nil
 }
 
-func (r *mysqlUsersRepo) GetAll() ([]dbModels.User, error) {
-	users := []dbModels.User{}
-	err := r.db.Select(&users, "SELECT * FROM users")
+//GetByHandle finds the user with the handle, or the user who had it before changing it in the retention period
+func (r *mysqlUsersRepo) GetByHandle(handle string) (*dbModels.User, error) {
+	user := dbModels.User{}
+	err := r.db.Get(&user, "SELECT * FROM users WHERE handle=? AND deleted_at IS NULL", handle)
+	if err == nil {
+		return &user, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, customErr.DB(err)
+	}
+	err = r.db.Get(&user, `SELECT users.* FROM users JOIN handle_history ON handle_history.user_id=users.id
+		WHERE handle_history.handle=? AND handle_history.created_at>? AND users.deleted_at IS NULL`, handle,
+		time.Now().Add(-HandleHistoryRetention))
 	if err != nil {
 		return nil, customErr.DB(err)
 	}
-	return users, nil
+	return &user, nil
+}
+
+//IsHandleTaken reports whether another user has the handle or had it in the retention period, old handles keep
+//redirecting
+func (r *mysqlUsersRepo) IsHandleTaken(handle string, userId int) (bool, error) {
+	c := 0
+	err := r.db.Get(&c, `SELECT (SELECT COUNT(*) FROM users WHERE handle=? AND id<>?) +
+		(SELECT COUNT(*) FROM handle_history WHERE handle=? AND user_id<>? AND created_at>?)`, handle, userId,
+		handle, userId, time.Now().Add(-HandleHistoryRetention))
+	if err != nil {
+		return false, customErr.DB(err)
+	}
+	return c != 0, nil
+}
+
+//ChangeHandle moves the current handle of the user to their history, a handle they had before is taken back from it.
+//Expired entries of the handles are dropped, and only the newest MaxHandleHistory old handles are kept.
+func (r *mysqlUsersRepo) ChangeHandle(id int, handle string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	var old *string
+	err = tx.Get(&old, "SELECT handle FROM users WHERE id=? FOR UPDATE", id)
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	now := time.Now()
+	_, err = tx.Exec("DELETE FROM handle_history WHERE handle=? AND (user_id=? OR created_at<=?)", handle, id,
+		now.Add(-HandleHistoryRetention))
+	if err != nil {
+		tx.Rollback()
+		return customErr.DB(err)
+	}
+	if old != nil {
+		//the current handle can only be in the history of someone who gave it up long ago
+		_, err = tx.Exec("DELETE FROM handle_history WHERE handle=?", *old)
+		if err != nil {
+			tx.Rollback()
+			return customErr.DB(err)
+		}
+		_, err = tx.Exec("INSERT INTO handle_history(user_id, handle, created_at) VALUES (?, ?, ?)", id, *old, now)
+		if err != nil {
+			tx.Rollback()
+			return customErr.DB(err)
+		}
+		_, err = tx.Exec(`DELETE FROM handle_history WHERE user_id=? AND id NOT IN (SELECT id FROM
+			(SELECT id FROM handle_history WHERE user_id=? ORDER BY created_at DESC, id DESC LIMIT ?) AS kept)`,
+			id, id, MaxHandleHistory)
+		if err != nil {
+			tx.Rollback()
+			return customErr.DB(err)
+		}
+	}
+	_, err = tx.Exec("UPDATE users SET handle=? WHERE id=?", handle, id)
+	if err != nil {
+		tx.Rollback()
+		return handleTakenErr(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+func (r *mysqlUsersRepo) GetPage(filter *UserFilter, page *Page) ([]dbModels.User, *PageInfo, error) {
+	if filter == nil {
+		filter = &UserFilter{}
+	}
+	where, args := filter.Where()
+	users := []dbModels.User{}
+	info, err := selectPage(r.db, &users, "users", where, args, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, info, nil
 }
 
 func (r *mysqlUsersRepo) CountByEmail(email string) (int, error) {
@@ -112,14 +258,30 @@ func (r *mysqlUsersRepo) CountByEmail(email string) (int, error) {
 	return c, nil
 }
 
+//Create inserts the user, a user without a handle gets user_<id>
 func (r *mysqlUsersRepo) Create(insertedUser *dbModels.User) (id int64, err error) {
-
-	result, err := r.db.NamedExec(`INSERT INTO users(email, password, username, bio, role, created_at) VALUES(
-		:email, :password, :username, :bio, :role, :created_at)`, insertedUser)
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return -1, customErr.DB(err)
 	}
+	result, err := tx.NamedExec(`INSERT INTO users(email, password, username, handle, bio, role, created_at) VALUES(
+		:email, :password, :username, :handle, :bio, :role, :created_at)`, insertedUser)
+	if err != nil {
+		tx.Rollback()
+		return -1, handleTakenErr(err)
+	}
 	userId, _ := result.LastInsertId()
+	if insertedUser.Handle == nil {
+		_, err = tx.Exec("UPDATE users SET handle=CONCAT('user_', id) WHERE id=?", userId)
+		if err != nil {
+			tx.Rollback()
+			return -1, customErr.DB(err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return -1, customErr.DB(err)
+	}
 	return userId, nil
 }
 
@@ -131,3 +293,19 @@ func (r *mysqlUsersRepo) Update(id int, updatedUser *dbModels.User) error {
 	}
 	return nil
 }
+
+func (r *mysqlUsersRepo) UpdatePrivacy(id int, showEmail bool, privateProfile bool) error {
+	_, err := r.db.Exec("UPDATE users SET show_email=?, private_profile=? WHERE id=?", showEmail, privateProfile, id)
+	if err != nil {
+		return customErr.DB(err)
+	}
+	return nil
+}
+
+//handleTakenErr tells the user when another request took the handle between the check and the write
+func handleTakenErr(err error) error {
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+		return customErr.BadRequest("this handle is taken")
+	}
+	return customErr.DB(err)
+}
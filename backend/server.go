@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -11,12 +13,14 @@ import (
 	"github.com/gasser707/go-gql-server/databases"
 	"github.com/gasser707/go-gql-server/graphql/dataloaders"
 	"github.com/gasser707/go-gql-server/graphql/generated"
+	"github.com/gasser707/go-gql-server/graphql/model"
 	"github.com/gasser707/go-gql-server/graphql/resolvers"
 	"github.com/gasser707/go-gql-server/helpers"
 	"github.com/gasser707/go-gql-server/middleware"
 	"github.com/gasser707/go-gql-server/services"
 	email_svc "github.com/gasser707/go-gql-server/services/email"
 	sales_svc "github.com/gasser707/go-gql-server/services/sale"
+	"github.com/gasser707/go-gql-server/utils/auth"
 	"github.com/gasser707/go-gql-server/utils/cloud"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -44,22 +48,66 @@ func graphqlHandler(mysqlDB *sqlx.DB, dl dataloaders.RetrieverInterface) gin.Han
 	emailSrv := email_svc.NewEmailService()
 	emailAdaptor := email_svc.NewEmailAdaptor(emailSrv)
 
-	authSrv := services.NewAuthService(mysqlDB, emailAdaptor)
-	userSrv := services.NewUsersService(mysqlDB, so, emailAdaptor)
+	store, limiter := services.NewAuthStore()
+	authSrv := services.NewAuthService(mysqlDB, emailAdaptor, store, limiter)
+	accountsSrv := services.NewAccountsService(mysqlDB, so, store, limiter)
+	go accountsSrv.RunPurge(ctx, time.Hour)
+	adminSrv := services.NewAdminService(mysqlDB, store)
+	followsSrv := services.NewFollowsService(mysqlDB)
+	userSrv := services.NewUsersService(mysqlDB, so, emailAdaptor, limiter)
 	imgSrv := services.NewImagesService(ctx, mysqlDB, so, emailAdaptor)
 	saleSrv := sales_svc.NewSalesService(mysqlDB)
 
 	c := generated.Config{Resolvers: &resolvers.Resolver{AuthService: authSrv,
-		ImagesService: imgSrv, UsersService: userSrv, SaleService: saleSrv, EmailService: emailSrv,
-		DataLoaders: dl,
+		ImagesService: imgSrv, UsersService: userSrv, AccountsService: accountsSrv, SaleService: saleSrv, EmailService: emailSrv,
+		AdminService: adminSrv, FollowsService: followsSrv, DataLoaders: dl,
 	}}
 
 	c.Directives.IsLoggedIn = func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
-		userId, _, err := authSrv.ValidateCredentials(ctx)
+		userId, role, err := authSrv.ValidateCredentials(ctx)
 		if err != nil {
 			return nil, err
 		}
 		newCtx := context.WithValue(ctx, helpers.UserIdKey, userId)
+		newCtx = context.WithValue(newCtx, helpers.UserRoleKey, role)
+		return next(newCtx)
+	}
+
+	c.Directives.HasRole = func(ctx context.Context, obj interface{}, next graphql.Resolver, roles []model.Role) (interface{}, error) {
+		userId, role, err := authSrv.ValidateCredentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		newCtx := context.WithValue(ctx, helpers.UserIdKey, userId)
+		newCtx = context.WithValue(newCtx, helpers.UserRoleKey, role)
+		err = services.RequireRole(newCtx, roles...)
+		if err != nil {
+			return nil, err
+		}
+		return next(newCtx)
+	}
+
+	c.Directives.HasScope = func(ctx context.Context, obj interface{}, next graphql.Resolver, scope string) (interface{}, error) {
+		userId, role, err := authSrv.ValidateCredentials(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+		newCtx := context.WithValue(ctx, helpers.UserIdKey, userId)
+		newCtx = context.WithValue(newCtx, helpers.UserRoleKey, role)
+		return next(newCtx)
+	}
+
+	c.Directives.OptionalAuth = func(ctx context.Context, obj interface{}, next graphql.Resolver, scope string) (interface{}, error) {
+		//credentials that are sent must be valid, so an expired session gets refreshed rather than browsing anonymously
+		if !authSrv.HasCredentials(ctx) {
+			return next(ctx)
+		}
+		userId, role, err := authSrv.ValidateCredentials(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+		newCtx := context.WithValue(ctx, helpers.UserIdKey, userId)
+		newCtx = context.WithValue(newCtx, helpers.UserRoleKey, role)
 		return next(newCtx)
 	}
 
@@ -80,10 +128,21 @@ func playgroundHandler() gin.HandlerFunc {
 	}
 }
 
+//Defining the JWKS handler, other services verify our access tokens with these public keys
+func jwksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, auth.Jwks())
+	}
+}
+
 func main() {
 
 	// Setting up Gin
 	r := gin.Default()
+	err := middleware.TrustProxies(r)
+	if err != nil {
+		log.Panic(err)
+	}
 
 	mysqlDB := databases.NewMysqlClient()
 	dl := dataloaders.NewRetriever()                         // <- here we initialize the dataloader.Retriever
@@ -95,7 +154,7 @@ func main() {
 		AllowOrigins:     []string{"*"},
 		AllowCredentials: true,
 		AllowMethods:     []string{"PUT", "PATCH", "POST"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Cookie", "Set-Cookie", "X-CSRF-TOKEN"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Cookie", "Set-Cookie", "X-CSRF-TOKEN", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Cookie", "Set-Cookie", "X-CSRF-TOKEN"},
 	}))
 
@@ -103,6 +162,7 @@ func main() {
 
 	r.POST("/query", graphqlHandler(mysqlDB, dl))
 	r.GET("/query/playground", playgroundHandler())
+	r.GET("/.well-known/jwks.json", jwksHandler())
 	r.Run()
 
 }
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/repo"
+	"github.com/gasser707/go-gql-server/utils/auth"
+	"github.com/gasser707/go-gql-server/utils/cloud"
+	"github.com/jmoiron/sqlx"
+)
+
+//accountDeletionGrace is how long a user has to change their mind before the account is purged
+const accountDeletionGrace = 14 * 24 * time.Hour
+
+type AccountsServiceInterface interface {
+	RequestAccountDeletion(ctx context.Context, password string) (*time.Time, error)
+	CancelAccountDeletion(ctx context.Context) (bool, error)
+	ExportMyData(ctx context.Context) (*model.DataExport, error)
+	PurgeDeletedAccounts() (int, error)
+}
+
+//accountsService implements the AccountsServiceInterface
+var _ AccountsServiceInterface = &accountsService{}
+
+type accountsService struct {
+	repo            repo.AccountsRepoInterface
+	storageOperator cloud.StorageOperatorInterface
+	rd              auth.AuthStoreOperatorInterface
+	limiter         auth.RateLimiterInterface
+}
+
+func NewAccountsService(db *sqlx.DB, storageOperator cloud.StorageOperatorInterface,
+	rd auth.AuthStoreOperatorInterface, limiter auth.RateLimiterInterface) *accountsService {
+	return &accountsService{repo: repo.NewAccountsRepo(db), storageOperator: storageOperator, rd: rd,
+		limiter: limiter}
+}
+
+//RequestAccountDeletion schedules the purge of the account, it returns when the purge will happen
+func (s *accountsService) RequestAccountDeletion(ctx context.Context, password string) (*time.Time, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = checkCurrentPassword(s.limiter, user, password)
+	if err != nil {
+		return nil, err
+	}
+	requestedAt := time.Now()
+	if user.DeletionRequestedAt != nil {
+		requestedAt = *user.DeletionRequestedAt
+	} else {
+		err = s.repo.SetDeletionRequestedAt(user.ID, &requestedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	purgeAt := requestedAt.Add(accountDeletionGrace)
+	return &purgeAt, nil
+}
+
+func (s *accountsService) CancelAccountDeletion(ctx context.Context) (bool, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+	if user.DeletionRequestedAt == nil {
+		return false, customErr.BadRequest("your account isn't scheduled for deletion")
+	}
+	err = s.repo.SetDeletionRequestedAt(user.ID, nil)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type exportedProfile struct {
+	ID          int        `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Bio         string     `json:"bio"`
+	Avatar      string     `json:"avatar"`
+	Role        string     `json:"role"`
+	Verified    bool       `json:"verified"`
+	TotpEnabled bool       `json:"twoFactorEnabled"`
+	Joined      time.Time  `json:"joined"`
+	DeletionAt  *time.Time `json:"deletionScheduledAt,omitempty"`
+}
+
+type exportedImage struct {
+	ID              int       `json:"id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	URL             string    `json:"url"`
+	Price           float64   `json:"price"`
+	ForSale         bool      `json:"forSale"`
+	Private         bool      `json:"private"`
+	Archived        bool      `json:"archived"`
+	DiscountPercent int       `json:"discountPercent"`
+	Labels          []string  `json:"labels"`
+	Created         time.Time `json:"created"`
+}
+
+type exportedSale struct {
+	ID       int       `json:"id"`
+	ImageID  int       `json:"imageId"`
+	BuyerID  int       `json:"buyerId"`
+	SellerID int       `json:"sellerId"`
+	Price    float64   `json:"price"`
+	Side     string    `json:"side"`
+	Time     time.Time `json:"time"`
+}
+
+//ExportMyData zips the profile, image metadata and sales of the user as JSON files
+func (s *accountsService) ExportMyData(ctx context.Context) (*model.DataExport, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	images, err := s.repo.GetUserImages(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.repo.GetUserLabels(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := s.repo.GetUserSales(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := exportedProfile{ID: user.ID, Username: user.Username, Email: user.Email, Bio: user.Bio,
+		Avatar: user.Avatar, Role: user.Role, Verified: user.Verfied, TotpEnabled: user.TotpEnabled,
+		Joined: user.CreatedAt}
+	if user.DeletionRequestedAt != nil {
+		deletionAt := user.DeletionRequestedAt.Add(accountDeletionGrace)
+		profile.DeletionAt = &deletionAt
+	}
+	imageLabels := map[int][]string{}
+	for _, label := range labels {
+		imageLabels[label.ImageID] = append(imageLabels[label.ImageID], label.Tag)
+	}
+	exportedImages := []exportedImage{}
+	for _, img := range images {
+		tags := imageLabels[img.ID]
+		if tags == nil {
+			tags = []string{}
+		}
+		exportedImages = append(exportedImages, exportedImage{ID: img.ID, Title: img.Title,
+			Description: img.Description, URL: img.URL, Price: img.Price, ForSale: img.ForSale,
+			Private: img.Private, Archived: img.Archived, DiscountPercent: img.DiscountPercent,
+			Labels: tags, Created: img.CreatedAt})
+	}
+	exportedSales := []exportedSale{}
+	for _, sale := range sales {
+		side := "buyer"
+		if sale.SellerID == user.ID {
+			side = "seller"
+		}
+		exportedSales = append(exportedSales, exportedSale{ID: sale.ID, ImageID: sale.ImageID,
+			BuyerID: sale.BuyerID, SellerID: sale.SellerID, Price: sale.Price, Side: side, Time: sale.CreatedAt})
+	}
+
+	archive, err := zipJson(map[string]interface{}{
+		"profile.json": profile,
+		"images.json":  exportedImages,
+		"sales.json":   exportedSales,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.DataExport{
+		FileName:    fmt.Sprintf("shotify-%d-%s.zip", user.ID, time.Now().Format("2006-01-02")),
+		ContentType: "application/zip",
+		Data:        base64.StdEncoding.EncodeToString(archive),
+	}, nil
+}
+
+func zipJson(files map[string]interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, name := range []string{"profile.json", "images.json", "sales.json"} {
+		f, err := w.Create(name)
+		if err != nil {
+			return nil, customErr.Internal(err.Error())
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(files[name])
+		if err != nil {
+			return nil, customErr.Internal(err.Error())
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+//PurgeDeletedAccounts purges the accounts whose grace period is over, it returns how many were purged.
+//An account that fails is logged and retried on the next run.
+func (s *accountsService) PurgeDeletedAccounts() (int, error) {
+	users, err := s.repo.GetUsersDueForPurge(time.Now().Add(-accountDeletionGrace))
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for i := range users {
+		err = s.purgeAccount(&users[i])
+		if err != nil {
+			log.Printf("couldn't purge account %d: %v\n", users[i].ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+//purgeAccount deletes the images and avatar of the user from the storage, drops their labels, logs them out
+//everywhere and anonymizes them. Sales rows are kept for the other side, they point to the anonymized user.
+//Deleting an object that is already gone succeeds, so a run that failed before its rows were purged is retried.
+func (s *accountsService) purgeAccount(user *dbModels.User) error {
+	images, err := s.repo.GetUserImages(user.ID)
+	if err != nil {
+		return err
+	}
+	for _, img := range images {
+		if img.URL != "" {
+			err = s.storageOperator.DeleteImage(img.URL)
+			if err != nil {
+				return err
+			}
+		}
+		err = s.repo.PurgeImage(img.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if user.Avatar != "" {
+		err = s.storageOperator.DeleteImage(user.Avatar)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.rd.DeleteAllUserTokens(fmt.Sprintf("%d", user.ID))
+	if err != nil {
+		return err
+	}
+	return s.repo.AnonymizeUser(user.ID)
+}
+
+//RunPurge purges the deleted accounts every interval until the context is done
+func (s *accountsService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeDeletedAccounts()
+			if err != nil {
+				log.Println("couldn't purge deleted accounts\n", err.Error())
+			} else if purged > 0 {
+				log.Printf("purged %d deleted accounts\n", purged)
+			}
+		}
+	}
+}
+
+func (s *accountsService) currentUser(ctx context.Context) (*dbModels.User, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	return s.repo.GetUserById(int(userId))
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	"github.com/gasser707/go-gql-server/helpers"
+	repoMocks "github.com/gasser707/go-gql-server/mocks/repo"
+	mocks "github.com/gasser707/go-gql-server/mocks/utils/auth"
+	cloudMocks "github.com/gasser707/go-gql-server/mocks/utils/cloud"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountsServiceTestSuite struct {
+	suite.Suite
+}
+
+func (suite *AccountsServiceTestSuite) userCtx(id int) context.Context {
+	return context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(id))
+}
+
+func (suite *AccountsServiceTestSuite) TestRequestAccountDeletion() {
+	mockRepo := repoMocks.AccountsRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+
+	mockRepo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Password: hash}, nil)
+	mockRepo.On("SetDeletionRequestedAt", 1, mock.AnythingOfType("*time.Time")).Return(nil)
+	mockLimiter.On("Take", passwordByUser, "1").Return(false, nil)
+	mockLimiter.On("Clear", passwordByUser, "1").Return(nil)
+
+	s := &accountsService{repo: &mockRepo, limiter: &mockLimiter}
+	purgeAt, err := s.RequestAccountDeletion(suite.userCtx(1), "secret")
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Nil(err)
+	suite.WithinDuration(time.Now().Add(accountDeletionGrace), *purgeAt, time.Minute)
+}
+
+func (suite *AccountsServiceTestSuite) TestRequestAccountDeletionTwiceKeepsTheFirstRequest() {
+	mockRepo := repoMocks.AccountsRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	requestedAt := time.Now().Add(-48 * time.Hour)
+
+	mockRepo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Password: hash, DeletionRequestedAt: &requestedAt}, nil)
+	mockLimiter.On("Take", passwordByUser, "1").Return(false, nil)
+	mockLimiter.On("Clear", passwordByUser, "1").Return(nil)
+
+	s := &accountsService{repo: &mockRepo, limiter: &mockLimiter}
+	purgeAt, err := s.RequestAccountDeletion(suite.userCtx(1), "secret")
+
+	mockRepo.AssertNotCalled(suite.T(), "SetDeletionRequestedAt", mock.Anything, mock.Anything)
+	suite.Nil(err)
+	suite.Equal(requestedAt.Add(accountDeletionGrace), *purgeAt)
+}
+
+func (suite *AccountsServiceTestSuite) TestRequestAccountDeletionWrongPassword() {
+	mockRepo := repoMocks.AccountsRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+
+	mockRepo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Password: hash}, nil)
+	mockLimiter.On("Take", passwordByUser, "1").Return(false, nil)
+
+	s := &accountsService{repo: &mockRepo, limiter: &mockLimiter}
+	purgeAt, err := s.RequestAccountDeletion(suite.userCtx(1), "wrong")
+
+	mockRepo.AssertNotCalled(suite.T(), "SetDeletionRequestedAt", mock.Anything, mock.Anything)
+	suite.NotNil(err)
+	suite.Nil(purgeAt)
+}
+
+func (suite *AccountsServiceTestSuite) TestCancelAccountDeletion() {
+	mockRepo := repoMocks.AccountsRepoInterface{}
+	requestedAt := time.Now()
+
+	mockRepo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, DeletionRequestedAt: &requestedAt}, nil).Once()
+	mockRepo.On("SetDeletionRequestedAt", 1, (*time.Time)(nil)).Return(nil)
+	s := &accountsService{repo: &mockRepo}
+	result, err := s.CancelAccountDeletion(suite.userCtx(1))
+	suite.Nil(err)
+	suite.True(result)
+
+	//nothing to cancel
+	mockRepo.On("GetUserById", 1).Return(&dbModels.User{ID: 1}, nil).Once()
+	result, err = s.CancelAccountDeletion(suite.userCtx(1))
+	suite.NotNil(err)
+	suite.False(result)
+	mockRepo.AssertNumberOfCalls(suite.T(), "SetDeletionRequestedAt", 1)
+}
+
+func (suite *AccountsServiceTestSuite) TestPurgeDeletedAccounts() {
+	mockRepo := repoMocks.AccountsRepoInterface{}
+	mockStorage := cloudMocks.StorageOperatorInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	requestedAt := time.Now().Add(-accountDeletionGrace - time.Hour)
+
+	mockRepo.On("GetUsersDueForPurge", mock.AnythingOfType("time.Time")).Return([]dbModels.User{
+		{ID: 1, Avatar: "avatars/1.png", DeletionRequestedAt: &requestedAt},
+		{ID: 2, DeletionRequestedAt: &requestedAt},
+	}, nil)
+	mockRepo.On("GetUserImages", 1).Return([]dbModels.Image{{ID: 10, URL: "1/a.png"}, {ID: 11, URL: "1/b.png"}}, nil)
+	mockRepo.On("GetUserImages", 2).Return([]dbModels.Image{{ID: 20, URL: "2/a.png"}}, nil)
+	mockStorage.On("DeleteImage", "1/a.png").Return(nil)
+	mockStorage.On("DeleteImage", "1/b.png").Return(nil)
+	mockStorage.On("DeleteImage", "avatars/1.png").Return(nil)
+	//the second account fails and is left for the next run
+	mockStorage.On("DeleteImage", "2/a.png").Return(errors.New("storage is down"))
+	mockRepo.On("PurgeImage", 10).Return(nil)
+	mockRepo.On("PurgeImage", 11).Return(nil)
+	mockStore.On("DeleteAllUserTokens", "1").Return(nil)
+	mockRepo.On("AnonymizeUser", 1).Return(nil)
+
+	s := &accountsService{repo: &mockRepo, storageOperator: &mockStorage, rd: &mockStore}
+	purged, err := s.PurgeDeletedAccounts()
+
+	mockRepo.AssertExpectations(suite.T())
+	mockStorage.AssertExpectations(suite.T())
+	mockStore.AssertExpectations(suite.T())
+	mockRepo.AssertNotCalled(suite.T(), "PurgeImage", 20)
+	mockRepo.AssertNotCalled(suite.T(), "AnonymizeUser", 2)
+	suite.Nil(err)
+	suite.Equal(1, purged)
+}
+
+func (suite *AccountsServiceTestSuite) TestExportMyData() {
+	mockRepo := repoMocks.AccountsRepoInterface{}
+
+	mockRepo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com",
+		Password: "hash", TotpSecret: "ABC"}, nil)
+	mockRepo.On("GetUserImages", 1).Return([]dbModels.Image{{ID: 10, Title: "sunset", UserID: 1}}, nil)
+	mockRepo.On("GetUserLabels", 1).Return([]dbModels.Label{{ID: 1, Tag: "sky", ImageID: 10}}, nil)
+	mockRepo.On("GetUserSales", 1).Return([]dbModels.Sale{
+		{ID: 1, ImageID: 10, BuyerID: 2, SellerID: 1, Price: 5},
+		{ID: 2, ImageID: 30, BuyerID: 1, SellerID: 3, Price: 7},
+	}, nil)
+
+	s := &accountsService{repo: &mockRepo}
+	export, err := s.ExportMyData(suite.userCtx(1))
+	suite.Nil(err)
+	suite.Equal("application/zip", export.ContentType)
+
+	data, err := base64.StdEncoding.DecodeString(export.Data)
+	suite.Nil(err)
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	suite.Nil(err)
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		suite.Nil(err)
+		files[f.Name], err = ioutil.ReadAll(r)
+		suite.Nil(err)
+		r.Close()
+	}
+	suite.Len(files, 3)
+
+	profile := map[string]interface{}{}
+	suite.Nil(json.Unmarshal(files["profile.json"], &profile))
+	suite.Equal("foo@bar.com", profile["email"])
+	//secrets never leave the server
+	suite.NotContains(string(files["profile.json"]), "hash")
+	suite.NotContains(string(files["profile.json"]), "ABC")
+
+	images := []exportedImage{}
+	suite.Nil(json.Unmarshal(files["images.json"], &images))
+	suite.Equal([]string{"sky"}, images[0].Labels)
+
+	sales := []exportedSale{}
+	suite.Nil(json.Unmarshal(files["sales.json"], &sales))
+	suite.Equal("seller", sales[0].Side)
+	suite.Equal("buyer", sales[1].Side)
+}
+
+func TestAccountsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountsServiceTestSuite))
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/repo"
+	"github.com/gasser707/go-gql-server/utils/auth"
+	"github.com/jmoiron/sqlx"
+)
+
+const maxSuspensionReason = 300
+
+type AdminServiceInterface interface {
+	GetUsers(ctx context.Context, filter *model.AdminUserFilterInput, first *int, after *string, last *int,
+		before *string) (*model.AdminUserConnection, error)
+	SetUserRole(ctx context.Context, userId string, role model.Role) (*model.AdminUser, error)
+	SuspendUser(ctx context.Context, userId string, reason string, until time.Time) (*model.AdminUser, error)
+	BanUser(ctx context.Context, userId string, reason string) (*model.AdminUser, error)
+	UnsuspendUser(ctx context.Context, userId string) (*model.AdminUser, error)
+	ForceLogout(ctx context.Context, userId string) (bool, error)
+}
+
+//adminService implements the AdminServiceInterface
+var _ AdminServiceInterface = &adminService{}
+
+type adminService struct {
+	repo repo.AdminRepoInterface
+	rd   auth.AuthStoreOperatorInterface
+}
+
+func NewAdminService(db *sqlx.DB, rd auth.AuthStoreOperatorInterface) *adminService {
+	return &adminService{repo: repo.NewAdminRepo(db), rd: rd}
+}
+
+//suspendedAt reports whether the account is suspended or banned at the given time
+func suspendedAt(user *dbModels.User, now time.Time) bool {
+	return user.SuspendedAt != nil && (user.SuspendedUntil == nil || user.SuspendedUntil.After(now))
+}
+
+//checkNotSuspended keeps suspended and banned users from logging in or using their sessions
+func checkNotSuspended(user *dbModels.User, now time.Time) error {
+	if !suspendedAt(user, now) {
+		return nil
+	}
+	if user.SuspendedUntil == nil {
+		return customErr.Suspended("your account is banned: "+user.SuspensionReason, nil)
+	}
+	return customErr.Suspended(fmt.Sprintf("your account is suspended until %s: %s",
+		user.SuspendedUntil.UTC().Format(time.RFC1123), user.SuspensionReason), user.SuspendedUntil)
+}
+
+func (s *adminService) GetUsers(ctx context.Context, input *model.AdminUserFilterInput, first *int, after *string,
+	last *int, before *string) (*model.AdminUserConnection, error) {
+	page, err := repo.NewPage(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	filter := &repo.AdminUserFilter{Now: now}
+	if input != nil {
+		if input.Role != nil {
+			role := input.Role.String()
+			filter.Role = &role
+		}
+		filter.Email = input.Email
+		filter.Verified = input.Verified
+		filter.Suspended = input.Suspended
+	}
+
+	users, info, err := s.repo.GetUsersPage(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	edges := []*model.AdminUserEdge{}
+	cursors := []string{}
+	for i := range users {
+		cursor := repo.EncodeCursor(users[i].CreatedAt, users[i].ID)
+		edges = append(edges, &model.AdminUserEdge{Cursor: cursor, Node: toAdminUser(&users[i], now)})
+		cursors = append(cursors, cursor)
+	}
+	return &model.AdminUserConnection{
+		Edges:      edges,
+		PageInfo:   NewPageInfo(info, cursors),
+		TotalCount: info.TotalCount,
+	}, nil
+}
+
+//SetUserRole changes the role of another user, their sessions end so they log in again with the new role
+func (s *adminService) SetUserRole(ctx context.Context, userId string, role model.Role) (*model.AdminUser, error) {
+	if !role.IsValid() {
+		return nil, customErr.BadRequest(fmt.Sprintf("%s is not a role", role))
+	}
+	user, err := s.otherUser(ctx, userId, "change your own role")
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role.String() {
+		return toAdminUser(user, time.Now()), nil
+	}
+	err = s.repo.UpdateRole(user.ID, role.String())
+	if err != nil {
+		return nil, err
+	}
+	err = s.endSessions(ctx, user, RoleChangedEvent, fmt.Sprintf("%s to %s", user.Role, role))
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role.String()
+	return toAdminUser(user, time.Now()), nil
+}
+
+//SuspendUser keeps the user out until the given time
+func (s *adminService) SuspendUser(ctx context.Context, userId string, reason string,
+	until time.Time) (*model.AdminUser, error) {
+	if !until.After(time.Now()) {
+		return nil, customErr.BadRequest("a suspension has to end in the future")
+	}
+	return s.suspend(ctx, userId, reason, &until)
+}
+
+//BanUser keeps the user out until an admin lifts the ban
+func (s *adminService) BanUser(ctx context.Context, userId string, reason string) (*model.AdminUser, error) {
+	return s.suspend(ctx, userId, reason, nil)
+}
+
+func (s *adminService) UnsuspendUser(ctx context.Context, userId string) (*model.AdminUser, error) {
+	user, err := s.otherUser(ctx, userId, "lift your own suspension")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !suspendedAt(user, now) {
+		return nil, customErr.BadRequest("this user isn't suspended")
+	}
+	err = s.repo.UpdateSuspension(user.ID, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	err = s.recordEvent(ctx, user, UnsuspendedEvent, "")
+	if err != nil {
+		return nil, err
+	}
+	user.SuspendedAt, user.SuspendedUntil, user.SuspensionReason = nil, nil, ""
+	return toAdminUser(user, now), nil
+}
+
+//ForceLogout ends every session of the user, it doesn't revoke their personal access tokens
+func (s *adminService) ForceLogout(ctx context.Context, userId string) (bool, error) {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return false, err
+	}
+	err = s.endSessions(ctx, user, ForcedLogoutEvent, "")
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *adminService) suspend(ctx context.Context, userId string, reason string,
+	until *time.Time) (*model.AdminUser, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) < 1 || len(reason) > maxSuspensionReason {
+		return nil, customErr.BadRequest(fmt.Sprintf("the reason must be between 1 and %d characters",
+			maxSuspensionReason))
+	}
+	user, err := s.otherUser(ctx, userId, "suspend yourself")
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == model.RoleAdmin.String() {
+		return nil, customErr.Forbidden("admins can't be suspended, change their role first")
+	}
+
+	now := time.Now()
+	err = s.repo.UpdateSuspension(user.ID, &now, until, reason)
+	if err != nil {
+		return nil, err
+	}
+	details := "banned: " + reason
+	if until != nil {
+		details = fmt.Sprintf("until %s: %s", until.UTC().Format(time.RFC3339), reason)
+	}
+	err = s.endSessions(ctx, user, SuspendedEvent, details)
+	if err != nil {
+		return nil, err
+	}
+	user.SuspendedAt, user.SuspendedUntil, user.SuspensionReason = &now, until, reason
+	return toAdminUser(user, now), nil
+}
+
+//endSessions logs the user out everywhere and records why
+func (s *adminService) endSessions(ctx context.Context, user *dbModels.User, kind string, details string) error {
+	err := s.rd.DeleteAllUserTokens(strconv.Itoa(user.ID))
+	if err != nil {
+		return err
+	}
+	return s.recordEvent(ctx, user, kind, details)
+}
+
+//recordEvent adds the action to the security events of the user along with the admin who took it
+func (s *adminService) recordEvent(ctx context.Context, user *dbModels.User, kind string, details string) error {
+	adminId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return customErr.Internal("userId not found in ctx")
+	}
+	details = strings.TrimSpace(fmt.Sprintf("by admin %d %s", adminId, details))
+	return s.repo.CreateSecurityEvent(newSecurityEvent(ctx, user.ID, kind, details))
+}
+
+//otherUser fetches the user an admin acts on, the action describes what admins can't do to themselves
+func (s *adminService) otherUser(ctx context.Context, userId string, action string) (*dbModels.User, error) {
+	adminId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if IntUserID(user.ID) == adminId {
+		return nil, customErr.BadRequest("you can't " + action)
+	}
+	return user, nil
+}
+
+func (s *adminService) getUser(userId string) (*dbModels.User, error) {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, customErr.BadRequest(err.Error())
+	}
+	return s.repo.GetUserById(id)
+}
+
+func toAdminUser(user *dbModels.User, now time.Time) *model.AdminUser {
+	adminUser := &model.AdminUser{
+		User:             toUser(user),
+		Verified:         user.Verfied,
+		TwoFactorEnabled: user.TotpEnabled,
+	}
+	if suspendedAt(user, now) {
+		adminUser.Suspension = &model.Suspension{
+			Reason: user.SuspensionReason,
+			Since:  *user.SuspendedAt,
+			Until:  user.SuspendedUntil,
+		}
+	}
+	return adminUser
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/repo"
+	repoMocks "github.com/gasser707/go-gql-server/mocks/repo"
+	mocks "github.com/gasser707/go-gql-server/mocks/utils/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminServiceTestSuite struct {
+	suite.Suite
+	repo    *repoMocks.AdminRepoInterface
+	rd      *mocks.AuthStoreOperatorInterface
+	service *adminService
+	ctx     context.Context
+}
+
+func (suite *AdminServiceTestSuite) SetupTest() {
+	suite.repo = &repoMocks.AdminRepoInterface{}
+	suite.rd = &mocks.AuthStoreOperatorInterface{}
+	suite.service = &adminService{repo: suite.repo, rd: suite.rd}
+	suite.ctx = context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+}
+
+func (suite *AdminServiceTestSuite) securityEvent(kind string) interface{} {
+	return mock.MatchedBy(func(event *dbModels.SecurityEvent) bool {
+		return event.UserID == 2 && event.Kind == kind && strings.HasPrefix(event.Details, "by admin 1")
+	})
+}
+
+func (suite *AdminServiceTestSuite) TestSuspendUser() {
+	until := time.Now().Add(24 * time.Hour)
+	suite.repo.On("GetUserById", 2).Return(&dbModels.User{ID: 2, Role: "USER"}, nil)
+	suite.repo.On("UpdateSuspension", 2, mock.AnythingOfType("*time.Time"), &until, "spam").Return(nil)
+	suite.rd.On("DeleteAllUserTokens", "2").Return(nil)
+	suite.repo.On("CreateSecurityEvent", suite.securityEvent(SuspendedEvent)).Return(nil)
+
+	user, err := suite.service.SuspendUser(suite.ctx, "2", " spam ", until)
+
+	suite.Nil(err)
+	suite.Equal("spam", user.Suspension.Reason)
+	suite.Equal(&until, user.Suspension.Until)
+	suite.repo.AssertExpectations(suite.T())
+	suite.rd.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceTestSuite) TestBanUser() {
+	suite.repo.On("GetUserById", 2).Return(&dbModels.User{ID: 2, Role: "MODERATOR"}, nil)
+	suite.repo.On("UpdateSuspension", 2, mock.AnythingOfType("*time.Time"), (*time.Time)(nil), "fraud").Return(nil)
+	suite.rd.On("DeleteAllUserTokens", "2").Return(nil)
+	suite.repo.On("CreateSecurityEvent", suite.securityEvent(SuspendedEvent)).Return(nil)
+
+	user, err := suite.service.BanUser(suite.ctx, "2", "fraud")
+
+	suite.Nil(err)
+	suite.NotNil(user.Suspension)
+	suite.Nil(user.Suspension.Until)
+	suite.repo.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceTestSuite) TestSuspendRejectsBadRequests() {
+	suite.repo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Role: "ADMIN"}, nil)
+	suite.repo.On("GetUserById", 3).Return(&dbModels.User{ID: 3, Role: "ADMIN"}, nil)
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	_, err := suite.service.SuspendUser(suite.ctx, "2", "spam", time.Now().Add(-time.Hour))
+	suite.NotNil(err)
+	_, err = suite.service.SuspendUser(suite.ctx, "2", "  ", tomorrow)
+	suite.NotNil(err)
+	_, err = suite.service.BanUser(suite.ctx, "1", "spam")
+	suite.NotNil(err)
+	//admins have to be demoted first
+	_, err = suite.service.BanUser(suite.ctx, "3", "spam")
+	suite.NotNil(err)
+	suite.repo.AssertNotCalled(suite.T(), "UpdateSuspension", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything)
+	suite.rd.AssertNotCalled(suite.T(), "DeleteAllUserTokens", mock.Anything)
+}
+
+func (suite *AdminServiceTestSuite) TestUnsuspendUser() {
+	since, ended := time.Now().Add(-48*time.Hour), time.Now().Add(-time.Hour)
+	suite.repo.On("GetUserById", 2).Return(&dbModels.User{ID: 2, Role: "USER", SuspendedAt: &since,
+		SuspensionReason: "spam"}, nil)
+	suite.repo.On("GetUserById", 3).Return(&dbModels.User{ID: 3, Role: "USER", SuspendedAt: &since,
+		SuspendedUntil: &ended}, nil)
+	suite.repo.On("UpdateSuspension", 2, (*time.Time)(nil), (*time.Time)(nil), "").Return(nil)
+	suite.repo.On("CreateSecurityEvent", suite.securityEvent(UnsuspendedEvent)).Return(nil)
+
+	user, err := suite.service.UnsuspendUser(suite.ctx, "2")
+	suite.Nil(err)
+	suite.Nil(user.Suspension)
+	//a suspension that ended is already lifted
+	_, err = suite.service.UnsuspendUser(suite.ctx, "3")
+	suite.NotNil(err)
+	suite.repo.AssertNumberOfCalls(suite.T(), "UpdateSuspension", 1)
+}
+
+func (suite *AdminServiceTestSuite) TestSetUserRole() {
+	suite.repo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Role: "ADMIN"}, nil)
+	suite.repo.On("GetUserById", 2).Return(&dbModels.User{ID: 2, Role: "USER"}, nil)
+	suite.repo.On("UpdateRole", 2, "MODERATOR").Return(nil)
+	suite.rd.On("DeleteAllUserTokens", "2").Return(nil)
+	suite.repo.On("CreateSecurityEvent", suite.securityEvent(RoleChangedEvent)).Return(nil)
+
+	user, err := suite.service.SetUserRole(suite.ctx, "2", model.RoleModerator)
+	suite.Nil(err)
+	suite.Equal("MODERATOR", string(user.User.Role))
+
+	_, err = suite.service.SetUserRole(suite.ctx, "1", model.RoleUser)
+	suite.NotNil(err)
+	suite.repo.AssertNumberOfCalls(suite.T(), "UpdateRole", 1)
+}
+
+func (suite *AdminServiceTestSuite) TestForceLogout() {
+	suite.repo.On("GetUserById", 2).Return(&dbModels.User{ID: 2, Role: "USER"}, nil)
+	suite.rd.On("DeleteAllUserTokens", "2").Return(nil)
+	suite.repo.On("CreateSecurityEvent", suite.securityEvent(ForcedLogoutEvent)).Return(nil)
+
+	ok, err := suite.service.ForceLogout(suite.ctx, "2")
+
+	suite.Nil(err)
+	suite.True(ok)
+	suite.rd.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceTestSuite) TestGetUsers() {
+	since := time.Now().Add(-time.Hour)
+	role, suspended := model.RoleUser, true
+	suite.repo.On("GetUsersPage", mock.MatchedBy(func(filter *repo.AdminUserFilter) bool {
+		return *filter.Role == "USER" && *filter.Suspended && filter.Verified == nil && !filter.Now.IsZero()
+	}), mock.Anything).Return([]dbModels.User{{ID: 2, Role: "USER", CreatedAt: since, SuspendedAt: &since,
+		SuspensionReason: "spam"}}, &repo.PageInfo{TotalCount: 1}, nil)
+
+	connection, err := suite.service.GetUsers(suite.ctx, &model.AdminUserFilterInput{Role: &role,
+		Suspended: &suspended}, nil, nil, nil, nil)
+
+	suite.Nil(err)
+	suite.Equal(1, connection.TotalCount)
+	suite.Equal("2", connection.Edges[0].Node.User.ID)
+	suite.Equal("spam", connection.Edges[0].Node.Suspension.Reason)
+	suite.Equal(connection.Edges[0].Cursor, *connection.PageInfo.StartCursor)
+}
+
+func TestAdminServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminServiceTestSuite))
+}
@@ -3,9 +3,17 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
+	"net/mail"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/gasser707/go-gql-server/databases"
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
 	customErr "github.com/gasser707/go-gql-server/errors"
 	"github.com/gasser707/go-gql-server/graphql/model"
 	"github.com/gasser707/go-gql-server/helpers"
@@ -16,21 +24,52 @@ import (
 	"github.com/gorilla/securecookie"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/matoous/go-nanoid/v2"
 )
 
 var (
 	domain = os.Getenv("DOMAIN_NAME")
 )
 
+const (
+	totpIssuer        = "Shotify"
+	recoveryCodeCount = 10
+)
+
 type AuthServiceInterface interface {
-	Login(ctx context.Context, input model.LoginInput) (bool, error)
-	ValidateCredentials(c context.Context) (IntUserID, model.Role, error)
+	Login(ctx context.Context, input model.LoginInput) (*model.LoginResult, error)
+	ValidateCredentials(c context.Context, scopes ...string) (IntUserID, model.Role, error)
+	HasCredentials(ctx context.Context) bool
 	Logout(ctx context.Context) (bool, error)
 	RefreshCredentials(ctx context.Context) (bool, error)
-	RequestPasswordReset(ctx context.Context, email string) (bool, error)
+	RequestPasswordReset(ctx context.Context, email string, captchaToken *string) (bool, error)
+	ResendVerificationEmail(ctx context.Context, email string) (bool, error)
 	ProcessPasswordReset(ctx context.Context, resetToken string, newPass string) (bool, error)
 	ValidateUser(ctx context.Context, validationToken string) (bool, error)
+	UnlockAccount(ctx context.Context, unlockToken string) (bool, error)
 	LogoutAll(ctx context.Context) (bool, error)
+	GetSessions(ctx context.Context) ([]*model.Session, error)
+	RevokeSession(ctx context.Context, sessionId string) (bool, error)
+	EnableTwoFactor(ctx context.Context) (*model.TwoFactorSetup, error)
+	ConfirmTwoFactor(ctx context.Context, code string) ([]string, error)
+	VerifyTwoFactor(ctx context.Context, challengeToken string, code string) (bool, error)
+	DisableTwoFactor(ctx context.Context, code string) (bool, error)
+	CreateAccessToken(ctx context.Context, input model.NewAccessTokenInput) (*model.NewAccessToken, error)
+	GetAccessTokens(ctx context.Context) ([]*model.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, id string) (bool, error)
+	ChangePassword(ctx context.Context, currentPassword string, newPassword string) (bool, error)
+	RequestEmailChange(ctx context.Context, newEmail string, password string) (bool, error)
+	ConfirmEmailChange(ctx context.Context, token string) (bool, error)
+	RequestMagicLink(ctx context.Context, email string) (bool, error)
+	ConsumeMagicLink(ctx context.Context, token string) (*model.LoginResult, error)
+	BeginPasskeyRegistration(ctx context.Context) (*model.PasskeyChallenge, error)
+	FinishPasskeyRegistration(ctx context.Context, challengeId string, name string, credential string) (*model.Passkey, error)
+	BeginPasskeyLogin(ctx context.Context, email string) (*model.PasskeyChallenge, error)
+	FinishPasskeyLogin(ctx context.Context, challengeId string, credential string) (*model.LoginResult, error)
+	GetPasskeys(ctx context.Context) ([]*model.Passkey, error)
+	DeletePasskey(ctx context.Context, id string) (bool, error)
+	GetLoginHistory(ctx context.Context, limit *int) ([]*model.LoginEvent, error)
+	ReportUnrecognizedLogin(ctx context.Context, token string) (bool, error)
 }
 
 //authService implements the AuthServiceInterface
@@ -41,58 +80,222 @@ type authService struct {
 	tk           auth.TokenOperatorInterface
 	sc           *securecookie.SecureCookie
 	repo         repo.AuthRepoInterface
+	tokensRepo   repo.AccessTokensRepoInterface
+	limiter      auth.RateLimiterInterface
 	emailAdaptor email_svc.EmailAdaptorInterface
+	passwords    *auth.PasswordPolicy
+	passkeys     repo.PasskeysRepoInterface
+	webauthn     *webauthn.WebAuthn
+	human        *auth.HumanCheck
 }
 
-func NewAuthService(db *sqlx.DB, emailAdaptor email_svc.EmailAdaptorInterface) *authService {
+func NewAuthService(db *sqlx.DB, emailAdaptor email_svc.EmailAdaptorInterface, rd auth.AuthStoreOperatorInterface,
+	limiter auth.RateLimiterInterface) *authService {
 	sc := helpers.NewSecureCookie()
 	tk := auth.NewTokenOperator(sc)
-	rd := auth.NewRedisStore()
 	authRepo := repo.NewAuthRepo(db)
-	return &authService{rd, tk, sc, authRepo, emailAdaptor}
+	tokensRepo := repo.NewAccessTokensRepo(db)
+	passkeysRepo := repo.NewPasskeysRepo(db)
+	return &authService{rd, tk, sc, authRepo, tokensRepo, limiter, emailAdaptor, auth.DefaultPasswordPolicy(),
+		passkeysRepo, NewWebAuthn(), auth.DefaultHumanCheck()}
+}
+
+//NewAuthStore picks where sessions, one time tokens and rate limits are kept from AUTH_STORE, "memory" runs
+//without redis but doesn't survive restarts or work across several instances
+func NewAuthStore() (auth.AuthStoreOperatorInterface, auth.RateLimiterInterface) {
+	if os.Getenv("AUTH_STORE") == "memory" {
+		return auth.NewMemoryStore(), auth.NewMemoryRateLimiter()
+	}
+	redisClient := databases.NewRedisClient()
+	return auth.NewRedisStore(redisClient), auth.NewRedisRateLimiter(redisClient)
 }
 
 type UserID string
 type IntUserID int64
 
-func (s *authService) Login(ctx context.Context, input model.LoginInput) (bool, error) {
+func (s *authService) Login(ctx context.Context, input model.LoginInput) (*model.LoginResult, error) {
+	//every attempt counts up front, a right password clears the attempts of the email and takes back the
+	//one of the ip, so the users behind one NAT only share their failures
+	_, err := s.limiter.Take(loginByIp, clientIp(ctx))
+	if err != nil {
+		return nil, err
+	}
+	locked, err := s.limiter.Take(loginByEmail, emailSubject(input.Email))
+	if err != nil {
+		return nil, err
+	}
 
 	user, err := s.repo.GetUserByEmail(input.Email)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	ok := helpers.CheckPasswordHash(input.Password, user.Password)
 	if !ok {
-		return false, customErr.NoAuth("this combination of email password is wrong")
+		s.recordLogin(ctx, user, model.LoginMethodPassword, false)
+		if locked {
+			if lockErr := s.sendAccountLocked(user); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, customErr.NoAuth("this combination of email password is wrong")
 	}
+	err = s.limiter.Clear(loginByEmail, emailSubject(input.Email))
+	if err != nil {
+		return nil, err
+	}
+	err = s.limiter.Refund(loginByIp, clientIp(ctx))
+	if err != nil {
+		return nil, err
+	}
+	s.upgradePasswordHash(user, input.Password)
+	//only the owner of the password learns the account is unverified
+	if !user.Verfied {
+		return nil, customErr.Unverified("your account is unverified, follow the link we emailed you " +
+			"or ask for a new one with resendVerificationEmail")
+	}
+
+	return s.completeLogin(ctx, user, model.LoginMethodPassword)
+}
+
+//upgradePasswordHash replaces a bcrypt hash, or an argon2id hash made with older parameters, while the password
+//is known. A failure only means it is tried again on the next login.
+func (s *authService) upgradePasswordHash(user *dbModels.User, password string) {
+	if !helpers.PasswordNeedsRehash(user.Password) {
+		return
+	}
+	hash, err := helpers.HashPassword(password)
+	if err == nil {
+		err = s.repo.UpdatePassword(fmt.Sprintf("%d", user.ID), hash)
+	}
+	if err != nil {
+		log.Printf("couldn't upgrade the password hash of user %d: %v\n", user.ID, err)
+		return
+	}
+	user.Password = hash
+}
 
+//completeLogin logs in a user who proved who they are, or hands out the two factor challenge if they use 2FA
+func (s *authService) completeLogin(ctx context.Context, user *dbModels.User,
+	method model.LoginMethod) (*model.LoginResult, error) {
 	id := fmt.Sprintf("%v", user.ID)
 	role := fmt.Sprintf("%v", user.Role)
+	err := checkNotSuspended(user, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-	ts, err := s.tk.CreateTokens(id, model.Role(role))
+	//users with 2FA get a short lived challenge to exchange with a code in verifyTwoFactor
+	if user.TotpEnabled {
+		challenge, err := s.tk.CreateStatelessToken(id, auth.TwoFactorToken)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResult{LoggedIn: false, TwoFactorRequired: true, ChallengeToken: &challenge}, nil
+	}
+
+	err = s.issueCredentials(ctx, id, model.Role(role))
+	if err != nil {
+		return nil, err
+	}
+	s.recordLogin(ctx, user, method, true)
+	return &model.LoginResult{LoggedIn: true, TwoFactorRequired: false}, nil
+}
+
+//RequestMagicLink emails a single use login link, it answers the same whether the email belongs to an account or not
+func (s *authService) RequestMagicLink(ctx context.Context, email string) (bool, error) {
+	for _, limit := range []struct {
+		policy  auth.Policy
+		subject string
+	}{{magicLinkByIp, clientIp(ctx)}, {magicLinkByEmail, emailSubject(email)}} {
+		_, err := s.limiter.Take(limit.policy, limit.subject)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		return true, nil
+	}
+	token, err := s.tk.CreateStatelessToken(fmt.Sprintf("%d", user.ID), auth.MagicLinkToken)
 	if err != nil {
 		return false, err
 	}
-	saveErr := s.rd.CreateAuthTokens(id, ts)
+	go s.emailAdaptor.SendMagicLinkEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("magic-login", token))
+	return true, nil
+}
+
+//ConsumeMagicLink logs in the user the link was emailed to, the link can only be used once. Following it proves
+//the user owns the address, so it verifies unverified accounts too.
+func (s *authService) ConsumeMagicLink(ctx context.Context, token string) (*model.LoginResult, error) {
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, token, auth.MagicLinkToken)
+	if err != nil {
+		return nil, err
+	}
+	err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetUserById(details.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Verfied {
+		err = s.repo.UpdateVerified(details.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.completeLogin(ctx, user, model.LoginMethodMagicLink)
+}
+
+//issueCredentials starts a new session for the user and sets its tokens on the response
+func (s *authService) issueCredentials(ctx context.Context, userId string, role model.Role) error {
+	sessionId, err := gonanoid.New()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return s.issueTokens(ctx, userId, role, sessionId)
+}
+
+//issueTokens creates the tokens of the session, stores them and sends them back in the cookie and csrf header
+func (s *authService) issueTokens(ctx context.Context, userId string, role model.Role, sessionId string) error {
+	ts, err := s.tk.CreateTokens(userId, role, sessionId)
+	if err != nil {
+		return err
+	}
+	saveErr := s.rd.CreateAuthTokens(userId, ts)
 	if saveErr != nil {
-		return false, err
+		return saveErr
+	}
+	err = s.saveSession(ctx, userId, ts)
+	if err != nil {
+		return err
 	}
 
 	ca, err := middleware.GetCookieAccess(ctx)
 	if err != nil {
-		return false, err
+		return err
 	}
 	ca.SetCookie(ts.AccessToken, ts.RefreshToken, s.sc)
 	ha, err := middleware.GetHeaderAccess(ctx)
 	if err != nil {
-		return false, err
+		return err
 	}
 	ha.SetCsrfToken(ts.CsrfToken)
-	return true, nil
+	return nil
 }
 
-func (s *authService) ValidateCredentials(ctx context.Context) (IntUserID, model.Role, error) {
+//ValidateCredentials resolves the user of the request from a personal access token sent as a bearer token,
+//otherwise from the session cookie and csrf token. Access tokens are only accepted if they hold all the scopes.
+func (s *authService) ValidateCredentials(ctx context.Context, scopes ...string) (IntUserID, model.Role, error) {
+	ha, err := middleware.GetHeaderAccess(ctx)
+	if err == nil && ha.BearerToken != "" {
+		return s.validateAccessToken(ha.BearerToken, scopes)
+	}
+
 	metadata, err := s.tk.ExtractAccessTokenMetadata(ctx)
 	if err != nil {
 		return -1, "", err
@@ -102,12 +305,24 @@ func (s *authService) ValidateCredentials(ctx context.Context) (IntUserID, model
 		return -1, "", err
 	}
 
-	id, err := strconv.Atoi(userId)
+	if metadata.SessionId != "" {
+		err = s.rd.TouchSession(userId, metadata.SessionId)
+		if err != nil {
+			return -1, "", err
+		}
+	}
+
+	//the user is looked up so suspensions and role changes apply to sessions right away
+	user, err := s.repo.GetUserById(userId)
+	if err != nil {
+		return -1, "", err
+	}
+	err = checkNotSuspended(user, time.Now())
 	if err != nil {
-		return -1, "", customErr.Internal(err.Error())
+		return -1, "", err
 	}
 
-	return IntUserID(id), metadata.UserRole, nil
+	return IntUserID(user.ID), model.Role(user.Role), nil
 }
 
 func (s *authService) Logout(ctx context.Context) (bool, error) {
@@ -124,10 +339,24 @@ func (s *authService) Logout(ctx context.Context) (bool, error) {
 
 }
 
-func (s *authService) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+func (s *authService) RequestPasswordReset(ctx context.Context, email string, captchaToken *string) (bool, error) {
+	//every request counts, whether the email exists or not
+	for _, limit := range []struct {
+		policy  auth.Policy
+		subject string
+	}{{resetByIp, clientIp(ctx)}, {resetByEmail, emailSubject(email)}} {
+		_, err := s.limiter.Take(limit.policy, limit.subject)
+		if err != nil {
+			return false, err
+		}
+	}
+	err := s.human.Check(ctx, auth.PasswordResetAction, captchaToken, clientIp(ctx))
+	if err != nil {
+		return false, err
+	}
 
 	user, err := s.repo.GetUserByEmail(email)
-	if err != nil {
+	if err != nil || !user.Verfied {
 		return false, nil
 	}
 
@@ -137,15 +366,57 @@ func (s *authService) RequestPasswordReset(ctx context.Context, email string) (b
 	}
 
 	go s.emailAdaptor.SendResetPassEmail("auth@shotify.com", []string{email},
-		user.Username, fmt.Sprintf("http://%s/reset?token=%s", domain, token))
+		user.Username, frontendLink("reset", token))
 
 	return true, nil
 
 }
 
+//ResendVerificationEmail sends a new verification link to an unverified account, it answers the same
+//whether the email belongs to an account or not
+func (s *authService) ResendVerificationEmail(ctx context.Context, email string) (bool, error) {
+	for _, limit := range []struct {
+		policy  auth.Policy
+		subject string
+	}{{resendByIp, clientIp(ctx)}, {resendByEmail, emailSubject(email)}} {
+		_, err := s.limiter.Take(limit.policy, limit.subject)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil || user.Verfied {
+		return true, nil
+	}
+	token, err := s.tk.CreateStatelessToken(fmt.Sprintf("%d", user.ID), auth.ValidateUserToken)
+	if err != nil {
+		return false, err
+	}
+	go s.emailAdaptor.SendWelcomeEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("validate", token))
+	return true, nil
+}
+
 func (s *authService) ProcessPasswordReset(ctx context.Context, resetToken string, newPass string) (bool, error) {
-	//If metadata is passed and the tokens valid, delete them from the redis store
-	userId, err := s.tk.ExtractStatelessTokenMetadata(ctx, resetToken, auth.ResetToken)
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, resetToken, auth.ResetToken)
+	if err != nil {
+		return false, err
+	}
+	if details.Jti == "" {
+		return false, customErr.NoAuth("this link is no longer valid, request a new one")
+	}
+	user, err := s.repo.GetUserById(details.UserId)
+	if err != nil {
+		return false, err
+	}
+	//a rejected password doesn't burn the link
+	err = checkPasswordPolicy(s.passwords, newPass, user.Username, user.Email)
+	if err != nil {
+		return false, err
+	}
+	//reset links can only be used once, the token is consumed before the password changes so it can't be raced
+	err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
 	if err != nil {
 		return false, err
 	}
@@ -153,29 +424,75 @@ func (s *authService) ProcessPasswordReset(ctx context.Context, resetToken strin
 	if err != nil {
 		return false, err
 	}
-	err = s.repo.UpdatePassword(userId, hashedPwd)
+	err = s.repo.UpdatePassword(details.UserId, hashedPwd)
 	if err != nil {
-		return true, nil
+		return false, err
 	}
+
+	//whoever had the old password is logged out everywhere
+	err = s.logoutEverywhere(details.UserId)
+	if err != nil {
+		return false, err
+	}
+	go s.emailAdaptor.SendPasswordChangedEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("reset", ""))
+
 	return true, nil
 
 }
 
 func (s *authService) ValidateUser(ctx context.Context, validationToken string) (bool, error) {
-	//If metadata is passed and the tokens valid, delete them from the redis store
-	userId, err := s.tk.ExtractStatelessTokenMetadata(ctx, validationToken, auth.ValidateUserToken)
+	ip := clientIp(ctx)
+	_, err := s.limiter.Take(validateByIp, ip)
 	if err != nil {
 		return false, err
 	}
-	err = s.repo.UpdateVerified(userId)
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, validationToken, auth.ValidateUserToken)
 	if err != nil {
-		return true, nil
+		return false, err
+	}
+	//links sent before validation tokens had a jti are still honored, verifying twice is harmless
+	if details.Jti != "" {
+		err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
+		if err != nil {
+			return false, err
+		}
+	}
+	err = s.repo.UpdateVerified(details.UserId)
+	if err != nil {
+		return false, err
+	}
+	//only links that don't work count towards the limit
+	err = s.limiter.Refund(validateByIp, ip)
+	if err != nil {
+		return false, err
 	}
 
 	return true, nil
 
 }
 
+//UnlockAccount lifts the login lockout of the account the unlock email was sent to
+func (s *authService) UnlockAccount(ctx context.Context, unlockToken string) (bool, error) {
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, unlockToken, auth.UnlockToken)
+	if err != nil {
+		return false, err
+	}
+	err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	user, err := s.repo.GetUserById(details.UserId)
+	if err != nil {
+		return false, err
+	}
+	err = s.limiter.Clear(loginByEmail, emailSubject(user.Email))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (s *authService) LogoutAll(ctx context.Context) (bool, error) {
 	metadata, err := s.tk.ExtractAccessTokenMetadata(ctx)
 	if err != nil {
@@ -189,30 +506,573 @@ func (s *authService) LogoutAll(ctx context.Context) (bool, error) {
 
 }
 
+//HasCredentials reports whether the request carries an access token or an auth cookie, valid or not
+func (s *authService) HasCredentials(ctx context.Context) bool {
+	ha, err := middleware.GetHeaderAccess(ctx)
+	if err == nil && ha.BearerToken != "" {
+		return true
+	}
+	ca, err := middleware.GetCookieAccess(ctx)
+	return err == nil && ca.EncodedCookie != ""
+}
+
 func (s *authService) RefreshCredentials(ctx context.Context) (bool, error) {
 	metadata, err := s.tk.ExtractRefreshMetadata(ctx)
 	if err != nil {
 		return false, err
 	}
 	userId, err := s.rd.FetchRefresh(metadata.RefreshUuid)
+	if err != nil {
+		return false, s.checkRefreshReuse(ctx, metadata, err)
+	}
+
+	//tokens issued before sessions were tracked have no family, they start a new session
+	sessionId := metadata.SessionId
+	if sessionId == "" {
+		err = s.rd.DeleteRefresh(metadata.RefreshUuid)
+		if err != nil {
+			return false, err
+		}
+		sessionId, err = gonanoid.New()
+		if err != nil {
+			return false, customErr.Internal(err.Error())
+		}
+	} else {
+		//the previous refresh token is rotated out, presenting it again revokes the session
+		err = s.rd.RotateRefresh(userId, sessionId, metadata.RefreshUuid, metadata.ExpiresAt)
+		if err != nil {
+			return false, s.checkRefreshReuse(ctx, metadata, err)
+		}
+	}
+	err = s.issueTokens(ctx, userId, model.Role(metadata.Role), sessionId)
 	if err != nil {
 		return false, err
 	}
+	return true, nil
+}
 
-	//Delete the previous Refresh Token
-	delErr := s.rd.DeleteRefresh(metadata.RefreshUuid)
-	if delErr != nil { //if any goes wrong
+//checkRefreshReuse turns a refresh token that was already rotated out into a revoked session and a security event,
+//a rotated out token showing up again means it was stolen or replayed. Other failures return err as is.
+func (s *authService) checkRefreshReuse(ctx context.Context, metadata *auth.RefreshDetails, err error) error {
+	if metadata.SessionId == "" {
+		return err
+	}
+	reused, reuseErr := s.rd.IsRotatedRefresh(metadata.UserId, metadata.SessionId, metadata.RefreshUuid)
+	if reuseErr != nil {
+		return reuseErr
+	}
+	if !reused {
+		return err
+	}
+	reuseErr = s.rd.RevokeFamily(metadata.UserId, metadata.SessionId)
+	if reuseErr != nil {
+		return reuseErr
+	}
+	reuseErr = s.recordSecurityEvent(ctx, metadata.UserId, RefreshTokenReuseEvent,
+		fmt.Sprintf("session %s was revoked", metadata.SessionId))
+	if reuseErr != nil {
+		return reuseErr
+	}
+	return customErr.NoAuth("this refresh token was already used, the session was revoked")
+}
+
+//saveSession records the session of the tokens with the client the request came from
+func (s *authService) saveSession(ctx context.Context, userId string, ts *auth.TokenDetails) error {
+	ha, err := middleware.GetHeaderAccess(ctx)
+	if err != nil {
+		return err
+	}
+	session := &auth.Session{
+		ID:        ts.SessionId,
+		UserId:    userId,
+		UserAgent: ha.UserAgent,
+		IP:        ha.IP,
+	}
+	return s.rd.SaveSession(session, ts)
+}
+
+func (s *authService) GetSessions(ctx context.Context) ([]*model.Session, error) {
+	metadata, err := s.tk.ExtractAccessTokenMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.rd.FetchSessions(metadata.UserId)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+
+	result := []*model.Session{}
+	for _, session := range sessions {
+		result = append(result, &model.Session{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			IP:        session.IP,
+			Created:   session.CreatedAt,
+			LastSeen:  session.LastSeen,
+			Current:   session.ID == metadata.SessionId,
+		})
+	}
+	return result, nil
+}
+
+func (s *authService) RevokeSession(ctx context.Context, sessionId string) (bool, error) {
+	metadata, err := s.tk.ExtractAccessTokenMetadata(ctx)
+	if err != nil {
 		return false, err
 	}
-	//Create new pairs of refresh and access csrf tokens
-	ts, createErr := s.tk.CreateTokens(userId, model.Role(metadata.Role))
-	if createErr != nil {
+	err = s.rd.DeleteSession(metadata.UserId, sessionId)
+	if err != nil {
 		return false, err
 	}
-	//save the tokens metadata to redis
-	saveErr := s.rd.CreateAuthTokens(userId, ts)
-	if saveErr != nil {
+	return true, nil
+}
+
+//EnableTwoFactor generates a new TOTP secret, 2FA is only turned on once ConfirmTwoFactor gets a code of it
+func (s *authService) EnableTwoFactor(ctx context.Context) (*model.TwoFactorSetup, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabled {
+		return nil, customErr.BadRequest("two factor authentication is already enabled")
+	}
+	secret, err := auth.GenerateTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.UpdateTwoFactor(fmt.Sprintf("%d", user.ID), secret, false)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TwoFactorSetup{Secret: secret, URI: auth.TotpUri(totpIssuer, user.Email, secret)}, nil
+}
+
+//ConfirmTwoFactor turns 2FA on and returns the recovery codes, they are only shown this one time
+func (s *authService) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabled {
+		return nil, customErr.BadRequest("two factor authentication is already enabled")
+	}
+	if user.TotpSecret == "" {
+		return nil, customErr.BadRequest("call enableTwoFactor first")
+	}
+	id := fmt.Sprintf("%d", user.ID)
+	counter, ok := auth.MatchTotp(user.TotpSecret, code, time.Now())
+	if ok {
+		//the code that turns 2FA on can't be used again to log in
+		ok, err = s.repo.UseTotpCounter(id, counter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		return nil, customErr.BadRequest("invalid two factor code")
+	}
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := []string{}
+	for _, c := range codes {
+		hashes = append(hashes, auth.HashRecoveryCode(c))
+	}
+	err = s.repo.ReplaceRecoveryCodes(id, hashes)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.UpdateTwoFactor(id, user.TotpSecret, true)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+//VerifyTwoFactor exchanges the challenge token of Login and a TOTP or recovery code for the real tokens
+func (s *authService) VerifyTwoFactor(ctx context.Context, challengeToken string, code string) (bool, error) {
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, challengeToken, auth.TwoFactorToken)
+	if err != nil {
+		return false, err
+	}
+	userId := details.UserId
+	_, err = s.limiter.Take(twoFactorByUser, userId)
+	if err != nil {
+		return false, err
+	}
+	user, err := s.repo.GetUserById(userId)
+	if err != nil {
+		return false, err
+	}
+	if !user.TotpEnabled {
+		return false, customErr.NoAuth("two factor authentication is not enabled")
+	}
+	ok, err := s.checkSecondFactor(user, code)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		s.recordLogin(ctx, user, model.LoginMethodTwoFactor, false)
+		return false, customErr.NoAuth("invalid two factor code")
+	}
+	//a challenge logs in once
+	err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	err = s.limiter.Clear(twoFactorByUser, userId)
+	if err != nil {
+		return false, err
+	}
+	//the account may have been suspended since the challenge was handed out
+	err = checkNotSuspended(user, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	err = s.issueCredentials(ctx, userId, model.Role(user.Role))
+	if err != nil {
+		return false, err
+	}
+	s.recordLogin(ctx, user, model.LoginMethodTwoFactor, true)
+	return true, nil
+}
+
+func (s *authService) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !user.TotpEnabled {
+		return false, customErr.BadRequest("two factor authentication is not enabled")
+	}
+	ok, err := s.checkSecondFactor(user, code)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, customErr.BadRequest("invalid two factor code")
+	}
+
+	id := fmt.Sprintf("%d", user.ID)
+	err = s.repo.UpdateTwoFactor(id, "", false)
+	if err != nil {
+		return false, err
+	}
+	err = s.repo.ReplaceRecoveryCodes(id, []string{})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *authService) ChangePassword(ctx context.Context, currentPassword string, newPassword string) (bool, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = checkCurrentPassword(s.limiter, user, currentPassword)
+	if err != nil {
+		return false, err
+	}
+	err = checkPasswordPolicy(s.passwords, newPassword, user.Username, user.Email)
+	if err != nil {
+		return false, err
+	}
+	hashedPwd, err := helpers.HashPassword(newPassword)
+	if err != nil {
+		return false, err
+	}
+	id := fmt.Sprintf("%d", user.ID)
+	err = s.repo.UpdatePassword(id, hashedPwd)
+	if err != nil {
+		return false, err
+	}
+	err = s.logoutOtherSessions(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	go s.emailAdaptor.SendPasswordChangedEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("reset", ""))
+	return true, nil
+}
+
+//RequestEmailChange sends a confirmation link to the new address, the email only changes once it is confirmed
+func (s *authService) RequestEmailChange(ctx context.Context, newEmail string, password string) (bool, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = checkCurrentPassword(s.limiter, user, password)
+	if err != nil {
+		return false, err
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(newEmail))
+	if err != nil || address.Address != strings.TrimSpace(newEmail) {
+		return false, customErr.BadRequest("invalid email")
+	}
+	newEmail = address.Address
+	if strings.EqualFold(newEmail, user.Email) {
+		return false, customErr.BadRequest("this is already your email")
+	}
+	err = s.checkEmailAvailable(newEmail)
+	if err != nil {
+		return false, err
+	}
+
+	token, err := s.tk.CreateEmailChangeToken(fmt.Sprintf("%d", user.ID), newEmail)
+	if err != nil {
+		return false, err
+	}
+	go s.emailAdaptor.SendEmailChangeEmail("auth@shotify.com", []string{newEmail},
+		user.Username, frontendLink("confirm-email", token))
+	return true, nil
+}
+
+//ConfirmEmailChange switches the account to the email the link was sent to and tells the old address about it
+func (s *authService) ConfirmEmailChange(ctx context.Context, token string) (bool, error) {
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, token, auth.EmailChangeToken)
+	if err != nil {
+		return false, err
+	}
+	if details.Email == "" || details.Jti == "" {
+		return false, customErr.NoAuth("this link is no longer valid, request a new one")
+	}
+	user, err := s.repo.GetUserById(details.UserId)
+	if err != nil {
 		return false, err
 	}
+	//someone may have signed up with the address since the link was sent
+	err = s.checkEmailAvailable(details.Email)
+	if err != nil {
+		return false, err
+	}
+	err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	err = s.repo.UpdateEmail(details.UserId, details.Email)
+	if err != nil {
+		return false, err
+	}
+	err = s.logoutOtherSessions(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	go s.emailAdaptor.SendEmailChangedEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("reset", ""))
 	return true, nil
 }
+
+//checkCurrentPassword guards account changes behind the password, every guess counts towards a lockout
+//until a right one clears them
+func checkCurrentPassword(limiter auth.RateLimiterInterface, user *dbModels.User, password string) error {
+	subject := fmt.Sprintf("%d", user.ID)
+	_, err := limiter.Take(passwordByUser, subject)
+	if err != nil {
+		return err
+	}
+	if !helpers.CheckPasswordHash(password, user.Password) {
+		return customErr.BadRequest("wrong password")
+	}
+	return limiter.Clear(passwordByUser, subject)
+}
+
+//checkPasswordPolicy rejects a new password the policy doesn't allow
+func checkPasswordPolicy(policy *auth.PasswordPolicy, password string, username string, email string) error {
+	failed := policy.Check(password, username, email)
+	if len(failed) > 0 {
+		return customErr.InvalidPassword(policy.Describe(failed), failed)
+	}
+	return nil
+}
+
+func (s *authService) checkEmailAvailable(email string) error {
+	c, err := s.repo.CountByEmail(email)
+	if err != nil {
+		return err
+	}
+	if c != 0 {
+		return customErr.BadRequest("A user with this email already exists")
+	}
+	return nil
+}
+
+//logoutEverywhere ends every session of the user and revokes their personal access tokens, for when someone
+//else may have had access to the account
+func (s *authService) logoutEverywhere(userId string) error {
+	err := s.rd.DeleteAllUserTokens(userId)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return customErr.BadRequest(err.Error())
+	}
+	return s.tokensRepo.DeleteAllByUser(id)
+}
+
+//logoutOtherSessions logs the user out everywhere, the session of the request if it is theirs gets new tokens
+func (s *authService) logoutOtherSessions(ctx context.Context, user *dbModels.User) error {
+	id := fmt.Sprintf("%d", user.ID)
+	metadata, metadataErr := s.tk.ExtractAccessTokenMetadata(ctx)
+	err := s.logoutEverywhere(id)
+	if err != nil {
+		return err
+	}
+	if metadataErr != nil || metadata.UserId != id {
+		return nil
+	}
+	return s.issueCredentials(ctx, id, model.Role(user.Role))
+}
+
+//checkSecondFactor accepts a TOTP code that wasn't used yet or an unused recovery code, both get burned
+func (s *authService) checkSecondFactor(user *dbModels.User, code string) (bool, error) {
+	id := fmt.Sprintf("%d", user.ID)
+	if counter, ok := auth.MatchTotp(user.TotpSecret, code, time.Now()); ok {
+		return s.repo.UseTotpCounter(id, counter)
+	}
+	return s.repo.UseRecoveryCode(id, auth.HashRecoveryCode(code))
+}
+
+func (s *authService) currentUser(ctx context.Context) (*dbModels.User, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	return s.repo.GetUserById(fmt.Sprintf("%d", userId))
+}
+
+func (s *authService) validateAccessToken(token string, scopes []string) (IntUserID, model.Role, error) {
+	accessToken, err := s.tokensRepo.GetByHash(auth.HashAccessToken(token))
+	if err != nil {
+		return -1, "", err
+	}
+	now := time.Now()
+	if accessToken.ExpiresAt != nil && accessToken.ExpiresAt.Before(now) {
+		return -1, "", customErr.NoAuth("access token expired")
+	}
+	if len(scopes) == 0 {
+		return -1, "", customErr.Forbidden("access tokens can't be used for this operation")
+	}
+	granted := map[string]bool{}
+	for _, scope := range strings.Split(accessToken.Scopes, ",") {
+		granted[scope] = true
+	}
+	for _, scope := range scopes {
+		if !granted[scope] {
+			return -1, "", customErr.Forbidden(fmt.Sprintf("access token is missing the %s scope", scope))
+		}
+	}
+
+	user, err := s.repo.GetUserById(fmt.Sprintf("%d", accessToken.UserID))
+	if err != nil {
+		return -1, "", err
+	}
+	err = checkNotSuspended(user, now)
+	if err != nil {
+		return -1, "", err
+	}
+	err = s.tokensRepo.UpdateLastUsed(accessToken.ID, now)
+	if err != nil {
+		return -1, "", err
+	}
+	return IntUserID(user.ID), model.Role(user.Role), nil
+}
+
+//CreateAccessToken returns the new token, it can't be shown again since only its hash is stored
+func (s *authService) CreateAccessToken(ctx context.Context, input model.NewAccessTokenInput) (*model.NewAccessToken, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, customErr.BadRequest("access token name can't be empty")
+	}
+	if len(input.Scopes) == 0 {
+		return nil, customErr.BadRequest("access token needs at least one scope")
+	}
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range input.Scopes {
+		if !auth.IsValidScope(scope) {
+			return nil, customErr.BadRequest(fmt.Sprintf("unknown scope %s", scope))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	token, hash, err := auth.GenerateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	dbToken := &dbModels.AccessToken{
+		UserID:    int(userId),
+		Name:      input.Name,
+		TokenHash: hash,
+		Scopes:    strings.Join(scopes, ","),
+		CreatedAt: time.Now(),
+	}
+	if input.ExpiresInDays != nil {
+		if *input.ExpiresInDays <= 0 {
+			return nil, customErr.BadRequest("expiresInDays must be positive")
+		}
+		expires := dbToken.CreatedAt.Add(time.Hour * 24 * time.Duration(*input.ExpiresInDays))
+		dbToken.ExpiresAt = &expires
+	}
+	id, err := s.tokensRepo.Create(dbToken)
+	if err != nil {
+		return nil, err
+	}
+	dbToken.ID = int(id)
+	return &model.NewAccessToken{Token: token, AccessToken: toAccessToken(dbToken)}, nil
+}
+
+func (s *authService) GetAccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	tokens, err := s.tokensRepo.GetAllByUser(int(userId))
+	if err != nil {
+		return nil, err
+	}
+	result := []*model.AccessToken{}
+	for i := range tokens {
+		result = append(result, toAccessToken(&tokens[i]))
+	}
+	return result, nil
+}
+
+func (s *authService) RevokeAccessToken(ctx context.Context, id string) (bool, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return false, customErr.Internal("userId not found in ctx")
+	}
+	tokenId, err := strconv.Atoi(id)
+	if err != nil {
+		return false, customErr.BadRequest("invalid access token id")
+	}
+	err = s.tokensRepo.Delete(tokenId, int(userId))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func toAccessToken(token *dbModels.AccessToken) *model.AccessToken {
+	return &model.AccessToken{
+		ID:       fmt.Sprintf("%d", token.ID),
+		Name:     token.Name,
+		Scopes:   strings.Split(token.Scopes, ","),
+		Created:  token.CreatedAt,
+		LastUsed: token.LastUsedAt,
+		Expires:  token.ExpiresAt,
+	}
+}
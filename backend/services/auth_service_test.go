@@ -0,0 +1,899 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/middleware"
+	repoMocks "github.com/gasser707/go-gql-server/mocks/repo"
+	emailMocks "github.com/gasser707/go-gql-server/mocks/services/email"
+	mocks "github.com/gasser707/go-gql-server/mocks/utils/auth"
+	"github.com/gasser707/go-gql-server/utils"
+	"github.com/gasser707/go-gql-server/utils/auth"
+	"github.com/gorilla/securecookie"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthServiceTestSuite struct {
+	suite.Suite
+}
+
+func (suite *AuthServiceTestSuite) TestGetSessions() {
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	older := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	mockTk.On("ExtractAccessTokenMetadata", ctx).Return(&auth.AccessDetails{UserId: "1", SessionId: "a"}, nil)
+	mockStore.On("FetchSessions", "1").Return([]*auth.Session{
+		{ID: "a", UserId: "1", UserAgent: "firefox", IP: "1.1.1.1", CreatedAt: older, LastSeen: older},
+		{ID: "b", UserId: "1", UserAgent: "curl", IP: "2.2.2.2", CreatedAt: older, LastSeen: newer},
+	}, nil)
+
+	authService := &authService{rd: &mockStore, tk: &mockTk}
+	result, err := authService.GetSessions(ctx)
+
+	mockTk.AssertExpectations(suite.T())
+	mockStore.AssertExpectations(suite.T())
+	suite.Nil(err)
+	suite.Equal([]*model.Session{
+		{ID: "b", UserAgent: "curl", IP: "2.2.2.2", Created: older, LastSeen: newer, Current: false},
+		{ID: "a", UserAgent: "firefox", IP: "1.1.1.1", Created: older, LastSeen: older, Current: true},
+	}, result)
+}
+
+func (suite *AuthServiceTestSuite) TestRevokeSession() {
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	mockTk.On("ExtractAccessTokenMetadata", ctx).Return(&auth.AccessDetails{UserId: "1", SessionId: "a"}, nil)
+	mockStore.On("DeleteSession", "1", "b").Return(nil)
+
+	authService := &authService{rd: &mockStore, tk: &mockTk}
+	result, err := authService.RevokeSession(ctx, "b")
+
+	mockStore.AssertExpectations(suite.T())
+	suite.Nil(err)
+	suite.True(result)
+}
+
+func (suite *AuthServiceTestSuite) TestLoginWithTwoFactor() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Role: "USER",
+		Password: hash, Verfied: true, TotpEnabled: true, TotpSecret: "ABC"}, nil)
+	mockTk.On("CreateStatelessToken", "1", auth.TwoFactorToken).Return("challenge", nil)
+	mockLimiter := mocks.RateLimiterInterface{}
+	mockLimiter.On("Take", mock.Anything, mock.Anything).Return(false, nil)
+	mockLimiter.On("Clear", loginByEmail, "foo@bar.com").Return(nil)
+	mockLimiter.On("Refund", loginByIp, "unknown").Return(nil)
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, limiter: &mockLimiter}
+	result, err := authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+
+	mockTk.AssertExpectations(suite.T())
+	suite.Nil(err)
+	challenge := "challenge"
+	suite.Equal(&model.LoginResult{LoggedIn: false, TwoFactorRequired: true, ChallengeToken: &challenge}, result)
+}
+
+func (suite *AuthServiceTestSuite) TestSuccessfulLoginsDontThrottleTheIp() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Role: "USER",
+		Password: hash, Verfied: true, TotpEnabled: true, TotpSecret: "ABC"}, nil)
+	mockTk.On("CreateStatelessToken", "1", auth.TwoFactorToken).Return("challenge", nil)
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, limiter: auth.NewMemoryRateLimiter()}
+	for i := 0; i < 2*loginByIp.Free; i++ {
+		_, err = authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+		suite.Nil(err)
+	}
+	//failures still add up
+	mockRepo.On("GetUserByEmail", mock.Anything).Return(nil, customErr.NotFound("no rows"))
+	for i := 0; i < loginByIp.Free; i++ {
+		_, err = authService.Login(ctx, model.LoginInput{Email: fmt.Sprintf("foo%d@bar.com", i), Password: "wrong"})
+		suite.NotNil(err)
+	}
+	_, err = authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+	suite.Equal(customErr.RateLimitedType, err.(*gqlerror.Error).Extensions["type"])
+}
+
+func (suite *AuthServiceTestSuite) TestLoginUpgradesBcryptHash() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.Background()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	suite.Nil(err)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Role: "USER",
+		Password: string(bcryptHash), Verfied: true, TotpEnabled: true}, nil)
+	mockRepo.On("UpdatePassword", "1", mock.MatchedBy(func(hash string) bool {
+		return strings.HasPrefix(hash, "$argon2id$") && helpers.CheckPasswordHash("secret", hash)
+	})).Return(nil)
+	mockTk.On("CreateStatelessToken", "1", auth.TwoFactorToken).Return("challenge", nil)
+	mockLimiter.On("Take", mock.Anything, mock.Anything).Return(false, nil)
+	mockLimiter.On("Clear", loginByEmail, "foo@bar.com").Return(nil)
+	mockLimiter.On("Refund", loginByIp, "unknown").Return(nil)
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, limiter: &mockLimiter}
+	_, err = authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+	suite.Nil(err)
+	mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestConfirmTwoFactor() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+
+	secret, err := auth.GenerateTotpSecret()
+	suite.Nil(err)
+	code, err := auth.TotpCode(secret, time.Now())
+	suite.Nil(err)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, TotpSecret: secret}, nil)
+	mockRepo.On("ReplaceRecoveryCodes", "1", mock.AnythingOfType("[]string")).Return(nil)
+	mockRepo.On("UpdateTwoFactor", "1", secret, true).Return(nil)
+	mockRepo.On("UseTotpCounter", "1", mock.AnythingOfType("int64")).Return(true, nil)
+
+	authService := &authService{repo: &mockRepo}
+	_, err = authService.ConfirmTwoFactor(ctx, "000000")
+	suite.NotNil(err)
+	mockRepo.AssertNotCalled(suite.T(), "UpdateTwoFactor", "1", secret, true)
+
+	codes, err := authService.ConfirmTwoFactor(ctx, code)
+	mockRepo.AssertExpectations(suite.T())
+	suite.Nil(err)
+	suite.Len(codes, recoveryCodeCount)
+}
+
+func (suite *AuthServiceTestSuite) TestVerifyTwoFactorRejectsReusedCode() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.Background()
+
+	secret, err := auth.GenerateTotpSecret()
+	suite.Nil(err)
+	code, err := auth.TotpCode(secret, time.Now())
+	suite.Nil(err)
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "challenge", auth.TwoFactorToken).Return(
+		&auth.StatelessDetails{UserId: "1", Jti: "jti"}, nil)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, TotpEnabled: true, TotpSecret: secret}, nil)
+	//the code was already used in this time step
+	mockRepo.On("UseTotpCounter", "1", mock.AnythingOfType("int64")).Return(false, nil)
+	mockRepo.On("UseRecoveryCode", "1", auth.HashRecoveryCode(code)).Return(false, nil)
+	mockRepo.On("CreateLoginEvent", mock.Anything).Return(nil)
+	mockLimiter.On("Take", twoFactorByUser, "1").Return(false, nil)
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, rd: &mockStore, limiter: &mockLimiter}
+	result, err := authService.VerifyTwoFactor(ctx, "challenge", code)
+
+	suite.NotNil(err)
+	suite.False(result)
+	mockStore.AssertNotCalled(suite.T(), "ConsumeTokenId", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestVerifyTwoFactorChallengeIsUsedOnce() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.Background()
+	details := &auth.StatelessDetails{UserId: "1", Jti: "jti", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	secret, err := auth.GenerateTotpSecret()
+	suite.Nil(err)
+	code, err := auth.TotpCode(secret, time.Now())
+	suite.Nil(err)
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "challenge", auth.TwoFactorToken).Return(details, nil)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, TotpEnabled: true, TotpSecret: secret}, nil)
+	mockRepo.On("UseTotpCounter", "1", mock.AnythingOfType("int64")).Return(true, nil)
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(customErr.NoAuth("this link was already used"))
+	mockLimiter.On("Take", twoFactorByUser, "1").Return(false, nil)
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, rd: &mockStore, limiter: &mockLimiter}
+	result, err := authService.VerifyTwoFactor(ctx, "challenge", code)
+
+	suite.NotNil(err)
+	suite.False(result)
+	mockStore.AssertExpectations(suite.T())
+	mockTk.AssertNotCalled(suite.T(), "CreateTokens", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestVerifyTwoFactorBurnsRecoveryCode() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	secret, err := auth.GenerateTotpSecret()
+	suite.Nil(err)
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "challenge", auth.TwoFactorToken).Return(
+		&auth.StatelessDetails{UserId: "1"}, nil)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, TotpEnabled: true, TotpSecret: secret}, nil)
+	mockRepo.On("UseRecoveryCode", "1", auth.HashRecoveryCode("abcde-fghjk")).Return(false, nil)
+	mockRepo.On("CreateLoginEvent", mock.MatchedBy(func(event *dbModels.LoginEvent) bool {
+		return event.UserID == 1 && event.Method == string(model.LoginMethodTwoFactor) && !event.Success
+	})).Return(nil)
+	mockLimiter := mocks.RateLimiterInterface{}
+	mockLimiter.On("Take", twoFactorByUser, "1").Return(false, nil)
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, limiter: &mockLimiter}
+	result, err := authService.VerifyTwoFactor(ctx, "challenge", "abcde-fghjk")
+
+	mockRepo.AssertExpectations(suite.T())
+	mockLimiter.AssertExpectations(suite.T())
+	suite.NotNil(err)
+	suite.False(result)
+}
+
+func (suite *AuthServiceTestSuite) TestValidateAccessToken() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTokens := repoMocks.AccessTokensRepoInterface{}
+	expired := time.Now().Add(-time.Hour)
+
+	mockTokens.On("GetByHash", auth.HashAccessToken("shp_valid")).Return(&dbModels.AccessToken{ID: 3, UserID: 1,
+		Scopes: "images:read,sales:read"}, nil)
+	mockTokens.On("GetByHash", auth.HashAccessToken("shp_expired")).Return(&dbModels.AccessToken{ID: 4, UserID: 1,
+		Scopes: "images:read", ExpiresAt: &expired}, nil)
+	mockTokens.On("UpdateLastUsed", 3, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Role: "USER"}, nil)
+
+	authService := &authService{repo: &mockRepo, tokensRepo: &mockTokens}
+
+	userId, role, err := authService.validateAccessToken("shp_valid", []string{"images:read"})
+	suite.Nil(err)
+	suite.Equal(IntUserID(1), userId)
+	suite.Equal(model.RoleUser, role)
+
+	_, _, err = authService.validateAccessToken("shp_valid", []string{"images:write"})
+	suite.NotNil(err)
+
+	_, _, err = authService.validateAccessToken("shp_valid", []string{})
+	suite.NotNil(err)
+
+	_, _, err = authService.validateAccessToken("shp_expired", []string{"images:read"})
+	suite.NotNil(err)
+
+	mockTokens.AssertNumberOfCalls(suite.T(), "UpdateLastUsed", 1)
+}
+
+func (suite *AuthServiceTestSuite) TestCreateAccessToken() {
+	mockTokens := repoMocks.AccessTokensRepoInterface{}
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+	days := 30
+
+	mockTokens.On("Create", mock.MatchedBy(func(token *dbModels.AccessToken) bool {
+		return token.UserID == 1 && token.Scopes == "images:read,images:write" && token.ExpiresAt != nil
+	})).Return(int64(7), nil)
+
+	authService := &authService{tokensRepo: &mockTokens}
+	_, err := authService.CreateAccessToken(ctx, model.NewAccessTokenInput{Name: "ci", Scopes: []string{"admin"}})
+	suite.NotNil(err)
+
+	result, err := authService.CreateAccessToken(ctx, model.NewAccessTokenInput{Name: "ci",
+		Scopes: []string{"images:read", "images:write", "images:read"}, ExpiresInDays: &days})
+	mockTokens.AssertExpectations(suite.T())
+	suite.Nil(err)
+	suite.Equal("7", result.AccessToken.ID)
+	suite.Equal([]string{"images:read", "images:write"}, result.AccessToken.Scopes)
+	suite.True(strings.HasPrefix(result.Token, "shp_"))
+}
+
+func (suite *AuthServiceTestSuite) TestProcessPasswordReset() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTokens := repoMocks.AccessTokensRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	ctx := context.Background()
+	details := &auth.StatelessDetails{UserId: "1", Jti: "jti", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	sent := make(chan bool, 1)
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "reset", auth.ResetToken).Return(details, nil)
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(nil).Once()
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com"}, nil)
+	mockRepo.On("UpdatePassword", "1", mock.AnythingOfType("string")).Return(nil)
+	mockStore.On("DeleteAllUserTokens", "1").Return(nil)
+	mockTokens.On("DeleteAllByUser", 1).Return(nil)
+	mockEmail.On("SendPasswordChangedEmail", mock.Anything, []string{"foo@bar.com"}, "foo", mock.Anything).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, rd: &mockStore, tokensRepo: &mockTokens, tk: &mockTk, emailAdaptor: &mockEmail,
+		passwords: auth.DefaultPasswordPolicy()}
+	//a password the policy rejects leaves the link usable
+	result, err := authService.ProcessPasswordReset(ctx, "reset", "foo12345")
+	suite.NotNil(err)
+	suite.False(result)
+	suite.Equal([]string{auth.PasswordPersonalInfo}, err.(*gqlerror.Error).Extensions["rules"])
+	mockStore.AssertNotCalled(suite.T(), "ConsumeTokenId", "jti", details.ExpiresAt)
+
+	result, err = authService.ProcessPasswordReset(ctx, "reset", "new password")
+	suite.Nil(err)
+	suite.True(result)
+	<-sent
+	mockStore.AssertExpectations(suite.T())
+	mockTokens.AssertExpectations(suite.T())
+	mockRepo.AssertExpectations(suite.T())
+
+	//the same link can't be used twice
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(customErr.NoAuth("this link was already used"))
+	result, err = authService.ProcessPasswordReset(ctx, "reset", "another password")
+	suite.NotNil(err)
+	suite.False(result)
+	mockRepo.AssertNumberOfCalls(suite.T(), "UpdatePassword", 1)
+}
+
+func (suite *AuthServiceTestSuite) TestProcessPasswordResetUpdateFails() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+	details := &auth.StatelessDetails{UserId: "1", Jti: "jti", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "reset", auth.ResetToken).Return(details, nil)
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(nil)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1}, nil)
+	mockRepo.On("UpdatePassword", "1", mock.AnythingOfType("string")).Return(customErr.DB(errors.New("db down")))
+
+	authService := &authService{repo: &mockRepo, rd: &mockStore, tk: &mockTk, passwords: auth.DefaultPasswordPolicy()}
+	result, err := authService.ProcessPasswordReset(ctx, "reset", "new password")
+	suite.NotNil(err)
+	suite.False(result)
+	mockStore.AssertNotCalled(suite.T(), "DeleteAllUserTokens", "1")
+}
+
+func (suite *AuthServiceTestSuite) TestLoginRateLimited() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.Background()
+
+	mockLimiter.On("Take", loginByIp, "unknown").Return(false, nil)
+	mockLimiter.On("Take", loginByEmail, "foo@bar.com").Return(false, customErr.RateLimited("locked out", time.Minute))
+
+	authService := &authService{repo: &mockRepo, limiter: &mockLimiter}
+	result, err := authService.Login(ctx, model.LoginInput{Email: " Foo@Bar.com", Password: "secret"})
+
+	suite.Nil(result)
+	gqlErr, ok := err.(*gqlerror.Error)
+	suite.True(ok)
+	suite.Equal(customErr.RateLimitedType, gqlErr.Extensions["type"])
+	suite.Equal(60, gqlErr.Extensions["retryAfter"])
+	mockRepo.AssertNotCalled(suite.T(), "GetUserByEmail", mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestLoginLockoutSendsUnlockEmail() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	ctx := context.Background()
+
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	sent := make(chan bool, 1)
+	mockLimiter.On("Take", loginByIp, "unknown").Return(false, nil)
+	//the attempt locks the email out, and it turns out to be a wrong password
+	mockLimiter.On("Take", loginByEmail, "foo@bar.com").Return(true, nil)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Username: "foo",
+		Email: "foo@bar.com", Password: hash}, nil)
+	mockRepo.On("CreateLoginEvent", mock.MatchedBy(func(event *dbModels.LoginEvent) bool {
+		return event.UserID == 1 && event.Method == string(model.LoginMethodPassword) && !event.Success
+	})).Return(nil)
+	mockTk.On("CreateStatelessToken", "1", auth.UnlockToken).Return("unlock", nil)
+	mockEmail.On("SendAccountLockedEmail", mock.Anything, []string{"foo@bar.com"}, "foo",
+		mock.MatchedBy(func(link string) bool { return strings.HasSuffix(link, "/unlock?token=unlock") })).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, limiter: &mockLimiter, tk: &mockTk, emailAdaptor: &mockEmail}
+	result, err := authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "wrong"})
+
+	suite.Nil(result)
+	suite.NotNil(err)
+	<-sent
+	mockLimiter.AssertExpectations(suite.T())
+	mockTk.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestRefreshTokenReuseRevokesFamily() {
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockRepo := repoMocks.AuthRepoInterface{}
+	ctx := context.Background()
+
+	metadata := &auth.RefreshDetails{RefreshUuid: "old++1", UserId: "1", Role: model.RoleUser, SessionId: "a"}
+	mockTk.On("ExtractRefreshMetadata", ctx).Return(metadata, nil)
+	mockStore.On("FetchRefresh", "old++1").Return("", customErr.NoAuth("refresh token not found"))
+	mockStore.On("IsRotatedRefresh", "1", "a", "old++1").Return(true, nil)
+	mockStore.On("RevokeFamily", "1", "a").Return(nil)
+	mockRepo.On("CreateSecurityEvent", mock.MatchedBy(func(event *dbModels.SecurityEvent) bool {
+		return event.UserID == 1 && event.Kind == RefreshTokenReuseEvent
+	})).Return(nil)
+
+	authService := &authService{rd: &mockStore, tk: &mockTk, repo: &mockRepo}
+	ok, err := authService.RefreshCredentials(ctx)
+
+	mockStore.AssertExpectations(suite.T())
+	mockRepo.AssertExpectations(suite.T())
+	suite.False(ok)
+	suite.NotNil(err)
+}
+
+func (suite *AuthServiceTestSuite) TestExpiredRefreshTokenIsNotReuse() {
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	metadata := &auth.RefreshDetails{RefreshUuid: "old++1", UserId: "1", Role: model.RoleUser, SessionId: "a"}
+	mockTk.On("ExtractRefreshMetadata", ctx).Return(metadata, nil)
+	notFound := customErr.NoAuth("refresh token not found")
+	mockStore.On("FetchRefresh", "old++1").Return("", notFound)
+	mockStore.On("IsRotatedRefresh", "1", "a", "old++1").Return(false, nil)
+
+	authService := &authService{rd: &mockStore, tk: &mockTk}
+	ok, err := authService.RefreshCredentials(ctx)
+
+	mockStore.AssertNotCalled(suite.T(), "RevokeFamily", "1", "a")
+	suite.False(ok)
+	suite.Equal(notFound, err)
+}
+
+func (suite *AuthServiceTestSuite) TestChangePassword() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTokens := repoMocks.AccessTokensRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+
+	hash, err := helpers.HashPassword("current")
+	suite.Nil(err)
+	sent := make(chan bool, 1)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com",
+		Password: hash, Role: "USER"}, nil)
+	mockRepo.On("UpdatePassword", "1", mock.AnythingOfType("string")).Return(nil)
+	mockLimiter.On("Take", passwordByUser, "1").Return(false, nil)
+	mockLimiter.On("Clear", passwordByUser, "1").Return(nil)
+	mockTk.On("ExtractAccessTokenMetadata", ctx).Return(nil, customErr.NoAuth("no cookie"))
+	mockStore.On("DeleteAllUserTokens", "1").Return(nil)
+	mockTokens.On("DeleteAllByUser", 1).Return(nil)
+	mockEmail.On("SendPasswordChangedEmail", mock.Anything, []string{"foo@bar.com"}, "foo", mock.Anything).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, rd: &mockStore, tokensRepo: &mockTokens, tk: &mockTk, limiter: &mockLimiter,
+		emailAdaptor: &mockEmail, passwords: auth.DefaultPasswordPolicy()}
+	result, err := authService.ChangePassword(ctx, "wrong", "new password")
+	suite.NotNil(err)
+	suite.False(result)
+	mockRepo.AssertNotCalled(suite.T(), "UpdatePassword", "1", mock.Anything)
+	mockLimiter.AssertCalled(suite.T(), "Take", passwordByUser, "1")
+
+	result, err = authService.ChangePassword(ctx, "current", "new password")
+	suite.Nil(err)
+	suite.True(result)
+	<-sent
+	mockRepo.AssertExpectations(suite.T())
+	mockStore.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestRequestEmailChange() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+
+	hash, err := helpers.HashPassword("current")
+	suite.Nil(err)
+	sent := make(chan bool, 1)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com",
+		Password: hash}, nil)
+	mockRepo.On("CountByEmail", "taken@bar.com").Return(1, nil)
+	mockRepo.On("CountByEmail", "new@bar.com").Return(0, nil)
+	mockLimiter.On("Take", passwordByUser, "1").Return(false, nil)
+	mockLimiter.On("Clear", passwordByUser, "1").Return(nil)
+	mockTk.On("CreateEmailChangeToken", "1", "new@bar.com").Return("token", nil)
+	mockEmail.On("SendEmailChangeEmail", mock.Anything, []string{"new@bar.com"}, "foo",
+		mock.MatchedBy(func(link string) bool { return strings.HasSuffix(link, "token=token") })).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, limiter: &mockLimiter, emailAdaptor: &mockEmail}
+	_, err = authService.RequestEmailChange(ctx, "not an email", "current")
+	suite.NotNil(err)
+	_, err = authService.RequestEmailChange(ctx, "taken@bar.com", "current")
+	suite.NotNil(err)
+
+	result, err := authService.RequestEmailChange(ctx, "new@bar.com", "current")
+	suite.Nil(err)
+	suite.True(result)
+	<-sent
+	mockTk.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestConfirmEmailChange() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTokens := repoMocks.AccessTokensRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	ctx := context.Background()
+	details := &auth.StatelessDetails{UserId: "1", Jti: "jti", ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		Email: "new@bar.com"}
+
+	sent := make(chan bool, 1)
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "token", auth.EmailChangeToken).Return(details, nil)
+	mockTk.On("ExtractAccessTokenMetadata", ctx).Return(nil, customErr.NoAuth("no cookie"))
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com"}, nil)
+	mockRepo.On("CountByEmail", "new@bar.com").Return(0, nil)
+	mockRepo.On("UpdateEmail", "1", "new@bar.com").Return(nil)
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(nil)
+	mockStore.On("DeleteAllUserTokens", "1").Return(nil)
+	mockTokens.On("DeleteAllByUser", 1).Return(nil)
+	//the notice goes to the old address
+	mockEmail.On("SendEmailChangedEmail", mock.Anything, []string{"foo@bar.com"}, "foo", mock.Anything).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, rd: &mockStore, tokensRepo: &mockTokens, tk: &mockTk, emailAdaptor: &mockEmail}
+	result, err := authService.ConfirmEmailChange(ctx, "token")
+	suite.Nil(err)
+	suite.True(result)
+	<-sent
+	mockRepo.AssertExpectations(suite.T())
+	mockStore.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLoginUnverified() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	ctx := context.Background()
+
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	mockLimiter.On("Take", mock.Anything, mock.Anything).Return(false, nil)
+	mockLimiter.On("Clear", loginByEmail, "foo@bar.com").Return(nil)
+	mockLimiter.On("Refund", loginByIp, "unknown").Return(nil)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Role: "USER", Password: hash}, nil)
+
+	authService := &authService{repo: &mockRepo, limiter: &mockLimiter}
+	result, err := authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+
+	suite.Nil(result)
+	suite.NotNil(err)
+	suite.Contains(err.(*gqlerror.Error).Message, "resendVerificationEmail")
+	suite.Equal(customErr.UnverifiedType, err.(*gqlerror.Error).Extensions["type"])
+}
+
+func (suite *AuthServiceTestSuite) TestLoginSuspended() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	since, until := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	mockLimiter.On("Take", mock.Anything, mock.Anything).Return(false, nil)
+	mockLimiter.On("Clear", loginByEmail, "foo@bar.com").Return(nil)
+	mockLimiter.On("Refund", loginByIp, "unknown").Return(nil)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Role: "USER", Password: hash,
+		Verfied: true, SuspendedAt: &since, SuspendedUntil: &until, SuspensionReason: "spam"}, nil)
+
+	authService := &authService{repo: &mockRepo, limiter: &mockLimiter, tk: &mockTk}
+	result, err := authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+
+	suite.Nil(result)
+	suite.NotNil(err)
+	suite.Contains(err.(*gqlerror.Error).Message, "spam")
+	suite.Equal(customErr.SuspendedType, err.(*gqlerror.Error).Extensions["type"])
+	suite.Equal(until.UTC().Format(time.RFC3339), err.(*gqlerror.Error).Extensions["until"])
+	mockTk.AssertNotCalled(suite.T(), "CreateTokens", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestValidateCredentialsRejectsSuspendedUser() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	store := auth.NewMemoryStore()
+	ctx := context.Background()
+
+	expires := time.Now().Add(time.Hour).Unix()
+	err := store.CreateAuthTokens("1", &auth.TokenDetails{TokenUuid: "at", CsrfUuid: "csrf", RefreshUuid: "rt",
+		AtExpires: expires, RtExpires: expires, CsrfExpires: expires})
+	suite.Nil(err)
+	mockTk.On("ExtractAccessTokenMetadata", ctx).Return(&auth.AccessDetails{TokenUuid: "at", CsrfUuid: "csrf",
+		UserId: "1", UserRole: model.RoleUser}, nil)
+	since := time.Now().Add(-time.Hour)
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Role: "MODERATOR"}, nil).Once()
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, rd: store}
+	//the role comes from the user rather than the token, so role changes apply right away
+	userId, role, err := authService.ValidateCredentials(ctx)
+	suite.Nil(err)
+	suite.Equal(IntUserID(1), userId)
+	suite.Equal(model.RoleModerator, role)
+
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Role: "USER", SuspendedAt: &since,
+		SuspensionReason: "spam"}, nil)
+	_, _, err = authService.ValidateCredentials(ctx)
+	suite.NotNil(err)
+	suite.Equal(customErr.SuspendedType, err.(*gqlerror.Error).Extensions["type"])
+	suite.Nil(err.(*gqlerror.Error).Extensions["until"])
+}
+
+func (suite *AuthServiceTestSuite) TestHasCredentials() {
+	authService := &authService{}
+	recorder := httptest.NewRecorder()
+	ctx := context.WithValue(context.Background(), utils.CookieKey, &middleware.CookieAccess{Writer: recorder})
+	ctx = context.WithValue(ctx, "header-name", &middleware.HeaderAccess{Writer: recorder})
+	suite.False(authService.HasCredentials(context.Background()))
+	suite.False(authService.HasCredentials(ctx))
+
+	cookieCtx := context.WithValue(ctx, utils.CookieKey, &middleware.CookieAccess{Writer: recorder,
+		EncodedCookie: "encoded"})
+	suite.True(authService.HasCredentials(cookieCtx))
+	bearerCtx := context.WithValue(ctx, "header-name", &middleware.HeaderAccess{Writer: recorder,
+		BearerToken: "shp_token"})
+	suite.True(authService.HasCredentials(bearerCtx))
+}
+
+func (suite *AuthServiceTestSuite) TestResendVerificationEmail() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	ctx := context.Background()
+
+	sent := make(chan bool, 1)
+	mockLimiter.On("Take", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("GetUserByEmail", "new@bar.com").Return(&dbModels.User{ID: 1, Username: "foo",
+		Email: "new@bar.com"}, nil)
+	mockRepo.On("GetUserByEmail", "done@bar.com").Return(&dbModels.User{ID: 2, Verfied: true}, nil)
+	mockRepo.On("GetUserByEmail", "nobody@bar.com").Return(nil, customErr.BadRequest("no rows"))
+	mockTk.On("CreateStatelessToken", "1", auth.ValidateUserToken).Return("validate", nil).Once()
+	mockEmail.On("SendWelcomeEmail", mock.Anything, []string{"new@bar.com"}, "foo",
+		mock.MatchedBy(func(link string) bool { return strings.HasSuffix(link, "/validate?token=validate") })).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, limiter: &mockLimiter, tk: &mockTk, emailAdaptor: &mockEmail}
+	for _, email := range []string{"new@bar.com", "done@bar.com", "nobody@bar.com"} {
+		result, err := authService.ResendVerificationEmail(ctx, email)
+		suite.Nil(err)
+		suite.True(result)
+	}
+	<-sent
+	mockTk.AssertExpectations(suite.T())
+	mockLimiter.AssertCalled(suite.T(), "Take", resendByEmail, "nobody@bar.com")
+
+	limited := &mocks.RateLimiterInterface{}
+	limited.On("Take", resendByIp, "unknown").Return(false, customErr.RateLimited("slow down", time.Minute))
+	authService.limiter = limited
+	_, err := authService.ResendVerificationEmail(ctx, "new@bar.com")
+	suite.NotNil(err)
+}
+
+func (suite *AuthServiceTestSuite) TestRequestMagicLink() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockLimiter := mocks.RateLimiterInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	ctx := context.Background()
+
+	sent := make(chan bool, 1)
+	mockLimiter.On("Take", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Username: "foo",
+		Email: "foo@bar.com"}, nil)
+	mockRepo.On("GetUserByEmail", "nobody@bar.com").Return(nil, customErr.BadRequest("no rows"))
+	mockTk.On("CreateStatelessToken", "1", auth.MagicLinkToken).Return("magic", nil).Once()
+	mockEmail.On("SendMagicLinkEmail", mock.Anything, []string{"foo@bar.com"}, "foo",
+		mock.MatchedBy(func(link string) bool { return strings.HasSuffix(link, "/magic-login?token=magic") })).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, limiter: &mockLimiter, tk: &mockTk, emailAdaptor: &mockEmail}
+	for _, email := range []string{"foo@bar.com", "nobody@bar.com"} {
+		result, err := authService.RequestMagicLink(ctx, email)
+		suite.Nil(err)
+		suite.True(result)
+	}
+	<-sent
+	mockTk.AssertExpectations(suite.T())
+	mockLimiter.AssertCalled(suite.T(), "Take", magicLinkByEmail, "nobody@bar.com")
+}
+
+func (suite *AuthServiceTestSuite) TestConsumeMagicLink() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	ctx := context.Background()
+	details := &auth.StatelessDetails{UserId: "1", Jti: "jti", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "magic", auth.MagicLinkToken).Return(details, nil)
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(nil).Once()
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(customErr.NoAuth("token was already used"))
+	//following the link proves the email, and 2FA still applies
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Role: "USER", TotpEnabled: true}, nil)
+	mockRepo.On("UpdateVerified", "1").Return(nil)
+	mockTk.On("CreateStatelessToken", "1", auth.TwoFactorToken).Return("challenge", nil)
+
+	authService := &authService{repo: &mockRepo, rd: &mockStore, tk: &mockTk}
+	result, err := authService.ConsumeMagicLink(ctx, "magic")
+	suite.Nil(err)
+	suite.False(result.LoggedIn)
+	suite.True(result.TwoFactorRequired)
+	suite.Equal("challenge", *result.ChallengeToken)
+	mockRepo.AssertExpectations(suite.T())
+
+	result, err = authService.ConsumeMagicLink(ctx, "magic")
+	suite.Nil(result)
+	suite.NotNil(err)
+	mockRepo.AssertNumberOfCalls(suite.T(), "GetUserById", 1)
+}
+
+func (suite *AuthServiceTestSuite) TestLoginFromNewDeviceSendsEmail() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	recorder := httptest.NewRecorder()
+	ctx := context.WithValue(context.Background(), utils.CookieKey, &middleware.CookieAccess{Writer: recorder})
+	ctx = context.WithValue(ctx, "header-name", &middleware.HeaderAccess{Writer: recorder, IP: "1.1.1.1",
+		UserAgent: "curl"})
+
+	hash, err := helpers.HashPassword("secret")
+	suite.Nil(err)
+	sent := make(chan bool, 1)
+	mockRepo.On("GetUserByEmail", "foo@bar.com").Return(&dbModels.User{ID: 1, Username: "foo",
+		Email: "foo@bar.com", Role: "USER", Password: hash, Verfied: true}, nil)
+	mockRepo.On("IsNewLoginDevice", "1", mock.AnythingOfType("string")).Return(true, nil)
+	mockRepo.On("CreateLoginEvent", mock.MatchedBy(func(event *dbModels.LoginEvent) bool {
+		return event.Method == string(model.LoginMethodPassword) && event.Success && event.IP == "1.1.1.1"
+	})).Return(nil)
+	mockTk.On("CreateTokens", "1", model.RoleUser, mock.Anything).Return(&auth.TokenDetails{}, nil)
+	mockTk.On("CreateStatelessToken", "1", auth.NotMeToken).Return("notme", nil)
+	mockEmail.On("SendNewDeviceEmail", mock.Anything, []string{"foo@bar.com"}, "foo", "curl", "1.1.1.1",
+		mock.Anything, mock.MatchedBy(func(link string) bool { return strings.HasSuffix(link, "/not-me?token=notme") })).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, tk: &mockTk, rd: auth.NewMemoryStore(),
+		limiter: auth.NewMemoryRateLimiter(), emailAdaptor: &mockEmail,
+		sc: securecookie.New(securecookie.GenerateRandomKey(32), nil)}
+	result, err := authService.Login(ctx, model.LoginInput{Email: "foo@bar.com", Password: "secret"})
+	suite.Nil(err)
+	suite.True(result.LoggedIn)
+	<-sent
+	mockRepo.AssertExpectations(suite.T())
+	mockEmail.AssertExpectations(suite.T())
+
+	//the device is the cookie the browser got, not its user agent
+	deviceId := ""
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == utils.DeviceCookieKey {
+			deviceId = cookie.Value
+		}
+	}
+	suite.NotEmpty(deviceId)
+	mockRepo.AssertCalled(suite.T(), "IsNewLoginDevice", "1", deviceFingerprint(deviceId, "curl"))
+}
+
+func (suite *AuthServiceTestSuite) TestLoginDeviceIsTheDeviceCookie() {
+	recorder := httptest.NewRecorder()
+	ctx := context.WithValue(context.Background(), utils.CookieKey, &middleware.CookieAccess{Writer: recorder,
+		DeviceId: "known"})
+	suite.Equal("known", loginDeviceId(ctx, true))
+	suite.Empty(recorder.Result().Cookies())
+
+	//everyone with the most common browser shares its user agent, but not its cookie
+	suite.NotEqual(deviceFingerprint("known", "Chrome"), deviceFingerprint("", "Chrome"))
+	suite.NotEqual(deviceFingerprint("known", "Chrome"), deviceFingerprint("other", "Chrome"))
+	suite.Equal(deviceFingerprint("", "Chrome"), deviceFingerprint("", " chrome"))
+
+	//a failed login doesn't hand out a device cookie
+	ctx = context.WithValue(context.Background(), utils.CookieKey, &middleware.CookieAccess{Writer: recorder})
+	suite.Equal("", loginDeviceId(ctx, false))
+	suite.Empty(recorder.Result().Cookies())
+}
+
+func (suite *AuthServiceTestSuite) TestReportUnrecognizedLogin() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockStore := mocks.AuthStoreOperatorInterface{}
+	mockTokens := repoMocks.AccessTokensRepoInterface{}
+	mockTk := mocks.TokenOperatorInterface{}
+	mockEmail := emailMocks.EmailAdaptorInterface{}
+	ctx := context.Background()
+	details := &auth.StatelessDetails{UserId: "1", Jti: "jti", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	sent := make(chan bool, 1)
+	mockTk.On("ExtractStatelessTokenMetadata", ctx, "notme", auth.NotMeToken).Return(details, nil)
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(nil).Once()
+	mockStore.On("ConsumeTokenId", "jti", details.ExpiresAt).Return(customErr.NoAuth("token was already used"))
+	mockRepo.On("GetUserById", "1").Return(&dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com"}, nil)
+	mockStore.On("DeleteAllUserTokens", "1").Return(nil).Once()
+	mockTokens.On("DeleteAllByUser", 1).Return(nil)
+	mockRepo.On("CreateSecurityEvent", mock.MatchedBy(func(event *dbModels.SecurityEvent) bool {
+		return event.UserID == 1 && event.Kind == UnrecognizedLoginEvent
+	})).Return(nil)
+	mockTk.On("CreateStatelessToken", "1", auth.ResetToken).Return("reset", nil)
+	mockEmail.On("SendResetPassEmail", mock.Anything, []string{"foo@bar.com"}, "foo",
+		mock.MatchedBy(func(link string) bool { return strings.HasSuffix(link, "/reset?token=reset") })).
+		Run(func(args mock.Arguments) { sent <- true })
+
+	authService := &authService{repo: &mockRepo, rd: &mockStore, tokensRepo: &mockTokens, tk: &mockTk, emailAdaptor: &mockEmail}
+	result, err := authService.ReportUnrecognizedLogin(ctx, "notme")
+	suite.Nil(err)
+	suite.True(result)
+	<-sent
+	mockStore.AssertExpectations(suite.T())
+	mockTokens.AssertExpectations(suite.T())
+	mockRepo.AssertExpectations(suite.T())
+
+	result, err = authService.ReportUnrecognizedLogin(ctx, "notme")
+	suite.NotNil(err)
+	suite.False(result)
+	mockEmail.AssertNumberOfCalls(suite.T(), "SendResetPassEmail", 1)
+}
+
+func (suite *AuthServiceTestSuite) TestGetLoginHistory() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+	at := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetLoginEvents", "1", 20).Return([]dbModels.LoginEvent{{ID: 4, UserID: 1,
+		Method: "PASSKEY", Success: true, IP: "1.1.1.1", UserAgent: "curl", CreatedAt: at}}, nil)
+
+	authService := &authService{repo: &mockRepo}
+	result, err := authService.GetLoginHistory(ctx, nil)
+	suite.Nil(err)
+	suite.Equal([]*model.LoginEvent{{ID: "4", Method: model.LoginMethodPasskey, Success: true,
+		IP: "1.1.1.1", UserAgent: "curl", Time: at}}, result)
+
+	limit := 1000
+	result, err = authService.GetLoginHistory(ctx, &limit)
+	suite.Nil(result)
+	suite.NotNil(err)
+}
+
+func (suite *AuthServiceTestSuite) TestRequestPasswordResetNeedsHumanVerification() {
+	mockRepo := repoMocks.AuthRepoInterface{}
+	mockVerifier := mocks.HumanVerifierInterface{}
+	ctx := context.Background()
+
+	mockVerifier.On("Verify", ctx, "bot", "unknown").Return(customErr.HumanVerificationFailed("failed"))
+	authService := &authService{repo: &mockRepo, limiter: auth.NewMemoryRateLimiter(),
+		human: auth.NewHumanCheck(&mockVerifier, auth.PasswordResetAction)}
+
+	result, err := authService.RequestPasswordReset(ctx, "foo@bar.com", nil)
+	suite.False(result)
+	suite.Equal(customErr.HumanVerificationType, err.(*gqlerror.Error).Extensions["type"])
+	token := "bot"
+	result, err = authService.RequestPasswordReset(ctx, "foo@bar.com", &token)
+	suite.False(result)
+	suite.NotNil(err)
+	mockVerifier.AssertExpectations(suite.T())
+	mockRepo.AssertNotCalled(suite.T(), "GetUserByEmail", "foo@bar.com")
+}
+
+func TestAuthServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthServiceTestSuite))
+}
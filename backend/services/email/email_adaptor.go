@@ -3,6 +3,7 @@ package services
 import (
 	"github.com/gasser707/go-gql-server/utils/emails"
 	"log"
+	"time"
 )
 
 type EmailAdaptorInterface interface {
@@ -10,6 +11,13 @@ type EmailAdaptorInterface interface {
 	SendResetPassEmail(sender string, to []string, name string, resetLink string)
 	SendReceiptEmail(sender string, to []string, sellerName string,
 		buyerName string, imageID string, imageTitle string, paymentMethod string)
+	SendPasswordChangedEmail(sender string, to []string, name string, resetLink string)
+	SendAccountLockedEmail(sender string, to []string, name string, unlockLink string)
+	SendEmailChangeEmail(sender string, to []string, name string, confirmLink string)
+	SendEmailChangedEmail(sender string, to []string, name string, resetLink string)
+	SendMagicLinkEmail(sender string, to []string, name string, loginLink string)
+	SendNewDeviceEmail(sender string, to []string, name string, device string, ip string, at time.Time,
+		notMeLink string)
 }
 
 //emailAdaptor implements the EmailAdaptorInterface
@@ -79,3 +87,99 @@ func (ea *emailAdaptor) SendReceiptEmail(sender string, to []string, sellerName
 	}
 
 }
+
+func (ea *emailAdaptor) SendPasswordChangedEmail(sender string, to []string, name string, resetLink string) {
+	email := &emails.Email{
+		Type:   emails.PasswordChanged,
+		Sender: sender,
+		To:     to,
+		Name:   name,
+		Link:   resetLink,
+	}
+
+	err := ea.emailService.SendEmail(email)
+	if err != nil {
+		log.Println("couldn't send email\n", err.Error())
+	}
+}
+
+func (ea *emailAdaptor) SendAccountLockedEmail(sender string, to []string, name string, unlockLink string) {
+	email := &emails.Email{
+		Type:   emails.AccountLocked,
+		Sender: sender,
+		To:     to,
+		Name:   name,
+		Link:   unlockLink,
+	}
+
+	err := ea.emailService.SendEmail(email)
+	if err != nil {
+		log.Println("couldn't send email\n", err.Error())
+	}
+}
+
+func (ea *emailAdaptor) SendEmailChangeEmail(sender string, to []string, name string, confirmLink string) {
+	email := &emails.Email{
+		Type:   emails.EmailChange,
+		Sender: sender,
+		To:     to,
+		Name:   name,
+		Link:   confirmLink,
+	}
+
+	err := ea.emailService.SendEmail(email)
+	if err != nil {
+		log.Println("couldn't send email\n", err.Error())
+	}
+}
+
+func (ea *emailAdaptor) SendEmailChangedEmail(sender string, to []string, name string, resetLink string) {
+	email := &emails.Email{
+		Type:   emails.EmailChanged,
+		Sender: sender,
+		To:     to,
+		Name:   name,
+		Link:   resetLink,
+	}
+
+	err := ea.emailService.SendEmail(email)
+	if err != nil {
+		log.Println("couldn't send email\n", err.Error())
+	}
+}
+
+func (ea *emailAdaptor) SendMagicLinkEmail(sender string, to []string, name string, loginLink string) {
+	email := &emails.Email{
+		Type:   emails.MagicLink,
+		Sender: sender,
+		To:     to,
+		Name:   name,
+		Link:   loginLink,
+	}
+
+	err := ea.emailService.SendEmail(email)
+	if err != nil {
+		log.Println("couldn't send email\n", err.Error())
+	}
+}
+
+func (ea *emailAdaptor) SendNewDeviceEmail(sender string, to []string, name string, device string, ip string,
+	at time.Time, notMeLink string) {
+	email := &emails.NewDeviceEmail{
+		Device: device,
+		IP:     ip,
+		Time:   at,
+		Email: emails.Email{
+			Type:   emails.NewDevice,
+			Sender: sender,
+			To:     to,
+			Name:   name,
+			Link:   notMeLink,
+		},
+	}
+
+	err := ea.emailService.SendEmail(email)
+	if err != nil {
+		log.Println("couldn't send email\n", err.Error())
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/custom"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+type FollowsServiceInterface interface {
+	Follow(ctx context.Context, userId string) (*custom.User, error)
+	Unfollow(ctx context.Context, userId string) (*custom.User, error)
+	GetFollowers(ctx context.Context, user *custom.User, first *int, after *string, last *int,
+		before *string) (*FollowsPage, error)
+	GetFollowing(ctx context.Context, user *custom.User, first *int, after *string, last *int,
+		before *string) (*FollowsPage, error)
+}
+
+//FollowsPage is a page of follows of a user, the resolver loads the users on the other side in one batch and
+//hands them to Connection
+type FollowsPage struct {
+	UserIDs []int
+	cursors []string
+	info    *repo.PageInfo
+}
+
+//Connection turns the page into a connection of the loaded users, a user that is gone by now is left out of the
+//edges and of the count
+func (p *FollowsPage) Connection(users []*custom.User, errs []error) (*model.UserConnection, error) {
+	edges := []*model.UserEdge{}
+	cursors := []string{}
+	for i := range p.UserIDs {
+		if i < len(errs) && errs[i] != nil {
+			return nil, errs[i]
+		}
+		if i >= len(users) || users[i] == nil {
+			continue
+		}
+		edges = append(edges, &model.UserEdge{Cursor: p.cursors[i], Node: users[i]})
+		cursors = append(cursors, p.cursors[i])
+	}
+	return &model.UserConnection{
+		Edges:      edges,
+		PageInfo:   NewPageInfo(p.info, cursors),
+		TotalCount: p.info.TotalCount - (len(p.UserIDs) - len(edges)),
+	}, nil
+}
+
+//followsService implements the FollowsServiceInterface
+var _ FollowsServiceInterface = &followsService{}
+
+type followsService struct {
+	repo repo.FollowsRepoInterface
+}
+
+func NewFollowsService(db *sqlx.DB) *followsService {
+	return &followsService{repo: repo.NewFollowsRepo(db)}
+}
+
+//Follow adds the new images of the user to the feed of the logged in user
+func (s *followsService) Follow(ctx context.Context, userId string) (*custom.User, error) {
+	followerId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if IntUserID(user.ID) == followerId {
+		return nil, customErr.BadRequest("you can't follow yourself")
+	}
+	err = s.repo.Follow(int(followerId), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+func (s *followsService) Unfollow(ctx context.Context, userId string) (*custom.User, error) {
+	followerId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.Unfollow(int(followerId), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+//GetFollowers lists who follows the user, a private profile hides them from other users
+func (s *followsService) GetFollowers(ctx context.Context, user *custom.User, first *int, after *string, last *int,
+	before *string) (*FollowsPage, error) {
+	return s.followsPage(ctx, user, first, after, last, before, s.repo.GetFollowersPage,
+		func(follow *dbModels.Follow) int { return follow.FollowerID })
+}
+
+//GetFollowing lists who the user follows, a private profile hides them from other users
+func (s *followsService) GetFollowing(ctx context.Context, user *custom.User, first *int, after *string, last *int,
+	before *string) (*FollowsPage, error) {
+	return s.followsPage(ctx, user, first, after, last, before, s.repo.GetFollowingPage,
+		func(follow *dbModels.Follow) int { return follow.FollowedID })
+}
+
+//followsPage fetches a page of follows of the user with the ids of the users on the other side
+func (s *followsService) followsPage(ctx context.Context, user *custom.User, first *int, after *string, last *int,
+	before *string, getPage func(userId int, page *repo.Page) ([]dbModels.Follow, *repo.PageInfo, error),
+	other func(follow *dbModels.Follow) int) (*FollowsPage, error) {
+	if !ProfileVisible(ctx, user) {
+		return &FollowsPage{UserIDs: []int{}, info: &repo.PageInfo{}}, nil
+	}
+	userId, err := strconv.Atoi(user.ID)
+	if err != nil {
+		return nil, customErr.BadRequest(err.Error())
+	}
+	page, err := repo.NewPage(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+	follows, info, err := getPage(userId, page)
+	if err != nil {
+		return nil, err
+	}
+	result := &FollowsPage{UserIDs: []int{}, info: info}
+	for i := range follows {
+		result.UserIDs = append(result.UserIDs, other(&follows[i]))
+		//the cursor is the follow, so pages are in the order the users were followed
+		result.cursors = append(result.cursors, repo.EncodeCursor(follows[i].CreatedAt, follows[i].ID))
+	}
+	return result, nil
+}
+
+func (s *followsService) getUser(userId string) (*dbModels.User, error) {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, customErr.BadRequest(err.Error())
+	}
+	return s.repo.GetUserById(id)
+}
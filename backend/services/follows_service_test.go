@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	"github.com/gasser707/go-gql-server/graphql/custom"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	repoMocks "github.com/gasser707/go-gql-server/mocks/repo"
+	"github.com/gasser707/go-gql-server/repo"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type FollowsServiceTestSuite struct {
+	suite.Suite
+	repo    *repoMocks.FollowsRepoInterface
+	service *followsService
+}
+
+func (suite *FollowsServiceTestSuite) SetupTest() {
+	suite.repo = &repoMocks.FollowsRepoInterface{}
+	suite.service = &followsService{repo: suite.repo}
+}
+
+func (suite *FollowsServiceTestSuite) viewer(id int) context.Context {
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(id))
+	return context.WithValue(ctx, helpers.UserRoleKey, model.RoleUser)
+}
+
+func (suite *FollowsServiceTestSuite) TestFollow() {
+	suite.repo.On("GetUserById", 2).Return(&dbModels.User{ID: 2, Role: "USER"}, nil)
+	suite.repo.On("Follow", 1, 2).Return(nil)
+
+	user, err := suite.service.Follow(suite.viewer(1), "2")
+
+	suite.Nil(err)
+	suite.Equal("2", user.ID)
+	suite.repo.AssertExpectations(suite.T())
+}
+
+func (suite *FollowsServiceTestSuite) TestFollowYourself() {
+	suite.repo.On("GetUserById", 1).Return(&dbModels.User{ID: 1, Role: "USER"}, nil)
+
+	_, err := suite.service.Follow(suite.viewer(1), "1")
+
+	suite.NotNil(err)
+	suite.repo.AssertNotCalled(suite.T(), "Follow", mock.Anything, mock.Anything)
+}
+
+func (suite *FollowsServiceTestSuite) TestGetFollowers() {
+	now := time.Now()
+	suite.repo.On("GetFollowersPage", 1, mock.Anything).Return([]dbModels.Follow{
+		{ID: 5, FollowerID: 3, FollowedID: 1, CreatedAt: now},
+		{ID: 4, FollowerID: 2, FollowedID: 1, CreatedAt: now.Add(-time.Hour)},
+	}, &repo.PageInfo{TotalCount: 2}, nil)
+
+	page, err := suite.service.GetFollowers(suite.viewer(2), &custom.User{ID: "1"}, nil, nil, nil, nil)
+	suite.Nil(err)
+	suite.Equal([]int{3, 2}, page.UserIDs)
+
+	connection, err := page.Connection([]*custom.User{{ID: "3"}, {ID: "2"}}, nil)
+	suite.Nil(err)
+	suite.Equal(2, connection.TotalCount)
+	suite.Equal("3", connection.Edges[0].Node.ID)
+	suite.Equal("2", connection.Edges[1].Node.ID)
+	suite.Equal(repo.EncodeCursor(now, 5), connection.Edges[0].Cursor)
+}
+
+func (suite *FollowsServiceTestSuite) TestMissingUsersLeaveTheCount() {
+	now := time.Now()
+	suite.repo.On("GetFollowingPage", 1, mock.Anything).Return([]dbModels.Follow{
+		{ID: 5, FollowerID: 1, FollowedID: 3, CreatedAt: now},
+		{ID: 4, FollowerID: 1, FollowedID: 2, CreatedAt: now.Add(-time.Hour)},
+	}, &repo.PageInfo{TotalCount: 7}, nil)
+
+	page, err := suite.service.GetFollowing(suite.viewer(2), &custom.User{ID: "1"}, nil, nil, nil, nil)
+	suite.Nil(err)
+	connection, err := page.Connection([]*custom.User{nil, {ID: "2"}}, nil)
+
+	suite.Nil(err)
+	suite.Equal(6, connection.TotalCount)
+	suite.Len(connection.Edges, 1)
+	suite.Equal("2", connection.Edges[0].Node.ID)
+	suite.Equal(repo.EncodeCursor(now.Add(-time.Hour), 4), *connection.PageInfo.StartCursor)
+}
+
+func (suite *FollowsServiceTestSuite) TestPrivateProfileHidesFollowing() {
+	page, err := suite.service.GetFollowing(suite.viewer(2), &custom.User{ID: "1", PrivateProfile: true},
+		nil, nil, nil, nil)
+	suite.Nil(err)
+	connection, err := page.Connection(nil, nil)
+
+	suite.Nil(err)
+	suite.Empty(connection.Edges)
+	suite.Equal(0, connection.TotalCount)
+	suite.repo.AssertNotCalled(suite.T(), "GetFollowingPage", mock.Anything, mock.Anything)
+}
+
+func TestFollowsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(FollowsServiceTestSuite))
+}
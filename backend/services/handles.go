@@ -0,0 +1,49 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+)
+
+//handlePattern is 3 to 30 letters, digits and underscores starting with a letter, handles are stored lowercase
+var handlePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,29}$`)
+
+//generatedHandlePattern matches the handles made up for users without a usable username, user_<id>
+var generatedHandlePattern = regexp.MustCompile(`^user_[0-9]+$`)
+
+//reservedHandles would pass for pages of the app or for staff, the backfill migration has the same list
+var reservedHandles = map[string]bool{
+	"about": true, "admin": true, "administrator": true, "api": true, "auth": true, "deleted": true,
+	"graphql": true, "help": true, "images": true, "login": true, "logout": true, "me": true,
+	"moderator": true, "null": true, "playground": true, "privacy": true, "query": true, "register": true,
+	"root": true, "sales": true, "security": true, "settings": true, "shotify": true, "signup": true,
+	"staff": true, "support": true, "system": true, "terms": true, "undefined": true, "user": true,
+	"users": true,
+}
+
+//normalizeHandle makes handles case insensitive, a leading @ from a /@handle link is dropped
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+//validateHandle checks a normalized handle a user picked
+func validateHandle(handle string) error {
+	if !handlePattern.MatchString(handle) {
+		return customErr.BadRequest("a handle has 3 to 30 letters, digits or underscores and starts with a letter")
+	}
+	if reservedHandles[handle] || generatedHandlePattern.MatchString(handle) {
+		return customErr.BadRequest("this handle is reserved")
+	}
+	return nil
+}
+
+//handleFromUsername suggests a handle for a user who didn't pick one, it is empty when the username can't be one
+func handleFromUsername(username string) string {
+	handle := normalizeHandle(username)
+	if validateHandle(handle) != nil {
+		return ""
+	}
+	return handle
+}
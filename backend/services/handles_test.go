@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *HandlesTestSuite) TestValidateHandle() {
+	for _, handle := range []string{"bob", "bob_99", "a_very_long_handle_of_30_chars"} {
+		suite.Nil(validateHandle(handle), handle)
+	}
+	for _, handle := range []string{"bo", "9bob", "_bob", "bob smith", "bob-smith", "Bob", "bób",
+		"a_very_long_handle_of_31_chars_"} {
+		suite.NotNil(validateHandle(handle), handle)
+	}
+	//reserved words and the made up handles can't be picked
+	for _, handle := range []string{"admin", "me", "user_12"} {
+		suite.NotNil(validateHandle(handle), handle)
+	}
+}
+
+func (suite *HandlesTestSuite) TestNormalizeHandle() {
+	suite.Equal("bob_smith", normalizeHandle(" @Bob_Smith "))
+}
+
+func (suite *HandlesTestSuite) TestHandleFromUsername() {
+	suite.Equal("bob", handleFromUsername("Bob"))
+	suite.Equal("", handleFromUsername("Bob Smith"))
+	suite.Equal("", handleFromUsername("Admin"))
+}
+
+func TestHandlesTestSuite(t *testing.T) {
+	suite.Run(t, new(HandlesTestSuite))
+}
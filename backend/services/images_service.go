@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/99designs/gqlgen/graphql"
 	dbModels "github.com/gasser707/go-gql-server/databases/models"
 	customErr "github.com/gasser707/go-gql-server/errors"
 	"github.com/gasser707/go-gql-server/graphql/custom"
@@ -26,6 +25,11 @@ type ImagesServiceInterface interface {
 	UploadImages(ctx context.Context, input []*model.NewImageInput) ([]*custom.Image, error)
 	DeleteImages(ctx context.Context, input []string) (bool, error)
 	GetImages(ctx context.Context, input *model.ImageFilterInput) ([]*custom.Image, error)
+	GetImagesPage(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int,
+		before *string) (*model.ImageConnection, error)
+	GetPublicImagesPage(ctx context.Context, input *model.ImageFilterInput, first *int, after *string, last *int,
+		before *string) (*model.ImageConnection, error)
+	GetFeed(ctx context.Context, first *int, after *string, last *int, before *string) (*model.ImageConnection, error)
 	GetImageById(ctx context.Context, ID string) (*custom.Image, error)
 	UpdateImage(ctx context.Context, input *model.UpdateImageInput) (*custom.Image, error)
 	AutoGenerateLabels(ctx context.Context, imageId string) ([]string, error)
@@ -167,41 +171,128 @@ func (s *imagesService) processDeleteImage(ID string, userId IntUserID) (err err
 }
 
 func (s *imagesService) GetImages(ctx context.Context, input *model.ImageFilterInput) ([]*custom.Image, error) {
-	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
-	if !ok {
-		return nil, customErr.Internal("userId not found in ctx")
+	if input != nil && input.ID != nil {
+		img, err := s.GetImageById(ctx, *input.ID)
+		if err != nil {
+			return nil, err
+		}
+		return []*custom.Image{img}, nil
 	}
-	if input == nil {
-		return s.GetAllPublicImgs(ctx)
+
+	filter, err := s.buildFilter(ctx, input)
+	if err != nil {
+		return nil, err
 	}
+	return s.GetImagesByFilter(ctx, filter)
 
-	if input.ID != nil {
+}
+
+func (s *imagesService) GetImagesPage(ctx context.Context, input *model.ImageFilterInput, first *int, after *string,
+	last *int, before *string) (*model.ImageConnection, error) {
+	if input != nil && input.ID != nil {
 		img, err := s.GetImageById(ctx, *input.ID)
 		if err != nil {
 			return nil, err
 		}
-		return []*custom.Image{img}, nil
+		imgId, _ := strconv.Atoi(img.ID)
+		cursor := repo.EncodeCursor(*img.Created, imgId)
+		return &model.ImageConnection{
+			Edges:      []*model.ImageEdge{{Cursor: cursor, Node: img}},
+			PageInfo:   NewPageInfo(&repo.PageInfo{TotalCount: 1}, []string{cursor}),
+			TotalCount: 1,
+		}, nil
 	}
 
-	if input.Image != nil {
-		s.searchbyImage(ctx, userId, input.Image)
+	filter, err := s.buildFilter(ctx, input)
+	if err != nil {
+		return nil, err
 	}
+	return s.imagesPage(filter, first, after, last, before)
+}
 
-	filter := helpers.ParseFilter(input, int(userId))
-	return s.GetImagesByFilter(ctx, userId, filter)
-
+//GetPublicImagesPage lists the public images to everyone, visitors included, and never the viewer's private ones
+func (s *imagesService) GetPublicImagesPage(ctx context.Context, input *model.ImageFilterInput, first *int,
+	after *string, last *int, before *string) (*model.ImageConnection, error) {
+	//searching by image calls the vision api, it is kept for users
+	if input != nil && input.Image != nil && GetViewerId(ctx) == AnonymousViewer {
+		return nil, customErr.NoAuth("log in to search by image")
+	}
+	filter, err := s.buildFilter(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	filter.ViewerID, filter.ViewerIsAdmin = repo.AnonymousViewer, false
+	return s.imagesPage(filter, first, after, last, before)
 }
 
-func (s *imagesService) GetImageById(ctx context.Context, ID string) (*custom.Image, error) {
+//GetFeed lists the public images of the users the viewer follows, newest first
+func (s *imagesService) GetFeed(ctx context.Context, first *int, after *string, last *int,
+	before *string) (*model.ImageConnection, error) {
 	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
 	if !ok {
 		return nil, customErr.Internal("userId not found in ctx")
 	}
+	followerId := int(userId)
+	return s.imagesPage(&repo.ImageFilter{ViewerID: followerId, FollowerID: &followerId}, first, after, last, before)
+}
+
+func (s *imagesService) imagesPage(filter *repo.ImageFilter, first *int, after *string, last *int,
+	before *string) (*model.ImageConnection, error) {
+	page, err := repo.NewPage(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+	dbImgs, info, err := s.repo.GetPage(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	imgs, err := s.toImages(dbImgs)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := []*model.ImageEdge{}
+	cursors := []string{}
+	for i, img := range imgs {
+		cursor := repo.EncodeCursor(dbImgs[i].CreatedAt, dbImgs[i].ID)
+		edges = append(edges, &model.ImageEdge{Cursor: cursor, Node: img})
+		cursors = append(cursors, cursor)
+	}
+	return &model.ImageConnection{
+		Edges:      edges,
+		PageInfo:   NewPageInfo(info, cursors),
+		TotalCount: info.TotalCount,
+	}, nil
+}
+
+//buildFilter makes the images filter for the viewer, searching by image is turned into labels
+func (s *imagesService) buildFilter(ctx context.Context, input *model.ImageFilterInput) (*repo.ImageFilter, error) {
+	filter, err := repo.NewImageFilter(input, int(GetViewerId(ctx)))
+	if err != nil {
+		return nil, err
+	}
+	filter.ViewerIsAdmin = IsAdmin(ctx)
+	if input != nil && input.Image != nil {
+		generatedLabels, err := s.visionOperator.DetectLocalImgProps(ctx, input.Image.File)
+		if err != nil {
+			return nil, err
+		}
+		//labels are stored lowercase, like the ones of the input
+		for _, l := range generatedLabels {
+			filter.Labels = append(filter.Labels, strings.ToLower(l))
+		}
+	}
+	return filter, nil
+}
+
+//GetImageById returns the image if the viewer can see it, visitors only see public images
+func (s *imagesService) GetImageById(ctx context.Context, ID string) (*custom.Image, error) {
+	userId := GetViewerId(ctx)
 	inputId, err := strconv.Atoi(ID)
 	if err != nil {
 		return nil, customErr.BadRequest(err.Error())
 	}
-	img, labels, err := s.repo.GetById(inputId, int(userId))
+	img, labels, err := s.repo.GetById(inputId, int(userId), IsAdmin(ctx))
 	if err != nil {
 		return nil, err
 
@@ -223,41 +314,15 @@ func (s *imagesService) GetImageById(ctx context.Context, ID string) (*custom.Im
 	}, nil
 }
 
-func (s *imagesService) GetImagesByFilter(ctx context.Context, userID IntUserID, filter string) ([]*custom.Image, error) {
+func (s *imagesService) GetImagesByFilter(ctx context.Context, filter *repo.ImageFilter) ([]*custom.Image, error) {
 	dbImgs, err := s.repo.GetByFilter(filter)
 	if err != nil {
 		return nil, err
 	}
-	imgList := []*custom.Image{}
-	for _, img := range dbImgs {
-		labels, err := s.repo.GetImageLabels(img.ID)
-		if err != nil {
-			return nil, err
-
-		}
-		imgList = append(imgList, &custom.Image{
-			ID:              fmt.Sprintf("%v", img.ID),
-			UserID:          fmt.Sprintf("%v", img.UserID),
-			Created:         &img.CreatedAt,
-			Title:           img.Title,
-			URL:             fmt.Sprintf("%s/%s/%s", utils.BaseGcsUrl, utils.BucketName, img.URL),
-			Description:     img.Description,
-			Private:         img.Private,
-			ForSale:         img.ForSale,
-			Price:           img.Price,
-			DiscountPercent: img.DiscountPercent,
-			Labels:          labels,
-			Archived:        img.Archived,
-		})
-	}
-	return imgList, nil
+	return s.toImages(dbImgs)
 }
 
-func (s *imagesService) GetAllPublicImgs(ctx context.Context) ([]*custom.Image, error) {
-	dbImgs, err := s.repo.GetAllPublic(ctx)
-	if err != nil {
-		return nil, err
-	}
+func (s *imagesService) toImages(dbImgs []*dbModels.Image) ([]*custom.Image, error) {
 	imgList := []*custom.Image{}
 	for _, img := range dbImgs {
 		labels, err := s.repo.GetImageLabels(img.ID)
@@ -344,19 +409,6 @@ func (s *imagesService) UpdateImage(ctx context.Context, input *model.UpdateImag
 	}, nil
 }
 
-func (s *imagesService) searchbyImage(ctx context.Context, userId IntUserID, img *graphql.Upload) ([]*custom.Image, error) {
-	generatedLabels, err := s.visionOperator.DetectLocalImgProps(ctx, img.File)
-	if err != nil {
-		return nil, err
-	}
-	inputFilter := &model.ImageFilterInput{
-		Labels: generatedLabels,
-	}
-	filter := helpers.ParseFilter(inputFilter, int(userId))
-
-	return s.GetImagesByFilter(ctx, userId, filter)
-}
-
 func (s *imagesService) insertLabels(labels []string, imgId int) error {
 	if len(labels) == 0 {
 		return nil
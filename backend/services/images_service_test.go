@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	mocks "github.com/gasser707/go-gql-server/mocks/utils/cloud"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImagesServiceTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ImagesServiceTestSuite) TestBuildFilterLowercasesDetectedLabels() {
+	mockVision := mocks.VisionOperatorInterface{}
+	ctx := context.Background()
+	file := strings.NewReader("image")
+
+	mockVision.On("DetectLocalImgProps", ctx, file).Return([]string{"Cat", "Grass"}, nil)
+
+	s := &imagesService{visionOperator: &mockVision}
+	filter, err := s.buildFilter(ctx, &model.ImageFilterInput{Labels: []string{"CAT"},
+		Image: &graphql.Upload{File: file}})
+
+	suite.Nil(err)
+	suite.Equal([]string{"cat", "cat", "grass"}, filter.Labels)
+}
+
+func TestImagesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ImagesServiceTestSuite))
+}
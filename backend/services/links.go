@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+//frontendUrl is where the links in emails lead, FRONTEND_URL (e.g. https://shotify.com/app) or else
+//FRONTEND_SCHEME (http by default) with DOMAIN_NAME
+var frontendUrl = frontendBaseUrl(os.Getenv("FRONTEND_URL"), os.Getenv("FRONTEND_SCHEME"), domain)
+
+func frontendBaseUrl(baseUrl string, scheme string, domain string) string {
+	if baseUrl != "" {
+		return strings.TrimRight(baseUrl, "/")
+	}
+	if scheme == "" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s", scheme, domain)
+}
+
+//frontendLink is the link to a page of the frontend, with the token in the query if there is one
+func frontendLink(path string, token string) string {
+	link := frontendUrl + "/" + strings.TrimLeft(path, "/")
+	if token == "" {
+		return link
+	}
+	return link + "?" + url.Values{"token": {token}}.Encode()
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LinksTestSuite struct {
+	suite.Suite
+}
+
+func (suite *LinksTestSuite) TestFrontendBaseUrl() {
+	suite.Equal("http://localhost", frontendBaseUrl("", "", "localhost"))
+	suite.Equal("https://shotify.com", frontendBaseUrl("", "https", "shotify.com"))
+	suite.Equal("https://shotify.com/app", frontendBaseUrl("https://shotify.com/app/", "http", "localhost"))
+}
+
+func (suite *LinksTestSuite) TestFrontendLink() {
+	previous := frontendUrl
+	defer func() { frontendUrl = previous }()
+	frontendUrl = "https://shotify.com/app"
+
+	suite.Equal("https://shotify.com/app/reset", frontendLink("reset", ""))
+	suite.Equal("https://shotify.com/app/validate?token=a.b-c", frontendLink("/validate", "a.b-c"))
+	suite.Equal("https://shotify.com/app/unlock?token=a%2Bb%3D", frontendLink("unlock", "a+b="))
+}
+
+func TestLinksTestSuite(t *testing.T) {
+	suite.Run(t, new(LinksTestSuite))
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/middleware"
+	"github.com/gasser707/go-gql-server/utils/auth"
+	"github.com/matoous/go-nanoid/v2"
+)
+
+const maxLoginHistory = 100
+
+//deviceFingerprint identifies the client a login came from by the device cookie it got at its first login. A client
+//that doesn't keep cookies falls back to its user agent, the ip is left out because it changes whenever the same
+//device moves to another network.
+func deviceFingerprint(deviceId string, userAgent string) string {
+	if deviceId != "" {
+		sum := sha256.Sum256([]byte("device:" + deviceId))
+		return hex.EncodeToString(sum[:])
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(userAgent))))
+	return hex.EncodeToString(sum[:])
+}
+
+//loginDeviceId is the device cookie of the client, a successful login from a client without one gives it one
+func loginDeviceId(ctx context.Context, success bool) string {
+	ca, err := middleware.GetCookieAccess(ctx)
+	if err != nil {
+		return ""
+	}
+	if ca.DeviceId != "" || !success {
+		return ca.DeviceId
+	}
+	deviceId, err := gonanoid.New(32)
+	if err != nil {
+		log.Printf("couldn't make a device id: %v\n", err)
+		return ""
+	}
+	ca.SetDeviceCookie(deviceId)
+	return deviceId
+}
+
+//recordLogin adds the attempt to the login history of the user, a successful login from a device the user never
+//logged in from gets them an email with a "this wasn't me" link. Failing to record doesn't fail the login.
+func (s *authService) recordLogin(ctx context.Context, user *dbModels.User, method model.LoginMethod, success bool) {
+	id := fmt.Sprintf("%d", user.ID)
+	event := &dbModels.LoginEvent{
+		UserID:    user.ID,
+		Method:    string(method),
+		Success:   success,
+		CreatedAt: time.Now(),
+	}
+	ha, err := middleware.GetHeaderAccess(ctx)
+	if err == nil {
+		event.IP = ha.IP
+		event.UserAgent = ha.UserAgent
+	}
+	event.Device = deviceFingerprint(loginDeviceId(ctx, success), event.UserAgent)
+
+	newDevice := false
+	if success {
+		newDevice, err = s.repo.IsNewLoginDevice(id, event.Device)
+		if err != nil {
+			log.Printf("couldn't look up the devices of user %d: %v\n", user.ID, err)
+		}
+	}
+	err = s.repo.CreateLoginEvent(event)
+	if err != nil {
+		log.Printf("couldn't record a login of user %d: %v\n", user.ID, err)
+	}
+	if !newDevice {
+		return
+	}
+
+	token, err := s.tk.CreateStatelessToken(id, auth.NotMeToken)
+	if err != nil {
+		log.Printf("couldn't create the new device link of user %d: %v\n", user.ID, err)
+		return
+	}
+	go s.emailAdaptor.SendNewDeviceEmail("auth@shotify.com", []string{user.Email}, user.Username,
+		event.UserAgent, event.IP, event.CreatedAt, frontendLink("not-me", token))
+}
+
+//GetLoginHistory returns the latest login attempts of the user, newest first
+func (s *authService) GetLoginHistory(ctx context.Context, limit *int) ([]*model.LoginEvent, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	n := 20
+	if limit != nil {
+		n = *limit
+	}
+	if n < 1 || n > maxLoginHistory {
+		return nil, customErr.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxLoginHistory))
+	}
+	events, err := s.repo.GetLoginEvents(fmt.Sprintf("%d", userId), n)
+	if err != nil {
+		return nil, err
+	}
+	result := []*model.LoginEvent{}
+	for _, event := range events {
+		result = append(result, &model.LoginEvent{
+			ID:        fmt.Sprintf("%d", event.ID),
+			Method:    model.LoginMethod(event.Method),
+			Success:   event.Success,
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			Time:      event.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+//ReportUnrecognizedLogin is the "this wasn't me" link of the new device email, it logs the user out of every
+//session, revokes their personal access tokens and emails them a link to reset their password. The link can only be used once.
+func (s *authService) ReportUnrecognizedLogin(ctx context.Context, token string) (bool, error) {
+	details, err := s.tk.ExtractStatelessTokenMetadata(ctx, token, auth.NotMeToken)
+	if err != nil {
+		return false, err
+	}
+	err = s.rd.ConsumeTokenId(details.Jti, details.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	user, err := s.repo.GetUserById(details.UserId)
+	if err != nil {
+		return false, err
+	}
+	err = s.logoutEverywhere(details.UserId)
+	if err != nil {
+		return false, err
+	}
+	err = s.recordSecurityEvent(ctx, details.UserId, UnrecognizedLoginEvent, "reported from the new device email")
+	if err != nil {
+		return false, err
+	}
+
+	resetToken, err := s.tk.CreateStatelessToken(details.UserId, auth.ResetToken)
+	if err != nil {
+		return false, err
+	}
+	go s.emailAdaptor.SendResetPassEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("reset", resetToken))
+	return true, nil
+}
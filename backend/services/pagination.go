@@ -0,0 +1,19 @@
+package services
+
+import (
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/repo"
+)
+
+//NewPageInfo converts the page info of a repo page using the cursors of the page edges in order
+func NewPageInfo(info *repo.PageInfo, cursors []string) *model.PageInfo {
+	pageInfo := &model.PageInfo{
+		HasNextPage:     info.HasNextPage,
+		HasPreviousPage: info.HasPreviousPage,
+	}
+	if len(cursors) > 0 {
+		pageInfo.StartCursor = &cursors[0]
+		pageInfo.EndCursor = &cursors[len(cursors)-1]
+	}
+	return pageInfo
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/matoous/go-nanoid/v2"
+)
+
+const (
+	//passkeyChallengeTTL is how long the browser has to answer a passkey challenge
+	passkeyChallengeTTL  = 5 * time.Minute
+	passkeyNameMaxLength = 100
+)
+
+//The ceremonies a passkey challenge can be answered in
+const (
+	passkeyRegistration = "register"
+	passkeyLogin        = "login"
+)
+
+//passkeyChallenge is what the auth store keeps between the two requests of a ceremony
+type passkeyChallenge struct {
+	Ceremony string               `json:"ceremony"`
+	Session  webauthn.SessionData `json:"session"`
+}
+
+//NewWebAuthn is the relying party passkeys are registered with. WEBAUTHN_RP_ORIGIN defaults to the origin of the
+//frontend and WEBAUTHN_RP_ID to its host, a passkey only works on the rp id it was registered on
+func NewWebAuthn() *webauthn.WebAuthn {
+	frontend, err := url.Parse(frontendUrl)
+	if err != nil {
+		log.Panic(err)
+	}
+	origin := os.Getenv("WEBAUTHN_RP_ORIGIN")
+	if origin == "" {
+		origin = frontend.Scheme + "://" + frontend.Host
+	}
+	rpId := os.Getenv("WEBAUTHN_RP_ID")
+	if rpId == "" {
+		rpId = frontend.Hostname()
+	}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPDisplayName: "Shotify",
+		RPID:          rpId,
+		RPOrigin:      origin,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationPreferred,
+		},
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	return wa
+}
+
+//passkeyUser is a user with their passkeys as the webauthn library sees them
+type passkeyUser struct {
+	user     *dbModels.User
+	passkeys []dbModels.Passkey
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(strconv.Itoa(u.user.ID))
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnIcon() string {
+	return u.user.Avatar
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := []webauthn.Credential{}
+	for _, passkey := range u.passkeys {
+		credentials = append(credentials, webauthn.Credential{
+			ID:              passkey.CredentialID,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Authenticator:   webauthn.Authenticator{AAGUID: passkey.AAGUID, SignCount: passkey.SignCount},
+		})
+	}
+	return credentials
+}
+
+func (s *authService) passkeyUser(user *dbModels.User) (*passkeyUser, error) {
+	passkeys, err := s.passkeys.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &passkeyUser{user: user, passkeys: passkeys}, nil
+}
+
+//BeginPasskeyRegistration starts registering a new passkey of the logged in user
+func (s *authService) BeginPasskeyRegistration(ctx context.Context) (*model.PasskeyChallenge, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pu, err := s.passkeyUser(user)
+	if err != nil {
+		return nil, err
+	}
+	//an authenticator that already holds a passkey of the account refuses to register it again
+	exclusions := []protocol.CredentialDescriptor{}
+	for _, credential := range pu.WebAuthnCredentials() {
+		exclusions = append(exclusions, protocol.CredentialDescriptor{
+			Type: protocol.PublicKeyCredentialType, CredentialID: credential.ID})
+	}
+	options, session, err := s.webauthn.BeginRegistration(pu, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	return s.savePasskeyChallenge(passkeyRegistration, session, options)
+}
+
+//FinishPasskeyRegistration verifies the attestation of the new passkey and saves it
+func (s *authService) FinishPasskeyRegistration(ctx context.Context, challengeId string, name string,
+	credential string) (*model.Passkey, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > passkeyNameMaxLength {
+		return nil, customErr.BadRequest(fmt.Sprintf("the passkey name must have 1 to %d characters",
+			passkeyNameMaxLength))
+	}
+	session, err := s.takePasskeyChallenge(challengeId, passkeyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	pu, err := s.passkeyUser(user)
+	if err != nil {
+		return nil, err
+	}
+	//a challenge started by another user fails here too
+	parsed, err := protocol.ParseCredentialCreationResponseBody(strings.NewReader(credential))
+	if err != nil {
+		return nil, customErr.BadRequest(passkeyErrorDetails(err))
+	}
+	created, err := s.webauthn.CreateCredential(pu, *session, parsed)
+	if err != nil {
+		return nil, customErr.BadRequest(passkeyErrorDetails(err))
+	}
+
+	passkey := &dbModels.Passkey{
+		UserID:          user.ID,
+		Name:            name,
+		CredentialID:    created.ID,
+		PublicKey:       created.PublicKey,
+		AttestationType: created.AttestationType,
+		AAGUID:          created.Authenticator.AAGUID,
+		SignCount:       created.Authenticator.SignCount,
+		CreatedAt:       time.Now(),
+	}
+	id, err := s.passkeys.Create(passkey)
+	if err != nil {
+		return nil, err
+	}
+	passkey.ID = int(id)
+	return toPasskey(passkey), nil
+}
+
+//BeginPasskeyLogin challenges the passkeys of the account of the email
+func (s *authService) BeginPasskeyLogin(ctx context.Context, email string) (*model.PasskeyChallenge, error) {
+	err := s.limiter.Allow(loginByIp, clientIp(ctx))
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		return nil, customErr.NoAuth("no passkey is registered for this email")
+	}
+	pu, err := s.passkeyUser(user)
+	if err != nil {
+		return nil, err
+	}
+	if len(pu.passkeys) == 0 {
+		return nil, customErr.NoAuth("no passkey is registered for this email")
+	}
+	//the passkey stands in for the password and the second factor, so a key that is only touched isn't enough
+	options, session, err := s.webauthn.BeginLogin(pu, webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	return s.savePasskeyChallenge(passkeyLogin, session, options)
+}
+
+//FinishPasskeyLogin verifies the assertion of the passkey and logs its user in. The login challenge requires
+//user verification, so the passkey proves possession and a PIN or biometric and isn't followed by the two
+//factor challenge.
+func (s *authService) FinishPasskeyLogin(ctx context.Context, challengeId string,
+	credential string) (*model.LoginResult, error) {
+	_, err := s.limiter.Take(loginByIp, clientIp(ctx))
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.takePasskeyChallenge(challengeId, passkeyLogin)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetUserById(string(session.UserID))
+	if err != nil {
+		return nil, err
+	}
+	pu, err := s.passkeyUser(user)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBody(strings.NewReader(credential))
+	if err != nil {
+		return nil, customErr.BadRequest(passkeyErrorDetails(err))
+	}
+	used, err := s.webauthn.ValidateLogin(pu, *session, parsed)
+	if err != nil {
+		s.recordLogin(ctx, user, model.LoginMethodPasskey, false)
+		return nil, customErr.NoAuth(passkeyErrorDetails(err))
+	}
+
+	var passkey *dbModels.Passkey
+	for i := range pu.passkeys {
+		if bytes.Equal(pu.passkeys[i].CredentialID, used.ID) {
+			passkey = &pu.passkeys[i]
+		}
+	}
+	//a counter that didn't move forward means the key was copied, neither copy can log in again
+	if used.Authenticator.CloneWarning {
+		s.recordLogin(ctx, user, model.LoginMethodPasskey, false)
+		err = s.passkeys.Delete(passkey.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = s.recordSecurityEvent(ctx, strconv.Itoa(user.ID), PasskeyCloneEvent,
+			fmt.Sprintf("passkey %d", passkey.ID))
+		if err != nil {
+			return nil, err
+		}
+		return nil, customErr.NoAuth("this passkey can't be used anymore, sign in another way")
+	}
+	err = s.passkeys.UpdateSignCount(passkey.ID, used.Authenticator.SignCount, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	err = s.limiter.Refund(loginByIp, clientIp(ctx))
+	if err != nil {
+		return nil, err
+	}
+	err = checkNotSuspended(user, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.issueCredentials(ctx, strconv.Itoa(user.ID), model.Role(user.Role))
+	if err != nil {
+		return nil, err
+	}
+	s.recordLogin(ctx, user, model.LoginMethodPasskey, true)
+	return &model.LoginResult{LoggedIn: true, TwoFactorRequired: false}, nil
+}
+
+func (s *authService) GetPasskeys(ctx context.Context) ([]*model.Passkey, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	passkeys, err := s.passkeys.GetAllByUser(int(userId))
+	if err != nil {
+		return nil, err
+	}
+	result := []*model.Passkey{}
+	for i := range passkeys {
+		result = append(result, toPasskey(&passkeys[i]))
+	}
+	return result, nil
+}
+
+func (s *authService) DeletePasskey(ctx context.Context, id string) (bool, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return false, customErr.Internal("userId not found in ctx")
+	}
+	passkeyId, err := strconv.Atoi(id)
+	if err != nil {
+		return false, customErr.BadRequest("invalid passkey id")
+	}
+	err = s.passkeys.Delete(passkeyId, int(userId))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *authService) savePasskeyChallenge(ceremony string, session *webauthn.SessionData,
+	options interface{}) (*model.PasskeyChallenge, error) {
+	id, err := gonanoid.New()
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	data, err := json.Marshal(passkeyChallenge{Ceremony: ceremony, Session: *session})
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	err = s.rd.SaveChallenge(id, string(data), time.Now().Add(passkeyChallengeTTL).Unix())
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	return &model.PasskeyChallenge{ChallengeID: id, Options: string(encoded)}, nil
+}
+
+//takePasskeyChallenge returns the session of the challenge, a challenge can only be answered once
+func (s *authService) takePasskeyChallenge(id string, ceremony string) (*webauthn.SessionData, error) {
+	data, err := s.rd.TakeChallenge(id)
+	if err != nil {
+		return nil, err
+	}
+	challenge := passkeyChallenge{}
+	err = json.Unmarshal([]byte(data), &challenge)
+	if err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	if challenge.Ceremony != ceremony {
+		return nil, customErr.BadRequest("this challenge is for another passkey ceremony")
+	}
+	return &challenge.Session, nil
+}
+
+//passkeyErrorDetails is what the client is told about a failed ceremony, the developer info stays in the logs
+func passkeyErrorDetails(err error) string {
+	if perr, ok := err.(*protocol.Error); ok {
+		if perr.DevInfo != "" {
+			log.Printf("passkey ceremony failed: %s: %s %s\n", perr.Type, perr.Details, perr.DevInfo)
+		}
+		return strings.TrimSpace(fmt.Sprintf("%s: %s", perr.Type, perr.Details))
+	}
+	return err.Error()
+}
+
+func toPasskey(passkey *dbModels.Passkey) *model.Passkey {
+	return &model.Passkey{
+		ID:       fmt.Sprintf("%d", passkey.ID),
+		Name:     passkey.Name,
+		Created:  passkey.CreatedAt,
+		LastUsed: passkey.LastUsedAt,
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/fxamacker/cbor/v2"
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/middleware"
+	repoMocks "github.com/gasser707/go-gql-server/mocks/repo"
+	mocks "github.com/gasser707/go-gql-server/mocks/utils/auth"
+	"github.com/gasser707/go-gql-server/utils"
+	"github.com/gasser707/go-gql-server/utils/auth"
+	"github.com/gorilla/securecookie"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testRpId   = "shotify.test"
+	testOrigin = "https://shotify.test"
+)
+
+//softAuthenticator is a passkey kept in memory, it answers challenges the way a browser and a platform
+//authenticator would with a P-256 key and "none" attestation
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+	counter      uint32
+	//presenceOnly answers without user verification, like a security key without a PIN
+	presenceOnly bool
+}
+
+func newSoftAuthenticator() *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return &softAuthenticator{key: key, credentialId: []byte("soft-credential")}
+}
+
+func (a *softAuthenticator) clientData(ceremony string, options string) []byte {
+	parsed := struct {
+		PublicKey struct {
+			Challenge []byte `json:"challenge"`
+		} `json:"publicKey"`
+	}{}
+	if err := json.Unmarshal([]byte(options), &parsed); err != nil {
+		panic(err)
+	}
+	data, _ := json.Marshal(map[string]string{"type": ceremony, "challenge": b64(parsed.PublicKey.Challenge),
+		"origin": testOrigin})
+	return data
+}
+
+func (a *softAuthenticator) authData(flags byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(testRpId))
+	data := append(rpIdHash[:], flags)
+	counter := make([]byte, 4)
+	binary.BigEndian.PutUint32(counter, a.counter)
+	return append(data, counter...)
+}
+
+//register answers a registration challenge with the credential the client sends to finishPasskeyRegistration
+func (a *softAuthenticator) register(options string) string {
+	coseKey, _ := cbor.Marshal(map[int]interface{}{1: 2, 3: -7, -1: 1,
+		-2: a.key.X.FillBytes(make([]byte, 32)), -3: a.key.Y.FillBytes(make([]byte, 32))})
+	//user present, user verified and attested credential data
+	authData := a.authData(0x45)
+	authData = append(authData, make([]byte, 16)...)
+	authData = append(authData, byte(len(a.credentialId)>>8), byte(len(a.credentialId)))
+	authData = append(authData, a.credentialId...)
+	authData = append(authData, coseKey...)
+	attestation, _ := cbor.Marshal(map[string]interface{}{"fmt": "none", "attStmt": map[string]interface{}{},
+		"authData": authData})
+
+	return a.encode(map[string]string{
+		"clientDataJSON":    b64(a.clientData("webauthn.create", options)),
+		"attestationObject": b64(attestation),
+	})
+}
+
+//login answers a login challenge with the credential the client sends to finishPasskeyLogin
+func (a *softAuthenticator) login(options string, userId string) string {
+	a.counter++
+	clientData := a.clientData("webauthn.get", options)
+	//user present and user verified
+	flags := byte(0x05)
+	if a.presenceOnly {
+		flags = 0x01
+	}
+	authData := a.authData(flags)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+
+	return a.encode(map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64([]byte(userId)),
+	})
+}
+
+func (a *softAuthenticator) encode(response map[string]string) string {
+	credential, _ := json.Marshal(map[string]interface{}{
+		"id":       b64(a.credentialId),
+		"rawId":    b64(a.credentialId),
+		"type":     "public-key",
+		"response": response,
+	})
+	return string(credential)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+type PasskeysTestSuite struct {
+	suite.Suite
+	repo     *repoMocks.AuthRepoInterface
+	passkeys *repoMocks.PasskeysRepoInterface
+	tk       *mocks.TokenOperatorInterface
+	stored   []dbModels.Passkey
+	service  *authService
+}
+
+func (suite *PasskeysTestSuite) SetupTest() {
+	suite.repo = &repoMocks.AuthRepoInterface{}
+	suite.passkeys = &repoMocks.PasskeysRepoInterface{}
+	suite.tk = &mocks.TokenOperatorInterface{}
+	suite.stored = nil
+
+	user := &dbModels.User{ID: 1, Username: "foo", Email: "foo@bar.com", Role: "USER", Verfied: true}
+	suite.repo.On("GetUserById", "1").Return(user, nil)
+	suite.repo.On("GetUserByEmail", "foo@bar.com").Return(user, nil)
+	suite.repo.On("IsNewLoginDevice", "1", mock.Anything).Return(false, nil)
+	suite.repo.On("CreateLoginEvent", mock.Anything).Return(nil)
+	suite.passkeys.On("GetAllByUser", 1).Return(func(int) []dbModels.Passkey { return suite.stored }, nil)
+	suite.passkeys.On("Create", mock.Anything).Return(int64(3), nil).Run(func(args mock.Arguments) {
+		passkey := *args.Get(0).(*dbModels.Passkey)
+		passkey.ID = 3
+		suite.stored = append(suite.stored, passkey)
+	})
+
+	wa, err := webauthn.New(&webauthn.Config{RPDisplayName: "Shotify", RPID: testRpId, RPOrigin: testOrigin})
+	suite.Nil(err)
+	suite.service = &authService{repo: suite.repo, passkeys: suite.passkeys, tk: suite.tk, rd: auth.NewMemoryStore(),
+		limiter: auth.NewMemoryRateLimiter(), webauthn: wa,
+		sc: securecookie.New(securecookie.GenerateRandomKey(32), nil)}
+}
+
+//registerPasskey goes through the registration of the authenticator as user 1
+func (suite *PasskeysTestSuite) registerPasskey(authenticator *softAuthenticator) {
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+	challenge, err := suite.service.BeginPasskeyRegistration(ctx)
+	suite.Nil(err)
+	passkey, err := suite.service.FinishPasskeyRegistration(ctx, challenge.ChallengeID, " laptop ",
+		authenticator.register(challenge.Options))
+	suite.Nil(err)
+	suite.Equal(&model.Passkey{ID: "3", Name: "laptop", Created: suite.stored[0].CreatedAt}, passkey)
+}
+
+func (suite *PasskeysTestSuite) TestRegisterAndLogin() {
+	authenticator := newSoftAuthenticator()
+	suite.registerPasskey(authenticator)
+	suite.Equal(authenticator.credentialId, suite.stored[0].CredentialID)
+
+	recorder := httptest.NewRecorder()
+	ctx := context.WithValue(context.Background(), utils.CookieKey, &middleware.CookieAccess{Writer: recorder})
+	ctx = context.WithValue(ctx, "header-name", &middleware.HeaderAccess{Writer: recorder, IP: "1.1.1.1"})
+	suite.tk.On("CreateTokens", "1", model.RoleUser, mock.Anything).Return(&auth.TokenDetails{
+		AccessToken: "at", RefreshToken: "rt", CsrfToken: "csrf"}, nil)
+	suite.passkeys.On("UpdateSignCount", 3, uint32(1), mock.Anything).Return(nil)
+
+	challenge, err := suite.service.BeginPasskeyLogin(ctx, "foo@bar.com")
+	suite.Nil(err)
+	credential := authenticator.login(challenge.Options, "1")
+	result, err := suite.service.FinishPasskeyLogin(ctx, challenge.ChallengeID, credential)
+	suite.Nil(err)
+	suite.Equal(&model.LoginResult{LoggedIn: true}, result)
+	suite.Equal("csrf", recorder.Header().Get("X-CSRF-Token"))
+	suite.passkeys.AssertExpectations(suite.T())
+
+	//a challenge is answered once
+	result, err = suite.service.FinishPasskeyLogin(ctx, challenge.ChallengeID, credential)
+	suite.Nil(result)
+	suite.NotNil(err)
+	suite.tk.AssertNumberOfCalls(suite.T(), "CreateTokens", 1)
+}
+
+func (suite *PasskeysTestSuite) TestFinishRegistrationRejectsOtherCeremony() {
+	authenticator := newSoftAuthenticator()
+	suite.registerPasskey(authenticator)
+
+	challenge, err := suite.service.BeginPasskeyLogin(context.Background(), "foo@bar.com")
+	suite.Nil(err)
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+	passkey, err := suite.service.FinishPasskeyRegistration(ctx, challenge.ChallengeID, "phone",
+		newSoftAuthenticator().register(challenge.Options))
+	suite.Nil(passkey)
+	suite.NotNil(err)
+	suite.Len(suite.stored, 1)
+}
+
+func (suite *PasskeysTestSuite) TestLoginWithClonedPasskey() {
+	authenticator := newSoftAuthenticator()
+	suite.registerPasskey(authenticator)
+	//the original already signed 5 times, the copy is behind
+	suite.stored[0].SignCount = 5
+	suite.passkeys.On("Delete", 3, 1).Return(nil)
+	suite.repo.On("CreateSecurityEvent", mock.MatchedBy(func(event *dbModels.SecurityEvent) bool {
+		return event.UserID == 1 && event.Kind == PasskeyCloneEvent
+	})).Return(nil)
+
+	challenge, err := suite.service.BeginPasskeyLogin(context.Background(), "foo@bar.com")
+	suite.Nil(err)
+	result, err := suite.service.FinishPasskeyLogin(context.Background(), challenge.ChallengeID,
+		authenticator.login(challenge.Options, "1"))
+	suite.Nil(result)
+	suite.NotNil(err)
+	suite.repo.AssertNumberOfCalls(suite.T(), "CreateSecurityEvent", 1)
+	suite.passkeys.AssertCalled(suite.T(), "Delete", 3, 1)
+	suite.repo.AssertNotCalled(suite.T(), "IsNewLoginDevice", "1", mock.Anything)
+	suite.passkeys.AssertNotCalled(suite.T(), "UpdateSignCount", mock.Anything, mock.Anything, mock.Anything)
+	suite.tk.AssertNotCalled(suite.T(), "CreateTokens", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PasskeysTestSuite) TestErrorDetailsLeaveOutDevInfo() {
+	details := passkeyErrorDetails(&protocol.Error{Type: "verification_error", Details: "Error validating origin",
+		DevInfo: "Expected Value: https://shotify.test"})
+	suite.Equal("verification_error: Error validating origin", details)
+}
+
+func (suite *PasskeysTestSuite) TestLoginRequiresUserVerification() {
+	authenticator := newSoftAuthenticator()
+	suite.registerPasskey(authenticator)
+	authenticator.presenceOnly = true
+
+	challenge, err := suite.service.BeginPasskeyLogin(context.Background(), "foo@bar.com")
+	suite.Nil(err)
+	result, err := suite.service.FinishPasskeyLogin(context.Background(), challenge.ChallengeID,
+		authenticator.login(challenge.Options, "1"))
+	suite.Nil(result)
+	suite.NotNil(err)
+	suite.passkeys.AssertNotCalled(suite.T(), "UpdateSignCount", mock.Anything, mock.Anything, mock.Anything)
+	suite.tk.AssertNotCalled(suite.T(), "CreateTokens", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PasskeysTestSuite) TestBeginLoginWithoutPasskeys() {
+	challenge, err := suite.service.BeginPasskeyLogin(context.Background(), "foo@bar.com")
+	suite.Nil(challenge)
+	suite.NotNil(err)
+}
+
+func TestPasskeysTestSuite(t *testing.T) {
+	suite.Run(t, new(PasskeysTestSuite))
+}
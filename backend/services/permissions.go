@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/repo"
+)
+
+//AnonymousViewer stands in for the user id of visitors who aren't logged in
+const AnonymousViewer = IntUserID(repo.AnonymousViewer)
+
+//GetViewerId returns the logged in user, or AnonymousViewer on the paths that let visitors in
+func GetViewerId(ctx context.Context) IntUserID {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return AnonymousViewer
+	}
+	return userId
+}
+
+//GetUserRole returns the role the auth directives stored in ctx for the logged in user
+func GetUserRole(ctx context.Context) (model.Role, error) {
+	role, ok := ctx.Value(helpers.UserRoleKey).(model.Role)
+	if !ok {
+		return "", customErr.Internal("userRole not found in ctx")
+	}
+	return role, nil
+}
+
+//HasRole reports whether the logged in user has one of the given roles
+func HasRole(ctx context.Context, roles ...model.Role) bool {
+	role, err := GetUserRole(ctx)
+	if err != nil {
+		return false
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+//RequireRole returns a forbidden error unless the logged in user has one of the given roles
+func RequireRole(ctx context.Context, roles ...model.Role) error {
+	if !HasRole(ctx, roles...) {
+		return customErr.Forbidden("you don't have the permissions to do this action")
+	}
+	return nil
+}
+
+//IsAdmin reports whether the logged in user is an admin
+func IsAdmin(ctx context.Context) bool {
+	return HasRole(ctx, model.RoleAdmin)
+}
+
+//CanModerate reports whether the logged in user is an admin or a moderator
+func CanModerate(ctx context.Context) bool {
+	return HasRole(ctx, model.RoleAdmin, model.RoleModerator)
+}
+
+//RequireOwnerOrRole lets the owner of a resource through, otherwise it requires one of the given roles
+func RequireOwnerOrRole(ctx context.Context, ownerId IntUserID, roles ...model.Role) error {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return customErr.Internal("userId not found in ctx")
+	}
+	if userId == ownerId {
+		return nil
+	}
+	return RequireRole(ctx, roles...)
+}
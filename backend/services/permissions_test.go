@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/stretchr/testify/suite"
+)
+
+type PermissionsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PermissionsTestSuite) TestRequireRole() {
+	ctx := context.WithValue(context.Background(), helpers.UserRoleKey, model.RoleModerator)
+
+	suite.True(CanModerate(ctx))
+	suite.False(IsAdmin(ctx))
+	suite.Nil(RequireRole(ctx, model.RoleAdmin, model.RoleModerator))
+	suite.NotNil(RequireRole(ctx, model.RoleAdmin))
+	suite.NotNil(RequireRole(context.Background(), model.RoleUser))
+}
+
+func (suite *PermissionsTestSuite) TestRequireOwnerOrRole() {
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(1))
+	ctx = context.WithValue(ctx, helpers.UserRoleKey, model.RoleUser)
+
+	suite.Nil(RequireOwnerOrRole(ctx, IntUserID(1), model.RoleAdmin))
+	suite.NotNil(RequireOwnerOrRole(ctx, IntUserID(2), model.RoleAdmin))
+
+	ctx = context.WithValue(ctx, helpers.UserRoleKey, model.RoleAdmin)
+	suite.Nil(RequireOwnerOrRole(ctx, IntUserID(2), model.RoleAdmin))
+}
+
+func TestPermissionsTestSuite(t *testing.T) {
+	suite.Run(t, new(PermissionsTestSuite))
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	"github.com/gasser707/go-gql-server/middleware"
+	"github.com/gasser707/go-gql-server/utils/auth"
+)
+
+//Brute force protection of the auth endpoints, attempts are counted by email (or user) and by client ip
+var (
+	loginByEmail = auth.Policy{Name: "login_email", Window: 15 * time.Minute, Free: 3, Max: 10,
+		BaseDelay: time.Second, MaxDelay: 30 * time.Second, Lockout: 30 * time.Minute}
+	loginByIp = auth.Policy{Name: "login_ip", Window: 15 * time.Minute, Free: 20, Max: 100,
+		BaseDelay: time.Second, MaxDelay: time.Minute, Lockout: 15 * time.Minute}
+	resetByEmail = auth.Policy{Name: "reset_email", Window: time.Hour, Free: 2, Max: 5,
+		BaseDelay: time.Minute, MaxDelay: 10 * time.Minute, Lockout: time.Hour}
+	resetByIp = auth.Policy{Name: "reset_ip", Window: time.Hour, Free: 10, Max: 30,
+		BaseDelay: 5 * time.Second, MaxDelay: time.Minute, Lockout: time.Hour}
+	resendByEmail = auth.Policy{Name: "resend_email", Window: time.Hour, Free: 2, Max: 5,
+		BaseDelay: time.Minute, MaxDelay: 10 * time.Minute, Lockout: time.Hour}
+	resendByIp = auth.Policy{Name: "resend_ip", Window: time.Hour, Free: 10, Max: 30,
+		BaseDelay: 5 * time.Second, MaxDelay: time.Minute, Lockout: time.Hour}
+	magicLinkByEmail = auth.Policy{Name: "magic_link_email", Window: time.Hour, Free: 3, Max: 6,
+		BaseDelay: time.Minute, MaxDelay: 10 * time.Minute, Lockout: time.Hour}
+	magicLinkByIp = auth.Policy{Name: "magic_link_ip", Window: time.Hour, Free: 10, Max: 30,
+		BaseDelay: 5 * time.Second, MaxDelay: time.Minute, Lockout: time.Hour}
+	validateByIp = auth.Policy{Name: "validate_ip", Window: time.Hour, Free: 5, Max: 20,
+		BaseDelay: time.Second, MaxDelay: time.Minute, Lockout: time.Hour}
+	twoFactorByUser = auth.Policy{Name: "two_factor_user", Window: 15 * time.Minute, Free: 3, Max: 10,
+		BaseDelay: time.Second, MaxDelay: 30 * time.Second, Lockout: 30 * time.Minute}
+	//every old handle stays reserved for a while, so changing it over and over would squat handles
+	handleChangeByUser = auth.Policy{Name: "handle_change_user", Window: 24 * time.Hour, Free: 2, Max: 5,
+		BaseDelay: time.Hour, MaxDelay: 12 * time.Hour, Lockout: 24 * time.Hour}
+	passwordByUser = auth.Policy{Name: "password_user", Window: 15 * time.Minute, Free: 3, Max: 10,
+		BaseDelay: time.Second, MaxDelay: 30 * time.Second, Lockout: 30 * time.Minute}
+)
+
+//clientIp is the ip the request came from, requests without one share a single bucket
+func clientIp(ctx context.Context) string {
+	ha, err := middleware.GetHeaderAccess(ctx)
+	if err != nil || ha.IP == "" {
+		return "unknown"
+	}
+	return ha.IP
+}
+
+func emailSubject(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+//sendAccountLocked emails the owner of an account a failed login locked out a link to unlock it
+func (s *authService) sendAccountLocked(user *dbModels.User) error {
+	token, err := s.tk.CreateStatelessToken(fmt.Sprintf("%d", user.ID), auth.UnlockToken)
+	if err != nil {
+		return err
+	}
+	go s.emailAdaptor.SendAccountLockedEmail("auth@shotify.com", []string{user.Email},
+		user.Username, frontendLink("unlock", token))
+	return nil
+}
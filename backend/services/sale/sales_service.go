@@ -8,6 +8,7 @@ import (
 	dbModels "github.com/gasser707/go-gql-server/databases/models"
 	customErr "github.com/gasser707/go-gql-server/errors"
 	"github.com/gasser707/go-gql-server/graphql/custom"
+	"github.com/gasser707/go-gql-server/graphql/model"
 	"github.com/gasser707/go-gql-server/helpers"
 	"github.com/gasser707/go-gql-server/repo"
 	"github.com/gasser707/go-gql-server/services"
@@ -17,7 +18,7 @@ import (
 
 type SalesServiceInterface interface {
 	BuyImage(ctx context.Context, id string) (*custom.Sale, error)
-	GetSales(ctx context.Context) ([]*custom.Sale, error)
+	GetSales(ctx context.Context, first *int, after *string, last *int, before *string) (*model.SaleConnection, error)
 }
 
 //SalesService implements the usersServiceInterface
@@ -66,29 +67,42 @@ func (s *SalesService) BuyImage(ctx context.Context, id string) (*custom.Sale, e
 	}, nil
 }
 
-func (s *SalesService) GetSales(ctx context.Context) ([]*custom.Sale, error) {
+func (s *SalesService) GetSales(ctx context.Context, first *int, after *string, last *int,
+	before *string) (*model.SaleConnection, error) {
 	userId, ok := ctx.Value(helpers.UserIdKey).(services.IntUserID)
 	if !ok {
 		return nil, customErr.Internal("userId not found in ctx")
 	}
-	dbSales, err := s.Repo.GetAll(int(userId))
+	page, err := repo.NewPage(first, after, last, before)
 	if err != nil {
-		return nil, customErr.DB(err)
+		return nil, err
+	}
+	dbSales, info, err := s.Repo.GetPage(int(userId), page)
+	if err != nil {
+		return nil, err
 	}
 
-	sales := []*custom.Sale{}
+	edges := []*model.SaleEdge{}
+	cursors := []string{}
 	for _, s := range dbSales {
+		createdAt := s.CreatedAt
+		cursor := repo.EncodeCursor(s.CreatedAt, s.ID)
 		sale := &custom.Sale{
 			ID:       fmt.Sprintf("%v", s.ID),
-			Time:     &s.CreatedAt,
+			Time:     &createdAt,
 			ImageID:  fmt.Sprintf("%v", s.ImageID),
 			BuyerID:  fmt.Sprintf("%v", s.BuyerID),
 			SellerID: fmt.Sprintf("%v", s.SellerID),
 			Price:    s.Price,
 		}
-		sales = append(sales, sale)
+		edges = append(edges, &model.SaleEdge{Cursor: cursor, Node: sale})
+		cursors = append(cursors, cursor)
 	}
 
-	return sales, nil
+	return &model.SaleConnection{
+		Edges:      edges,
+		PageInfo:   services.NewPageInfo(info, cursors),
+		TotalCount: info.TotalCount,
+	}, nil
 
 }
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/middleware"
+)
+
+//The kinds of security events recorded on an account
+const (
+	RefreshTokenReuseEvent = "REFRESH_TOKEN_REUSE"
+	PasskeyCloneEvent      = "PASSKEY_CLONED"
+	UnrecognizedLoginEvent = "UNRECOGNIZED_LOGIN"
+	RoleChangedEvent       = "ROLE_CHANGED"
+	SuspendedEvent         = "SUSPENDED"
+	UnsuspendedEvent       = "UNSUSPENDED"
+	ForcedLogoutEvent      = "FORCED_LOGOUT"
+)
+
+//recordSecurityEvent stores a security event of the user with the client the request came from
+func (s *authService) recordSecurityEvent(ctx context.Context, userId string, kind string, details string) error {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return s.repo.CreateSecurityEvent(newSecurityEvent(ctx, id, kind, details))
+}
+
+//newSecurityEvent describes an event of the user that happened now, from the client the request came from
+func newSecurityEvent(ctx context.Context, userId int, kind string, details string) *dbModels.SecurityEvent {
+	event := &dbModels.SecurityEvent{
+		UserID:    userId,
+		Kind:      kind,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+	ha, err := middleware.GetHeaderAccess(ctx)
+	if err == nil {
+		event.IP = ha.IP
+		event.UserAgent = ha.UserAgent
+	}
+	return event
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gasser707/go-gql-server/graphql/custom"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+)
+
+//The user and admins see every field of a user. Everyone else sees the email only when the user shows it,
+//the bio, avatar and images only when the profile isn't private, and never the role or privacy settings.
+
+//canSeeAll reports whether the logged in user is the given user or an admin
+func canSeeAll(ctx context.Context, user *custom.User) bool {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if ok && fmt.Sprintf("%v", userId) == user.ID {
+		return true
+	}
+	return IsAdmin(ctx)
+}
+
+//VisibleEmail returns the email of the user, or nil when the logged in user can't see it
+func VisibleEmail(ctx context.Context, user *custom.User) *string {
+	if !user.ShowEmail && !canSeeAll(ctx, user) {
+		return nil
+	}
+	return &user.Email
+}
+
+//VisibleRole returns the role of the user, or nil when the logged in user can't see it
+func VisibleRole(ctx context.Context, user *custom.User) *model.Role {
+	if !canSeeAll(ctx, user) {
+		return nil
+	}
+	role := model.Role(user.Role)
+	return &role
+}
+
+//ProfileVisible reports whether the logged in user can see the bio, avatar and images of the user
+func ProfileVisible(ctx context.Context, user *custom.User) bool {
+	return !user.PrivateProfile || canSeeAll(ctx, user)
+}
+
+//VisiblePrivacySettings returns the privacy settings of the user, or nil when the logged in user can't see them
+func VisiblePrivacySettings(ctx context.Context, user *custom.User) *model.PrivacySettings {
+	if !canSeeAll(ctx, user) {
+		return nil
+	}
+	return &model.PrivacySettings{ShowEmail: user.ShowEmail, PrivateProfile: user.PrivateProfile}
+}
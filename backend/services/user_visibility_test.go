@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gasser707/go-gql-server/graphql/custom"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserVisibilityTestSuite struct {
+	suite.Suite
+}
+
+func (suite *UserVisibilityTestSuite) viewer(id int, role model.Role) context.Context {
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(id))
+	return context.WithValue(ctx, helpers.UserRoleKey, role)
+}
+
+func (suite *UserVisibilityTestSuite) TestPrivateFields() {
+	user := &custom.User{ID: "1", Email: "foo@bar.com", Role: custom.RoleModerator}
+	owner, other, admin := suite.viewer(1, model.RoleModerator), suite.viewer(2, model.RoleUser),
+		suite.viewer(3, model.RoleAdmin)
+
+	for _, ctx := range []context.Context{owner, admin} {
+		suite.Equal("foo@bar.com", *VisibleEmail(ctx, user))
+		suite.Equal(model.RoleModerator, *VisibleRole(ctx, user))
+		suite.NotNil(VisiblePrivacySettings(ctx, user))
+	}
+	suite.Nil(VisibleEmail(other, user))
+	suite.Nil(VisibleRole(other, user))
+	suite.Nil(VisiblePrivacySettings(other, user))
+	suite.Nil(VisibleEmail(context.Background(), user))
+
+	user.ShowEmail = true
+	suite.Equal("foo@bar.com", *VisibleEmail(other, user))
+	suite.Nil(VisibleRole(other, user))
+}
+
+func (suite *UserVisibilityTestSuite) TestPrivateProfile() {
+	user := &custom.User{ID: "1", Bio: "hi", PrivateProfile: true}
+
+	suite.True(ProfileVisible(suite.viewer(1, model.RoleUser), user))
+	suite.True(ProfileVisible(suite.viewer(3, model.RoleAdmin), user))
+	suite.False(ProfileVisible(suite.viewer(2, model.RoleModerator), user))
+
+	user.PrivateProfile = false
+	suite.True(ProfileVisible(suite.viewer(2, model.RoleUser), user))
+}
+
+func TestUserVisibilityTestSuite(t *testing.T) {
+	suite.Run(t, new(UserVisibilityTestSuite))
+}
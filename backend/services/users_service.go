@@ -20,9 +20,15 @@ import (
 
 type UsersServiceInterface interface {
 	UpdateUser(ctx context.Context, input model.UpdateUserInput) (*custom.User, error)
-	RegisterUser(input model.NewUserInput) (*custom.User, error)
-	GetUsers(ctx context.Context, input *model.UserFilterInput) ([]*custom.User, error)
+	RegisterUser(ctx context.Context, input model.NewUserInput) (*custom.User, error)
+	GetUsers(ctx context.Context, input *model.UserFilterInput, first *int, after *string, last *int,
+		before *string) (*model.UserConnection, error)
 	GetUserById(ID string) (*custom.User, error)
+	GetMe(ctx context.Context) (*custom.User, error)
+	GetUserProfile(ctx context.Context, username string) (*custom.User, error)
+	UpdatePrivacySettings(ctx context.Context, input model.PrivacySettingsInput) (*custom.User, error)
+	GetUserByHandle(ctx context.Context, handle string) (*custom.User, error)
+	ChangeHandle(ctx context.Context, handle string) (*custom.User, error)
 }
 
 //UsersService implements the usersServiceInterface
@@ -33,16 +39,25 @@ type usersService struct {
 	storageOperator cloud.StorageOperatorInterface
 	emailAdaptor    email_svc.EmailAdaptorInterface
 	ValTokenMaker   authUtils.TokenOperatorInterface
+	passwords       *authUtils.PasswordPolicy
+	human           *authUtils.HumanCheck
+	limiter         authUtils.RateLimiterInterface
 }
 
 func NewUsersService(db *sqlx.DB, storageOperator cloud.StorageOperatorInterface,
-	emailAdaptor email_svc.EmailAdaptorInterface) *usersService {
+	emailAdaptor email_svc.EmailAdaptorInterface, limiter authUtils.RateLimiterInterface) *usersService {
 
 	return &usersService{repo: repo.NewUsersRepo(db), storageOperator: storageOperator, emailAdaptor: emailAdaptor,
-		ValTokenMaker: authUtils.NewTokenOperator(nil)}
+		ValTokenMaker: authUtils.NewTokenOperator(nil), passwords: authUtils.DefaultPasswordPolicy(),
+		human: authUtils.DefaultHumanCheck(), limiter: limiter}
 }
 
-func (s *usersService) RegisterUser(input model.NewUserInput) (*custom.User, error) {
+func (s *usersService) RegisterUser(ctx context.Context, input model.NewUserInput) (*custom.User, error) {
+	//before the email lookup, so scripts can't use signups to find out who has an account
+	err := s.human.Check(ctx, authUtils.RegisterAction, input.CaptchaToken, clientIp(ctx))
+	if err != nil {
+		return nil, err
+	}
 
 	c, err := s.repo.CountByEmail(input.Email)
 	if err != nil {
@@ -51,6 +66,14 @@ func (s *usersService) RegisterUser(input model.NewUserInput) (*custom.User, err
 	if c != 0 {
 		return nil, customErr.BadRequest("A user with this email already exists")
 	}
+	err = checkPasswordPolicy(s.passwords, input.Password, input.Username, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := s.newUserHandle(input)
+	if err != nil {
+		return nil, err
+	}
 	pwd, err := helpers.HashPassword(input.Password)
 	if err != nil {
 		return nil, err
@@ -60,6 +83,7 @@ func (s *usersService) RegisterUser(input model.NewUserInput) (*custom.User, err
 		Email:     input.Email,
 		Password:  pwd,
 		Username:  input.Username,
+		Handle:    handle,
 		Bio:       input.Bio,
 		Role:      model.RoleUser.String(),
 		CreatedAt: time.Now(),
@@ -81,48 +105,65 @@ func (s *usersService) RegisterUser(input model.NewUserInput) (*custom.User, err
 	if err != nil {
 		return nil, err
 	}
-	returnedUser := &custom.User{
-		Username: input.Username,
-		Email:    input.Email,
-		Bio:      input.Bio,
-		Avatar:   avatarUrl,
-		Joined:   &insertedUser.CreatedAt,
-		ID:       fmt.Sprintf("%v", userId),
+	//the repo makes up the handle when the user has none
+	createdUser, err := s.repo.GetById(int(userId))
+	if err != nil {
+		return nil, err
 	}
+	returnedUser := toUser(createdUser)
 	token, err := s.ValTokenMaker.CreateStatelessToken(fmt.Sprintf("%v", userId), authUtils.ValidateUserToken)
 	if err != nil {
 		return nil, err
 	}
 	go s.emailAdaptor.SendWelcomeEmail("auth@shotify.com", []string{input.Email},
-		input.Username, fmt.Sprintf("http://%s/validate?token=%s", domain, token))
+		input.Username, frontendLink("validate", token))
 
 	return returnedUser, nil
 }
 
-func (s *usersService) GetUsers(ctx context.Context, input *model.UserFilterInput) ([]*custom.User, error) {
+func (s *usersService) GetUsers(ctx context.Context, input *model.UserFilterInput, first *int, after *string,
+	last *int, before *string) (*model.UserConnection, error) {
 	_, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
 	if !ok {
 		return nil, customErr.Internal("userId not found in ctx")
 	}
-	if input == nil {
-		return s.GetAllUsers()
+	page, err := repo.NewPage(first, after, last, before)
+	if err != nil {
+		return nil, err
 	}
-
-	if input.ID != nil {
-		user, err := s.GetUserById(*input.ID)
-		if err != nil {
-			return nil, err
+	filter := &repo.UserFilter{}
+	if input != nil {
+		if input.ID != nil {
+			id, err := strconv.Atoi(*input.ID)
+			if err != nil {
+				return nil, customErr.BadRequest(err.Error())
+			}
+			filter.ID = &id
+		}
+		//looking users up by email would tell anyone who has an account
+		if input.Email != nil && !IsAdmin(ctx) {
+			return nil, customErr.Forbidden("only admins can look users up by email")
 		}
-		return []*custom.User{user}, nil
+		filter.Email = input.Email
+		filter.Username = input.Username
 	}
 
-	if input.Email != nil {
-		return s.GetUserByEmail(*input.Email)
+	users, info, err := s.repo.GetPage(filter, page)
+	if err != nil {
+		return nil, err
 	}
-	if input.Username != nil {
-		return s.GetUsersByUserName(*input.Username)
+	edges := []*model.UserEdge{}
+	cursors := []string{}
+	for i := range users {
+		cursor := repo.EncodeCursor(users[i].CreatedAt, users[i].ID)
+		edges = append(edges, &model.UserEdge{Cursor: cursor, Node: toUser(&users[i])})
+		cursors = append(cursors, cursor)
 	}
-	return s.GetAllUsers()
+	return &model.UserConnection{
+		Edges:      edges,
+		PageInfo:   NewPageInfo(info, cursors),
+		TotalCount: info.TotalCount,
+	}, nil
 }
 
 func (s *usersService) GetUserById(ID string) (*custom.User, error) {
@@ -135,68 +176,133 @@ func (s *usersService) GetUserById(ID string) (*custom.User, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &custom.User{
-		ID:       fmt.Sprintf("%v", user.ID),
-		Username: user.Username,
-		Email:    user.Email,
-		Avatar:   user.Avatar,
-		Joined:   &user.CreatedAt,
-		Bio:      user.Bio,
-	}, nil
+	return toUser(user), nil
 }
 
-func (s *usersService) GetUserByEmail(email string) ([]*custom.User, error) {
+//GetMe returns the logged in user
+func (s *usersService) GetMe(ctx context.Context) (*custom.User, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	user, err := s.repo.GetById(int(userId))
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
 
-	user, err := s.repo.GetByEmail(email)
+//GetUserProfile finds a user by username for anyone, visitors included, the user resolvers hide what they can't see
+func (s *usersService) GetUserProfile(ctx context.Context, username string) (*custom.User, error) {
+	users, err := s.repo.GetByUsername(username)
 	if err != nil {
 		return nil, err
 	}
-	return []*custom.User{
-		{ID: fmt.Sprintf("%v", user.ID),
-			Username: user.Username,
-			Email:    user.Email,
-			Avatar:   user.Avatar,
-			Joined:   &user.CreatedAt,
-			Bio:      user.Bio,
-		},
-	}, nil
+	if len(users) == 0 {
+		return nil, customErr.NotFound("there is no user with this username")
+	}
+	return toUser(&users[0]), nil
 }
 
-func (s *usersService) GetUsersByUserName(username string) ([]*custom.User, error) {
+//GetUserByHandle finds a user by current or old handle for anyone, visitors included, a client that gets a
+//user whose handle differs from the one it asked for redirects to the new one
+func (s *usersService) GetUserByHandle(ctx context.Context, handle string) (*custom.User, error) {
+	user, err := s.repo.GetByHandle(normalizeHandle(handle))
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
 
-	userList := []*custom.User{}
-	users, err := s.repo.GetByUsername(username)
+//ChangeHandle gives the logged in user a new handle, the old one keeps redirecting to them and no one else can take
+//it for repo.HandleHistoryRetention. The changes are rate limited so no one can squat handles by cycling through them.
+func (s *usersService) ChangeHandle(ctx context.Context, handle string) (*custom.User, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	handle = normalizeHandle(handle)
+	err := validateHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetById(int(userId))
+	if err != nil {
+		return nil, err
+	}
+	if user.Handle != nil && *user.Handle == handle {
+		return toUser(user), nil
+	}
+	_, err = s.limiter.Take(handleChangeByUser, strconv.Itoa(user.ID))
 	if err != nil {
 		return nil, err
 	}
-	for _, user := range users {
-		userList = append(userList, &custom.User{
-			ID:       fmt.Sprintf("%v", user.ID),
-			Username: user.Username,
-			Email:    user.Email,
-			Avatar:   user.Avatar,
-			Joined:   &user.CreatedAt,
-			Bio:      user.Bio})
+	taken, err := s.repo.IsHandleTaken(handle, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, customErr.BadRequest("this handle is taken")
+	}
+	err = s.repo.ChangeHandle(user.ID, handle)
+	if err != nil {
+		return nil, err
 	}
-	return userList, nil
+	user.Handle = &handle
+	return toUser(user), nil
 }
 
-func (s *usersService) GetAllUsers() ([]*custom.User, error) {
-	userList := []*custom.User{}
-	users, err := s.repo.GetAll()
+//newUserHandle checks the handle a new user picked, without one the username is used when it's free, and the
+//repo makes up one when it isn't
+func (s *usersService) newUserHandle(input model.NewUserInput) (*string, error) {
+	if input.Handle != nil {
+		handle := normalizeHandle(*input.Handle)
+		err := validateHandle(handle)
+		if err != nil {
+			return nil, err
+		}
+		taken, err := s.repo.IsHandleTaken(handle, 0)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, customErr.BadRequest("this handle is taken")
+		}
+		return &handle, nil
+	}
+	handle := handleFromUsername(input.Username)
+	if handle == "" {
+		return nil, nil
+	}
+	taken, err := s.repo.IsHandleTaken(handle, 0)
+	if err != nil || taken {
+		return nil, err
+	}
+	return &handle, nil
+}
+
+//UpdatePrivacySettings changes the settings that are given and keeps the others
+func (s *usersService) UpdatePrivacySettings(ctx context.Context,
+	input model.PrivacySettingsInput) (*custom.User, error) {
+	userId, ok := ctx.Value(helpers.UserIdKey).(IntUserID)
+	if !ok {
+		return nil, customErr.Internal("userId not found in ctx")
+	}
+	user, err := s.repo.GetById(int(userId))
 	if err != nil {
 		return nil, err
 	}
-	for _, user := range users {
-		userList = append(userList, &custom.User{
-			ID:       fmt.Sprintf("%v", user.ID),
-			Username: user.Username,
-			Email:    user.Email,
-			Avatar:   user.Avatar,
-			Joined:   &user.CreatedAt,
-			Bio:      user.Bio})
+	if input.ShowEmail != nil {
+		user.ShowEmail = *input.ShowEmail
+	}
+	if input.PrivateProfile != nil {
+		user.PrivateProfile = *input.PrivateProfile
+	}
+	err = s.repo.UpdatePrivacy(user.ID, user.ShowEmail, user.PrivateProfile)
+	if err != nil {
+		return nil, err
 	}
-	return userList, nil
+	return toUser(user), nil
 }
 
 func (s *usersService) UpdateUser(ctx context.Context, input model.UpdateUserInput) (*custom.User, error) {
@@ -213,17 +319,6 @@ func (s *usersService) UpdateUser(ctx context.Context, input model.UpdateUserInp
 	user.Username = input.Username
 	user.Bio = input.Bio
 
-	if input.Email != user.Email {
-		c, err := s.repo.CountByEmail(input.Email)
-		if err != nil {
-			return nil, err
-		}
-		if c != 0 {
-			return nil, customErr.BadRequest("A user with this email already exists")
-		}
-	}
-	user.Email = input.Email
-
 	var newAvatarUrl string
 	if input.Avatar != nil {
 		newAvatarUrl, err = s.storageOperator.UploadImage(input.Avatar.File, "avatar", fmt.Sprintf("%v", userId))
@@ -240,11 +335,25 @@ func (s *usersService) UpdateUser(ctx context.Context, input model.UpdateUserInp
 		return nil, err
 	}
 
-	returnUser := &custom.User{
-		Avatar:   user.Avatar,
-		Email:    user.Email,
-		Username: user.Username,
-		Bio:      user.Bio,
-		ID:       fmt.Sprintf("%v", userId)}
-	return returnUser, nil
+	return toUser(user), nil
+}
+
+func toUser(user *dbModels.User) *custom.User {
+	createdAt := user.CreatedAt
+	handle := ""
+	if user.Handle != nil {
+		handle = *user.Handle
+	}
+	return &custom.User{
+		Handle:         handle,
+		ID:             fmt.Sprintf("%v", user.ID),
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           custom.Role(user.Role),
+		Avatar:         user.Avatar,
+		Joined:         &createdAt,
+		Bio:            user.Bio,
+		ShowEmail:      user.ShowEmail,
+		PrivateProfile: user.PrivateProfile,
+	}
 }
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dbModels "github.com/gasser707/go-gql-server/databases/models"
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/gasser707/go-gql-server/graphql/model"
+	"github.com/gasser707/go-gql-server/helpers"
+	"github.com/gasser707/go-gql-server/repo"
+	repoMocks "github.com/gasser707/go-gql-server/mocks/repo"
+	"github.com/gasser707/go-gql-server/utils/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type UsersServiceTestSuite struct {
+	suite.Suite
+}
+
+func (suite *UsersServiceTestSuite) viewer(id int, role model.Role) context.Context {
+	ctx := context.WithValue(context.Background(), helpers.UserIdKey, IntUserID(id))
+	return context.WithValue(ctx, helpers.UserRoleKey, role)
+}
+
+func (suite *UsersServiceTestSuite) TestGetUsersByEmailIsForAdmins() {
+	mockRepo := repoMocks.UsersRepoInterface{}
+	email := "foo@bar.com"
+	mockRepo.On("GetPage", mock.MatchedBy(func(filter *repo.UserFilter) bool {
+		return *filter.Email == email
+	}), mock.Anything).Return([]dbModels.User{{ID: 1, Email: email, Role: "USER"}}, &repo.PageInfo{TotalCount: 1}, nil)
+
+	s := &usersService{repo: &mockRepo}
+	_, err := s.GetUsers(suite.viewer(2, model.RoleUser), &model.UserFilterInput{Email: &email}, nil, nil, nil, nil)
+	suite.NotNil(err)
+	mockRepo.AssertNotCalled(suite.T(), "GetPage", mock.Anything, mock.Anything)
+
+	connection, err := s.GetUsers(suite.viewer(2, model.RoleAdmin), &model.UserFilterInput{Email: &email}, nil, nil,
+		nil, nil)
+	suite.Nil(err)
+	suite.Equal("1", connection.Edges[0].Node.ID)
+}
+
+func (suite *UsersServiceTestSuite) TestUpdatePrivacySettings() {
+	mockRepo := repoMocks.UsersRepoInterface{}
+	showEmail := true
+	mockRepo.On("GetById", 1).Return(&dbModels.User{ID: 1, Role: "USER", PrivateProfile: true}, nil)
+	mockRepo.On("UpdatePrivacy", 1, true, true).Return(nil)
+
+	s := &usersService{repo: &mockRepo}
+	user, err := s.UpdatePrivacySettings(suite.viewer(1, model.RoleUser),
+		model.PrivacySettingsInput{ShowEmail: &showEmail})
+
+	suite.Nil(err)
+	suite.True(user.ShowEmail)
+	suite.True(user.PrivateProfile)
+	mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UsersServiceTestSuite) TestGetUserProfile() {
+	mockRepo := repoMocks.UsersRepoInterface{}
+	mockRepo.On("GetByUsername", "foo").Return([]dbModels.User{{ID: 1, Username: "foo", Role: "USER"}}, nil)
+	mockRepo.On("GetByUsername", "bar").Return([]dbModels.User{}, nil)
+
+	s := &usersService{repo: &mockRepo}
+	user, err := s.GetUserProfile(context.Background(), "foo")
+	suite.Nil(err)
+	suite.Equal("1", user.ID)
+	//visitors get the profile without the private fields
+	suite.Nil(VisibleEmail(context.Background(), user))
+
+	_, err = s.GetUserProfile(context.Background(), "bar")
+	suite.NotNil(err)
+}
+
+func (suite *UsersServiceTestSuite) TestChangeHandle() {
+	mockRepo := repoMocks.UsersRepoInterface{}
+	old := "bob"
+	mockRepo.On("GetById", 1).Return(&dbModels.User{ID: 1, Role: "USER", Handle: &old}, nil)
+	mockRepo.On("IsHandleTaken", "bobby", 1).Return(false, nil)
+	mockRepo.On("IsHandleTaken", "alice", 1).Return(true, nil)
+	mockRepo.On("ChangeHandle", 1, "bobby").Return(nil)
+
+	s := &usersService{repo: &mockRepo, limiter: auth.NewMemoryRateLimiter()}
+	user, err := s.ChangeHandle(suite.viewer(1, model.RoleUser), "@Bobby")
+	suite.Nil(err)
+	suite.Equal("bobby", user.Handle)
+
+	_, err = s.ChangeHandle(suite.viewer(1, model.RoleUser), "alice")
+	suite.NotNil(err)
+	_, err = s.ChangeHandle(suite.viewer(1, model.RoleUser), "admin")
+	suite.NotNil(err)
+	mockRepo.AssertNumberOfCalls(suite.T(), "ChangeHandle", 1)
+}
+
+func (suite *UsersServiceTestSuite) TestChangeHandleIsRateLimited() {
+	mockRepo := repoMocks.UsersRepoInterface{}
+	old := "bob"
+	mockRepo.On("GetById", 1).Return(&dbModels.User{ID: 1, Role: "USER", Handle: &old}, nil)
+	mockRepo.On("IsHandleTaken", mock.Anything, 1).Return(false, nil)
+	mockRepo.On("ChangeHandle", 1, mock.Anything).Return(nil)
+
+	s := &usersService{repo: &mockRepo, limiter: auth.NewMemoryRateLimiter()}
+	for i := 0; i < handleChangeByUser.Free; i++ {
+		_, err := s.ChangeHandle(suite.viewer(1, model.RoleUser), fmt.Sprintf("bob_%d", i))
+		suite.Nil(err)
+	}
+	_, err := s.ChangeHandle(suite.viewer(1, model.RoleUser), "bob_again")
+	suite.Equal(customErr.RateLimitedType, err.(*gqlerror.Error).Extensions["type"])
+	mockRepo.AssertNumberOfCalls(suite.T(), "ChangeHandle", handleChangeByUser.Free)
+}
+
+func (suite *UsersServiceTestSuite) TestNewUserHandle() {
+	mockRepo := repoMocks.UsersRepoInterface{}
+	mockRepo.On("IsHandleTaken", "bob", 0).Return(false, nil)
+	mockRepo.On("IsHandleTaken", "alice", 0).Return(true, nil)
+	s := &usersService{repo: &mockRepo}
+
+	handle, err := s.newUserHandle(model.NewUserInput{Username: "Bob"})
+	suite.Nil(err)
+	suite.Equal("bob", *handle)
+	//the repo makes up a handle when the username is taken or can't be one
+	handle, err = s.newUserHandle(model.NewUserInput{Username: "Alice"})
+	suite.Nil(err)
+	suite.Nil(handle)
+	handle, err = s.newUserHandle(model.NewUserInput{Username: "Bob Smith"})
+	suite.Nil(err)
+	suite.Nil(handle)
+	//a handle the user picked has to be free
+	picked := "Alice"
+	_, err = s.newUserHandle(model.NewUserInput{Username: "Bob", Handle: &picked})
+	suite.NotNil(err)
+}
+
+func TestUsersServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UsersServiceTestSuite))
+}
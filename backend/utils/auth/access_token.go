@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/matoous/go-nanoid/v2"
+)
+
+//Scopes a personal access token can be granted, browser sessions are never limited by scopes
+const (
+	ImagesReadScope  = "images:read"
+	ImagesWriteScope = "images:write"
+	SalesReadScope   = "sales:read"
+	SalesWriteScope  = "sales:write"
+	UsersReadScope   = "users:read"
+	UsersWriteScope  = "users:write"
+)
+
+var Scopes = []string{ImagesReadScope, ImagesWriteScope, SalesReadScope, SalesWriteScope,
+	UsersReadScope, UsersWriteScope}
+
+//prefix of personal access tokens, it makes leaked tokens easy to spot
+const accessTokenPrefix = "shp_"
+
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+//GenerateAccessToken returns a new personal access token and the hash to store
+func GenerateAccessToken() (string, string, error) {
+	id, err := gonanoid.New(40)
+	if err != nil {
+		return "", "", customErr.Internal(err.Error())
+	}
+	token := accessTokenPrefix + id
+	return token, HashAccessToken(token), nil
+}
+
+func HashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,9 +2,9 @@ package auth
 
 import (
 	"fmt"
-	"github.com/gasser707/go-gql-server/databases"
 	customErr "github.com/gasser707/go-gql-server/errors"
 	"github.com/go-redis/redis/v7"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +16,26 @@ type AuthStoreOperatorInterface interface {
 	DeleteRefresh(string) error
 	DeleteTokens(*AccessDetails) error
 	DeleteAllUserTokens(userId string) error
+	SaveSession(session *Session, td *TokenDetails) error
+	TouchSession(userId string, sessionId string) error
+	FetchSessions(userId string) ([]*Session, error)
+	DeleteSession(userId string, sessionId string) error
+	ConsumeTokenId(jti string, expiresAt int64) error
+	RotateRefresh(userId string, familyId string, refreshUuid string, expiresAt int64) error
+	IsRotatedRefresh(userId string, familyId string, refreshUuid string) (bool, error)
+	RevokeFamily(userId string, familyId string) error
+	SaveChallenge(id string, data string, expiresAt int64) error
+	TakeChallenge(id string) (string, error)
+}
+
+//Session is a single login of a user, it lives as long as its latest refresh token
+type Session struct {
+	ID        string
+	UserId    string
+	UserAgent string
+	IP        string
+	CreatedAt time.Time
+	LastSeen  time.Time
 }
 
 type redisAuthStoreOperator struct {
@@ -30,8 +50,8 @@ var _ AuthStoreOperatorInterface = &redisAuthStoreOperator{}
 
 var _ AuthStoreOperatorInterface = &authStoreOperator{}
 
-func NewRedisStore() *redisAuthStoreOperator {
-	return &redisAuthStoreOperator{client: databases.NewRedisClient()}
+func NewRedisStore(client *redis.Client) *redisAuthStoreOperator {
+	return &redisAuthStoreOperator{client: client}
 }
 
 func NewAuthStore(authClient AuthStoreOperatorInterface) *authStoreOperator {
@@ -63,6 +83,46 @@ func (as *authStoreOperator) DeleteRefresh(refreshUuid string) error {
 	return as.authClient.DeleteRefresh(refreshUuid)
 }
 
+func (as *authStoreOperator) SaveSession(session *Session, td *TokenDetails) error {
+	return as.authClient.SaveSession(session, td)
+}
+
+func (as *authStoreOperator) TouchSession(userId string, sessionId string) error {
+	return as.authClient.TouchSession(userId, sessionId)
+}
+
+func (as *authStoreOperator) FetchSessions(userId string) ([]*Session, error) {
+	return as.authClient.FetchSessions(userId)
+}
+
+func (as *authStoreOperator) DeleteSession(userId string, sessionId string) error {
+	return as.authClient.DeleteSession(userId, sessionId)
+}
+
+func (as *authStoreOperator) ConsumeTokenId(jti string, expiresAt int64) error {
+	return as.authClient.ConsumeTokenId(jti, expiresAt)
+}
+
+func (as *authStoreOperator) RotateRefresh(userId string, familyId string, refreshUuid string, expiresAt int64) error {
+	return as.authClient.RotateRefresh(userId, familyId, refreshUuid, expiresAt)
+}
+
+func (as *authStoreOperator) IsRotatedRefresh(userId string, familyId string, refreshUuid string) (bool, error) {
+	return as.authClient.IsRotatedRefresh(userId, familyId, refreshUuid)
+}
+
+func (as *authStoreOperator) RevokeFamily(userId string, familyId string) error {
+	return as.authClient.RevokeFamily(userId, familyId)
+}
+
+func (as *authStoreOperator) SaveChallenge(id string, data string, expiresAt int64) error {
+	return as.authClient.SaveChallenge(id, data, expiresAt)
+}
+
+func (as *authStoreOperator) TakeChallenge(id string) (string, error) {
+	return as.authClient.TakeChallenge(id)
+}
+
 //Save token metadata to Redis
 func (rs *redisAuthStoreOperator) CreateAuthTokens(userId string, td *TokenDetails) error {
 	at := time.Unix(td.AtExpires, 0) //converting Unix to UTC(to Time object)
@@ -100,7 +160,7 @@ func (rs *redisAuthStoreOperator) FetchAuth(tokenUuid string, csrfUuid string) (
 		return "", customErr.NoAuth(err.Error())
 	}
 	if userId != csrfUserId {
-		return "", customErr.NoAuth(err.Error())
+		return "", customErr.NoAuth("csrf token doesn't match the access token")
 	}
 	return userId, nil
 }
@@ -136,6 +196,12 @@ func (rs *redisAuthStoreOperator) DeleteTokens(authD *AccessDetails) error {
 		return customErr.Internal("something went wrong")
 
 	}
+	if authD.SessionId != "" {
+		_, err = rs.client.Del(sessionKey(authD.UserId, authD.SessionId)).Result()
+		if err != nil {
+			return customErr.Internal(err.Error())
+		}
+	}
 	return nil
 }
 
@@ -157,6 +223,16 @@ func (rs *redisAuthStoreOperator) DeleteAllUserTokens(userId string) error {
 		keys = append(keys, iter.Val())
 	}
 
+	iter = rs.client.Scan(cursor, sessionKey(userId, "*"), 100).Iterator()
+	for iter.Next() {
+		keys = append(keys, iter.Val())
+	}
+
+	//the user isn't logged in anywhere
+	if len(keys) == 0 {
+		return nil
+	}
+
 	//delete refresh token
 	deleted, err := rs.client.Del(keys...).Result()
 	if err != nil {
@@ -174,8 +250,205 @@ func (rs *redisAuthStoreOperator) DeleteAllUserTokens(userId string) error {
 func (rs *redisAuthStoreOperator) DeleteRefresh(refreshUuid string) error {
 	//delete refresh token
 	deleted, err := rs.client.Del(refreshUuid).Result()
-	if err != nil || deleted == 0 {
+	if err != nil {
 		return customErr.Internal(err.Error())
 	}
+	if deleted == 0 {
+		return customErr.NoAuth("refresh token not found")
+	}
 	return nil
 }
+
+func sessionKey(userId string, sessionId string) string {
+	return fmt.Sprintf("session:%s:%s", userId, sessionId)
+}
+
+//Save the session with the uuids of its latest tokens, the creation time is only set once
+func (rs *redisAuthStoreOperator) SaveSession(session *Session, td *TokenDetails) error {
+	key := sessionKey(session.UserId, session.ID)
+	now := time.Now()
+	_, err := rs.client.HSetNX(key, "created_at", now.Unix()).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	_, err = rs.client.HSet(key, map[string]interface{}{
+		"user_agent":   session.UserAgent,
+		"ip":           session.IP,
+		"last_seen":    now.Unix(),
+		"access_uuid":  td.TokenUuid,
+		"refresh_uuid": td.RefreshUuid,
+		"csrf_uuid":    td.CsrfUuid,
+	}).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	_, err = rs.client.ExpireAt(key, time.Unix(td.RtExpires, 0)).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+//Update the last seen time of a session, a revoked session is unauthorized
+func (rs *redisAuthStoreOperator) TouchSession(userId string, sessionId string) error {
+	key := sessionKey(userId, sessionId)
+	exists, err := rs.client.Exists(key).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if exists == 0 {
+		return customErr.NoAuth("session was revoked")
+	}
+	_, err = rs.client.HSet(key, "last_seen", time.Now().Unix()).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+func (rs *redisAuthStoreOperator) FetchSessions(userId string) ([]*Session, error) {
+	sessions := []*Session{}
+	prefix := sessionKey(userId, "")
+	iter := rs.client.Scan(0, sessionKey(userId, "*"), 100).Iterator()
+	for iter.Next() {
+		key := iter.Val()
+		fields, err := rs.client.HGetAll(key).Result()
+		if err != nil {
+			return nil, customErr.Internal(err.Error())
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(fields["last_seen"], 10, 64)
+		sessions = append(sessions, &Session{
+			ID:        strings.TrimPrefix(key, prefix),
+			UserId:    userId,
+			UserAgent: fields["user_agent"],
+			IP:        fields["ip"],
+			CreatedAt: time.Unix(createdAt, 0),
+			LastSeen:  time.Unix(lastSeen, 0),
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, customErr.Internal(err.Error())
+	}
+	return sessions, nil
+}
+
+//Delete a session and the tokens it was issued
+func (rs *redisAuthStoreOperator) DeleteSession(userId string, sessionId string) error {
+	key := sessionKey(userId, sessionId)
+	fields, err := rs.client.HGetAll(key).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if len(fields) == 0 {
+		return customErr.NotFound("session not found")
+	}
+	_, err = rs.client.Del(key, fields["access_uuid"], fields["refresh_uuid"], fields["csrf_uuid"]).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+//Mark the id of a single use token as used until the token expires, using it again is unauthorized
+func (rs *redisAuthStoreOperator) ConsumeTokenId(jti string, expiresAt int64) error {
+	ttl := time.Until(time.Unix(expiresAt, 0))
+	if ttl <= 0 {
+		return customErr.NoAuth("token expired")
+	}
+	consumed, err := rs.client.SetNX(fmt.Sprintf("jti:%s", jti), 1, ttl).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if !consumed {
+		return customErr.NoAuth("this link was already used")
+	}
+	return nil
+}
+
+//The refresh tokens of a session form a family, the family remembers every refresh token rotated out of it
+func familyKey(userId string, familyId string) string {
+	return fmt.Sprintf("family:%s:%s", userId, familyId)
+}
+
+//RotateRefresh deletes the refresh token and records it as used in its family until it would have expired
+func (rs *redisAuthStoreOperator) RotateRefresh(userId string, familyId string, refreshUuid string, expiresAt int64) error {
+	key := familyKey(userId, familyId)
+	var deleted *redis.IntCmd
+	_, err := rs.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(refreshUuid)
+		pipe.SAdd(key, refreshUuid)
+		pipe.ExpireAt(key, time.Unix(expiresAt, 0))
+		return nil
+	})
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if deleted.Val() == 0 {
+		return customErr.NoAuth("refresh token not found")
+	}
+	return nil
+}
+
+func (rs *redisAuthStoreOperator) IsRotatedRefresh(userId string, familyId string, refreshUuid string) (bool, error) {
+	rotated, err := rs.client.SIsMember(familyKey(userId, familyId), refreshUuid).Result()
+	if err != nil {
+		return false, customErr.Internal(err.Error())
+	}
+	return rotated, nil
+}
+
+//RevokeFamily logs the session of the family out, the family itself is kept so replays are still noticed
+func (rs *redisAuthStoreOperator) RevokeFamily(userId string, familyId string) error {
+	key := sessionKey(userId, familyId)
+	fields, err := rs.client.HGetAll(key).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	_, err = rs.client.Del(key, fields["access_uuid"], fields["refresh_uuid"], fields["csrf_uuid"]).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+//A challenge is the state of a ceremony (like a webauthn registration) kept between its two requests
+func challengeKey(id string) string {
+	return fmt.Sprintf("challenge:%s", id)
+}
+
+func (rs *redisAuthStoreOperator) SaveChallenge(id string, data string, expiresAt int64) error {
+	ttl := time.Until(time.Unix(expiresAt, 0))
+	if ttl <= 0 {
+		return customErr.Internal("challenge expired before it was saved")
+	}
+	_, err := rs.client.Set(challengeKey(id), data, ttl).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+//TakeChallenge returns the challenge and deletes it in the same transaction, so it can only be answered once
+func (rs *redisAuthStoreOperator) TakeChallenge(id string) (string, error) {
+	key := challengeKey(id)
+	var get *redis.StringCmd
+	_, err := rs.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		get = pipe.Get(key)
+		pipe.Del(key)
+		return nil
+	})
+	if err == redis.Nil {
+		return "", customErr.NoAuth("challenge not found or expired")
+	}
+	if err != nil {
+		return "", customErr.Internal(err.Error())
+	}
+	return get.Val(), nil
+}
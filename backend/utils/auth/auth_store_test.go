@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/matoous/go-nanoid/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+//AuthStoreTestSuite is the behaviour every AuthStoreOperatorInterface implementation has to share
+type AuthStoreTestSuite struct {
+	suite.Suite
+	newStore func() AuthStoreOperatorInterface
+	store    AuthStoreOperatorInterface
+	userId   string
+}
+
+func (suite *AuthStoreTestSuite) SetupTest() {
+	suite.store = suite.newStore()
+	//users of earlier runs against a shared redis don't get in the way
+	id, err := gonanoid.Generate("123456789", 12)
+	suite.Nil(err)
+	suite.userId = id
+}
+
+func (suite *AuthStoreTestSuite) tokens(userId string, ttl time.Duration) *TokenDetails {
+	base, err := gonanoid.New()
+	suite.Nil(err)
+	expires := time.Now().Add(ttl).Unix()
+	return &TokenDetails{
+		SessionId:   base,
+		BaseUuid:    base,
+		TokenUuid:   base + "@@" + userId,
+		RefreshUuid: base + "++" + userId,
+		CsrfUuid:    base + "$$" + userId,
+		AtExpires:   expires,
+		RtExpires:   expires,
+		CsrfExpires: expires,
+	}
+}
+
+func accessDetails(userId string, td *TokenDetails) *AccessDetails {
+	return &AccessDetails{TokenUuid: td.TokenUuid, CsrfUuid: td.CsrfUuid, UserId: userId, SessionId: td.SessionId}
+}
+
+func (suite *AuthStoreTestSuite) TestCreateFetchAndDeleteTokens() {
+	td := suite.tokens(suite.userId, time.Hour)
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+
+	userId, err := suite.store.FetchAuth(td.TokenUuid, td.CsrfUuid)
+	suite.Nil(err)
+	suite.Equal(suite.userId, userId)
+	userId, err = suite.store.FetchRefresh(td.RefreshUuid)
+	suite.Nil(err)
+	suite.Equal(suite.userId, userId)
+
+	other := suite.tokens(suite.userId+"0", time.Hour)
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId+"0", other))
+	_, err = suite.store.FetchAuth(td.TokenUuid, other.CsrfUuid)
+	suite.NotNil(err)
+
+	suite.Nil(suite.store.DeleteTokens(accessDetails(suite.userId, td)))
+	_, err = suite.store.FetchAuth(td.TokenUuid, td.CsrfUuid)
+	suite.NotNil(err)
+	_, err = suite.store.FetchRefresh(td.RefreshUuid)
+	suite.NotNil(err)
+	suite.NotNil(suite.store.DeleteTokens(accessDetails(suite.userId, td)))
+}
+
+func (suite *AuthStoreTestSuite) TestDeleteRefresh() {
+	td := suite.tokens(suite.userId, time.Hour)
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+
+	suite.Nil(suite.store.DeleteRefresh(td.RefreshUuid))
+	_, err := suite.store.FetchRefresh(td.RefreshUuid)
+	suite.NotNil(err)
+	suite.NotNil(suite.store.DeleteRefresh(td.RefreshUuid))
+}
+
+func (suite *AuthStoreTestSuite) TestExpiry() {
+	td := suite.tokens(suite.userId, time.Second)
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+	suite.Nil(suite.store.SaveSession(&Session{ID: td.SessionId, UserId: suite.userId}, td))
+
+	//expiries are in whole seconds
+	time.Sleep(2 * time.Second)
+	_, err := suite.store.FetchAuth(td.TokenUuid, td.CsrfUuid)
+	suite.NotNil(err)
+	_, err = suite.store.FetchRefresh(td.RefreshUuid)
+	suite.NotNil(err)
+	suite.NotNil(suite.store.TouchSession(suite.userId, td.SessionId))
+	sessions, err := suite.store.FetchSessions(suite.userId)
+	suite.Nil(err)
+	suite.Len(sessions, 0)
+}
+
+func (suite *AuthStoreTestSuite) TestDeleteAllUserTokensOnlyMatchesTheUser() {
+	//the other user's id starts with the id of the user
+	otherId := suite.userId + "1"
+	td := suite.tokens(suite.userId, time.Hour)
+	other := suite.tokens(otherId, time.Hour)
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+	suite.Nil(suite.store.SaveSession(&Session{ID: td.SessionId, UserId: suite.userId}, td))
+	suite.Nil(suite.store.CreateAuthTokens(otherId, other))
+	suite.Nil(suite.store.SaveSession(&Session{ID: other.SessionId, UserId: otherId}, other))
+
+	suite.Nil(suite.store.DeleteAllUserTokens(suite.userId))
+	_, err := suite.store.FetchAuth(td.TokenUuid, td.CsrfUuid)
+	suite.NotNil(err)
+	_, err = suite.store.FetchRefresh(td.RefreshUuid)
+	suite.NotNil(err)
+	sessions, err := suite.store.FetchSessions(suite.userId)
+	suite.Nil(err)
+	suite.Len(sessions, 0)
+
+	userId, err := suite.store.FetchAuth(other.TokenUuid, other.CsrfUuid)
+	suite.Nil(err)
+	suite.Equal(otherId, userId)
+	sessions, err = suite.store.FetchSessions(otherId)
+	suite.Nil(err)
+	suite.Len(sessions, 1)
+
+	//a user without tokens is a no-op
+	suite.Nil(suite.store.DeleteAllUserTokens(suite.userId))
+}
+
+func (suite *AuthStoreTestSuite) TestSessions() {
+	td := suite.tokens(suite.userId, time.Hour)
+	session := &Session{ID: td.SessionId, UserId: suite.userId, UserAgent: "curl", IP: "127.0.0.1"}
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+	suite.Nil(suite.store.SaveSession(session, td))
+	suite.Nil(suite.store.TouchSession(suite.userId, td.SessionId))
+
+	sessions, err := suite.store.FetchSessions(suite.userId)
+	suite.Nil(err)
+	suite.Len(sessions, 1)
+	suite.Equal(td.SessionId, sessions[0].ID)
+	suite.Equal("curl", sessions[0].UserAgent)
+	suite.Equal("127.0.0.1", sessions[0].IP)
+	suite.False(sessions[0].CreatedAt.IsZero())
+
+	suite.Nil(suite.store.DeleteSession(suite.userId, td.SessionId))
+	_, err = suite.store.FetchAuth(td.TokenUuid, td.CsrfUuid)
+	suite.NotNil(err)
+	_, err = suite.store.FetchRefresh(td.RefreshUuid)
+	suite.NotNil(err)
+	suite.NotNil(suite.store.TouchSession(suite.userId, td.SessionId))
+	suite.NotNil(suite.store.DeleteSession(suite.userId, td.SessionId))
+}
+
+func (suite *AuthStoreTestSuite) TestConsumeTokenId() {
+	jti, err := gonanoid.New()
+	suite.Nil(err)
+	expiresAt := time.Now().Add(time.Minute).Unix()
+
+	suite.Nil(suite.store.ConsumeTokenId(jti, expiresAt))
+	suite.NotNil(suite.store.ConsumeTokenId(jti, expiresAt))
+	suite.NotNil(suite.store.ConsumeTokenId(jti+"-expired", time.Now().Add(-time.Minute).Unix()))
+}
+
+func (suite *AuthStoreTestSuite) TestChallenges() {
+	id, err := gonanoid.New()
+	suite.Nil(err)
+	suite.Nil(suite.store.SaveChallenge(id, `{"challenge":"abc"}`, time.Now().Add(time.Minute).Unix()))
+
+	data, err := suite.store.TakeChallenge(id)
+	suite.Nil(err)
+	suite.Equal(`{"challenge":"abc"}`, data)
+	//a challenge is answered once
+	_, err = suite.store.TakeChallenge(id)
+	suite.NotNil(err)
+
+	suite.Nil(suite.store.SaveChallenge(id+"-short", "x", time.Now().Add(time.Second).Unix()))
+	time.Sleep(2 * time.Second)
+	_, err = suite.store.TakeChallenge(id + "-short")
+	suite.NotNil(err)
+}
+
+func (suite *AuthStoreTestSuite) TestRefreshFamilies() {
+	td := suite.tokens(suite.userId, time.Hour)
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+	suite.Nil(suite.store.SaveSession(&Session{ID: td.SessionId, UserId: suite.userId}, td))
+
+	rotated, err := suite.store.IsRotatedRefresh(suite.userId, td.SessionId, td.RefreshUuid)
+	suite.Nil(err)
+	suite.False(rotated)
+	suite.Nil(suite.store.RotateRefresh(suite.userId, td.SessionId, td.RefreshUuid, td.RtExpires))
+	rotated, err = suite.store.IsRotatedRefresh(suite.userId, td.SessionId, td.RefreshUuid)
+	suite.Nil(err)
+	suite.True(rotated)
+	_, err = suite.store.FetchRefresh(td.RefreshUuid)
+	suite.NotNil(err)
+	suite.NotNil(suite.store.RotateRefresh(suite.userId, td.SessionId, td.RefreshUuid, td.RtExpires))
+
+	//the next token of the family
+	next := suite.tokens(suite.userId, time.Hour)
+	next.SessionId = td.SessionId
+	suite.Nil(suite.store.CreateAuthTokens(suite.userId, next))
+	suite.Nil(suite.store.SaveSession(&Session{ID: td.SessionId, UserId: suite.userId}, next))
+
+	suite.Nil(suite.store.RevokeFamily(suite.userId, td.SessionId))
+	_, err = suite.store.FetchRefresh(next.RefreshUuid)
+	suite.NotNil(err)
+	_, err = suite.store.FetchAuth(next.TokenUuid, next.CsrfUuid)
+	suite.NotNil(err)
+	rotated, err = suite.store.IsRotatedRefresh(suite.userId, td.SessionId, td.RefreshUuid)
+	suite.Nil(err)
+	suite.True(rotated)
+	suite.Nil(suite.store.RevokeFamily(suite.userId, td.SessionId))
+}
+
+func (suite *AuthStoreTestSuite) TestConcurrentUse() {
+	jti, err := gonanoid.New()
+	suite.Nil(err)
+	expiresAt := time.Now().Add(time.Minute).Unix()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	consumed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			td := suite.tokens(suite.userId, time.Hour)
+			suite.Nil(suite.store.CreateAuthTokens(suite.userId, td))
+			suite.Nil(suite.store.SaveSession(&Session{ID: td.SessionId, UserId: suite.userId}, td))
+			_, err := suite.store.FetchAuth(td.TokenUuid, td.CsrfUuid)
+			suite.Nil(err)
+			if suite.store.ConsumeTokenId(jti, expiresAt) == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(1, consumed)
+	sessions, err := suite.store.FetchSessions(suite.userId)
+	suite.Nil(err)
+	suite.Len(sessions, 20)
+	suite.Nil(suite.store.DeleteAllUserTokens(suite.userId))
+}
+
+func TestMemoryAuthStore(t *testing.T) {
+	suite.Run(t, &AuthStoreTestSuite{newStore: func() AuthStoreOperatorInterface { return NewMemoryStore() }})
+}
+
+//TestRedisAuthStore runs the same suite against the redis in REDIS_URI, if there is one
+func TestRedisAuthStore(t *testing.T) {
+	if os.Getenv("REDIS_URI") == "" {
+		t.Skip("REDIS_URI isn't set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: os.Getenv("REDIS_URI"), Password: os.Getenv("REDIS_PASSWORD")})
+	defer client.Close()
+	if err := client.Ping().Err(); err != nil {
+		t.Skipf("redis isn't reachable: %v", err)
+	}
+	suite.Run(t, &AuthStoreTestSuite{newStore: func() AuthStoreOperatorInterface { return NewRedisStore(client) }})
+}
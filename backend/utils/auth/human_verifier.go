@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+)
+
+//The actions a human verification token can be asked for
+const (
+	RegisterAction      = "registerUser"
+	PasswordResetAction = "requestPasswordReset"
+)
+
+//The siteverify endpoints of the supported providers, they take the same form and answer the same json
+const (
+	HCaptchaVerifyUrl  = "https://api.hcaptcha.com/siteverify"
+	TurnstileVerifyUrl = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+//HumanVerifierInterface checks a token the client got by solving a captcha
+type HumanVerifierInterface interface {
+	Verify(ctx context.Context, token string, remoteIp string) error
+}
+
+var _ HumanVerifierInterface = &siteVerifier{}
+var _ HumanVerifierInterface = &fakeHumanVerifier{}
+
+//siteVerifier checks tokens with the siteverify api of hCaptcha or Cloudflare Turnstile
+type siteVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewSiteVerifier(verifyUrl string, secret string) *siteVerifier {
+	return &siteVerifier{url: verifyUrl, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (v *siteVerifier) Verify(ctx context.Context, token string, remoteIp string) error {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIp != "" {
+		form.Set("remoteip", remoteIp)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := v.client.Do(req)
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return customErr.Internal(fmt.Sprintf("human verification answered %d", res.StatusCode))
+	}
+
+	result := struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&result)
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if !result.Success {
+		return customErr.HumanVerificationFailed("the human verification failed, please try again")
+	}
+	return nil
+}
+
+//fakeHumanVerifier accepts a single well known token, it stands in for a provider in dev and tests
+type fakeHumanVerifier struct {
+	token string
+}
+
+func NewFakeHumanVerifier(token string) *fakeHumanVerifier {
+	return &fakeHumanVerifier{token: token}
+}
+
+func (v *fakeHumanVerifier) Verify(ctx context.Context, token string, remoteIp string) error {
+	if token != v.token {
+		return customErr.HumanVerificationFailed("the human verification failed, please try again")
+	}
+	return nil
+}
+
+//HumanCheck decides which actions need a human verification token and verifies it, a nil HumanCheck
+//requires nothing
+type HumanCheck struct {
+	verifier HumanVerifierInterface
+	actions  map[string]bool
+}
+
+func NewHumanCheck(verifier HumanVerifierInterface, actions ...string) *HumanCheck {
+	check := &HumanCheck{verifier: verifier, actions: map[string]bool{}}
+	for _, action := range actions {
+		check.actions[action] = true
+	}
+	return check
+}
+
+//Check verifies the token when the action requires one
+func (h *HumanCheck) Check(ctx context.Context, action string, token *string, remoteIp string) error {
+	if h == nil || !h.actions[action] {
+		return nil
+	}
+	if token == nil || strings.TrimSpace(*token) == "" {
+		return customErr.HumanVerificationFailed("please complete the human verification")
+	}
+	return h.verifier.Verify(ctx, *token, remoteIp)
+}
+
+var (
+	defaultHumanCheck     *HumanCheck
+	defaultHumanCheckOnce sync.Once
+)
+
+//DefaultHumanCheck is configured by HUMAN_VERIFIER: hcaptcha, turnstile, fake or empty to turn the check off.
+//The providers need HUMAN_VERIFIER_SECRET, HUMAN_VERIFIER_URL replaces their endpoint and the fake accepts
+//HUMAN_VERIFIER_FAKE_TOKEN. HUMAN_VERIFICATION_REGISTER and HUMAN_VERIFICATION_PASSWORD_RESET (true by default)
+//choose the mutations that require it.
+func DefaultHumanCheck() *HumanCheck {
+	defaultHumanCheckOnce.Do(func() {
+		var verifier HumanVerifierInterface
+		verifyUrl := strings.TrimSpace(os.Getenv("HUMAN_VERIFIER_URL"))
+		secret := os.Getenv("HUMAN_VERIFIER_SECRET")
+		switch provider := strings.ToLower(strings.TrimSpace(os.Getenv("HUMAN_VERIFIER"))); provider {
+		case "":
+			return
+		case "hcaptcha", "turnstile":
+			if secret == "" {
+				log.Panic("HUMAN_VERIFIER_SECRET is needed to use " + provider)
+			}
+			if verifyUrl == "" {
+				verifyUrl = map[string]string{"hcaptcha": HCaptchaVerifyUrl, "turnstile": TurnstileVerifyUrl}[provider]
+			}
+			verifier = NewSiteVerifier(verifyUrl, secret)
+		case "fake":
+			token := os.Getenv("HUMAN_VERIFIER_FAKE_TOKEN")
+			if token == "" {
+				log.Panic("HUMAN_VERIFIER_FAKE_TOKEN is needed to use the fake human verifier")
+			}
+			verifier = NewFakeHumanVerifier(token)
+		default:
+			log.Panicf("unknown HUMAN_VERIFIER %s", provider)
+		}
+
+		actions := []string{}
+		for env, action := range map[string]string{
+			"HUMAN_VERIFICATION_REGISTER":       RegisterAction,
+			"HUMAN_VERIFICATION_PASSWORD_RESET": PasswordResetAction,
+		} {
+			required := true
+			if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					log.Panic(fmt.Errorf("%s: %w", env, err))
+				}
+				required = b
+			}
+			if required {
+				actions = append(actions, action)
+			}
+		}
+		defaultHumanCheck = NewHumanCheck(verifier, actions...)
+	})
+	return defaultHumanCheck
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type HumanVerifierTestSuite struct {
+	suite.Suite
+}
+
+func (suite *HumanVerifierTestSuite) TestSiteVerifier() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("secret", r.PostFormValue("secret"))
+		suite.Equal("1.1.1.1", r.PostFormValue("remoteip"))
+		if r.PostFormValue("response") == "solved" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	verifier := NewSiteVerifier(server.URL, "secret")
+	suite.Nil(verifier.Verify(context.Background(), "solved", "1.1.1.1"))
+	err := verifier.Verify(context.Background(), "guessed", "1.1.1.1")
+	suite.NotNil(err)
+	suite.Equal(customErr.HumanVerificationType, err.(*gqlerror.Error).Extensions["type"])
+}
+
+func (suite *HumanVerifierTestSuite) TestSiteVerifierUnavailable() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewSiteVerifier(server.URL, "secret").Verify(context.Background(), "solved", "")
+	suite.NotNil(err)
+	suite.Equal(http.StatusInternalServerError, err.(*gqlerror.Error).Extensions["code"])
+}
+
+func (suite *HumanVerifierTestSuite) TestHumanCheck() {
+	check := NewHumanCheck(NewFakeHumanVerifier("human"), RegisterAction)
+	ctx := context.Background()
+	human, bot, empty := "human", "bot", ""
+
+	suite.Nil(check.Check(ctx, RegisterAction, &human, ""))
+	suite.NotNil(check.Check(ctx, RegisterAction, &bot, ""))
+	suite.NotNil(check.Check(ctx, RegisterAction, &empty, ""))
+	suite.NotNil(check.Check(ctx, RegisterAction, nil, ""))
+	//the actions that aren't required skip the check
+	suite.Nil(check.Check(ctx, PasswordResetAction, nil, ""))
+
+	var off *HumanCheck
+	suite.Nil(off.Check(ctx, RegisterAction, nil, ""))
+}
+
+func TestHumanVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(HumanVerifierTestSuite))
+}
@@ -0,0 +1,378 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	customErr "github.com/gasser707/go-gql-server/errors"
+)
+
+//Purposes of the keyrings, stateless tokens use the lower cased kind e.g. reset_password
+const (
+	AccessPurpose  = "access"
+	RefreshPurpose = "refresh"
+	CsrfPurpose    = "csrf"
+)
+
+//legacySecrets are the env secrets used when the keyring file has no keys for a purpose
+var legacySecrets = map[string]string{
+	AccessPurpose:               "ACCESS_SECRET",
+	RefreshPurpose:              "REFRESH_SECRET",
+	CsrfPurpose:                 "CSRF_SECRET",
+	ValidateUserToken.purpose(): "VALIDATION_SECRET",
+	ResetToken.purpose():        "PASSWORD_RESET_SECRET",
+	TwoFactorToken.purpose():    "TWO_FACTOR_SECRET",
+	UnlockToken.purpose():       "UNLOCK_ACCOUNT_SECRET",
+	EmailChangeToken.purpose():  "EMAIL_CHANGE_SECRET",
+	MagicLinkToken.purpose():    "MAGIC_LINK_SECRET",
+	NotMeToken.purpose():        "NOT_ME_SECRET",
+}
+
+const legacyKid = "legacy"
+
+//minHmacSecretLength is the shortest HMAC secret accepted, jwt-go signs with any key, an empty one included
+const minHmacSecretLength = 32
+
+//SigningKey is a key of a keyring, keys without a private part can only verify tokens
+type SigningKey struct {
+	Kid       string
+	Method    jwt.SigningMethod
+	ExpiresAt time.Time
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func (k *SigningKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && now.After(k.ExpiresAt)
+}
+
+//Keyring signs tokens with its current key and verifies them with any of its keys that hasn't expired,
+//so keys can be rotated without logging everyone out
+type Keyring struct {
+	current *SigningKey
+	keys    map[string]*SigningKey
+}
+
+func NewKeyring(current string, keys ...*SigningKey) (*Keyring, error) {
+	ring := &Keyring{keys: map[string]*SigningKey{}}
+	for _, key := range keys {
+		if _, ok := ring.keys[key.Kid]; ok {
+			return nil, fmt.Errorf("duplicate kid %s", key.Kid)
+		}
+		ring.keys[key.Kid] = key
+	}
+	ring.current = ring.keys[current]
+	if ring.current == nil {
+		return nil, fmt.Errorf("current kid %s not found", current)
+	}
+	if ring.current.signKey == nil {
+		return nil, fmt.Errorf("current key %s has no private key", current)
+	}
+	//Parse would reject every token signed with it
+	if ring.current.expired(time.Now()) {
+		return nil, fmt.Errorf("current key %s expired", current)
+	}
+	return ring, nil
+}
+
+func NewHmacKey(kid string, secret string, expiresAt time.Time) *SigningKey {
+	return &SigningKey{Kid: kid, Method: jwt.SigningMethodHS256, ExpiresAt: expiresAt,
+		signKey: []byte(secret), verifyKey: []byte(secret)}
+}
+
+//NewRsaKey parses a PKCS1 or PKCS8 private key, or a PKIX public key for a verify only key
+func NewRsaKey(kid string, pemKey string, expiresAt time.Time) (*SigningKey, error) {
+	key := &SigningKey{Kid: kid, Method: jwt.SigningMethodRS256, ExpiresAt: expiresAt}
+	parsed, err := parsePemKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.signKey, key.verifyKey = k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.verifyKey = k
+	default:
+		return nil, fmt.Errorf("key %s is not an RSA key", kid)
+	}
+	return key, nil
+}
+
+//NewEd25519Key parses a PKCS8 private key, or a PKIX public key for a verify only key
+func NewEd25519Key(kid string, pemKey string, expiresAt time.Time) (*SigningKey, error) {
+	key := &SigningKey{Kid: kid, Method: SigningMethodEdDSA, ExpiresAt: expiresAt}
+	parsed, err := parsePemKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.signKey, key.verifyKey = k, k.Public()
+	case ed25519.PublicKey:
+		key.verifyKey = k
+	default:
+		return nil, fmt.Errorf("key %s is not an Ed25519 key", kid)
+	}
+	return key, nil
+}
+
+func parsePemKey(pemKey string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("invalid PEM key")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+}
+
+//Sign signs the claims with the current key and sets its kid header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.Method, claims)
+	token.Header["kid"] = k.current.Kid
+	signed, err := token.SignedString(k.current.signKey)
+	if err != nil {
+		return "", customErr.Internal(err.Error())
+	}
+	return signed, nil
+}
+
+//Parse verifies the token with the key of its kid, tokens signed before kids were added are
+//verified with the legacy key
+func (k *Keyring) Parse(tokenString string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = legacyKid
+		}
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %s", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if key.expired(time.Now()) {
+			return nil, fmt.Errorf("signing key %s expired", kid)
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, customErr.NoAuth(err.Error())
+	}
+	return token, nil
+}
+
+//Jwk is the JSON web key of a public key
+type Jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JwkSet struct {
+	Keys []Jwk `json:"keys"`
+}
+
+//Jwks returns the public keys of the keyring that haven't expired, HMAC keys are never published
+func (k *Keyring) Jwks() *JwkSet {
+	set := &JwkSet{Keys: []Jwk{}}
+	now := time.Now()
+	for _, key := range k.keys {
+		if key.expired(now) {
+			continue
+		}
+		jwk := Jwk{Kid: key.Kid, Alg: key.Method.Alg(), Use: "sig"}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+//Keyrings holds a keyring per token purpose
+type Keyrings struct {
+	rings map[string]*Keyring
+}
+
+func (k *Keyrings) get(purpose string) *Keyring {
+	return k.rings[purpose]
+}
+
+//keyringConfig is the format of the JWT_KEYRING_FILE, one entry per purpose:
+//{"access": {"current": "2022-06", "keys": [{"kid": "2022-06", "alg": "EdDSA", "privateKey": "-----BEGIN..."},
+//{"kid": "legacy", "alg": "HS256", "secret": "...", "expires": "2022-07-01T00:00:00Z"}]}}
+//the legacy kid verifies tokens issued before kids were added, until it expires
+type keyringConfig struct {
+	Current string `json:"current"`
+	Keys    []struct {
+		Kid        string    `json:"kid"`
+		Alg        string    `json:"alg"`
+		Secret     string    `json:"secret"`
+		PrivateKey string    `json:"privateKey"`
+		PublicKey  string    `json:"publicKey"`
+		Expires    time.Time `json:"expires"`
+	} `json:"keys"`
+}
+
+//LoadKeyrings reads the keyrings from the JSON config, purposes missing from it fall back to a
+//single HS256 key from their legacy env secret, which has to be set
+func LoadKeyrings(config []byte) (*Keyrings, error) {
+	configs := map[string]keyringConfig{}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &configs); err != nil {
+			return nil, err
+		}
+	}
+	keyrings := &Keyrings{rings: map[string]*Keyring{}}
+	for purpose, cfg := range configs {
+		keys := []*SigningKey{}
+		for _, c := range cfg.Keys {
+			pemKey := c.PrivateKey
+			if pemKey == "" {
+				pemKey = c.PublicKey
+			}
+			var key *SigningKey
+			var err error
+			switch c.Alg {
+			case jwt.SigningMethodHS256.Alg():
+				if len(c.Secret) < minHmacSecretLength {
+					err = fmt.Errorf("key %s needs a secret of at least %d bytes", c.Kid, minHmacSecretLength)
+					break
+				}
+				key = NewHmacKey(c.Kid, c.Secret, c.Expires)
+			case jwt.SigningMethodRS256.Alg():
+				key, err = NewRsaKey(c.Kid, pemKey, c.Expires)
+			case SigningMethodEdDSA.Alg():
+				key, err = NewEd25519Key(c.Kid, pemKey, c.Expires)
+			default:
+				err = fmt.Errorf("unsupported alg %s", c.Alg)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s keyring: %s", purpose, err.Error())
+			}
+			keys = append(keys, key)
+		}
+		ring, err := NewKeyring(cfg.Current, keys...)
+		if err != nil {
+			return nil, fmt.Errorf("%s keyring: %s", purpose, err.Error())
+		}
+		keyrings.rings[purpose] = ring
+	}
+	for purpose, env := range legacySecrets {
+		if _, ok := keyrings.rings[purpose]; ok {
+			continue
+		}
+		secret := os.Getenv(env)
+		if len(secret) < minHmacSecretLength {
+			return nil, fmt.Errorf("%s keyring: %s must be set to at least %d bytes", purpose, env,
+				minHmacSecretLength)
+		}
+		ring, _ := NewKeyring(legacyKid, NewHmacKey(legacyKid, secret, time.Time{}))
+		keyrings.rings[purpose] = ring
+	}
+	return keyrings, nil
+}
+
+var (
+	defaultKeyrings     *Keyrings
+	defaultKeyringsOnce sync.Once
+)
+
+//DefaultKeyrings loads the keyrings of JWT_KEYRING_FILE once, the server can't start with a broken keyring
+func DefaultKeyrings() *Keyrings {
+	defaultKeyringsOnce.Do(func() {
+		config := []byte{}
+		if path := strings.TrimSpace(os.Getenv("JWT_KEYRING_FILE")); path != "" {
+			var err error
+			config, err = ioutil.ReadFile(path)
+			if err != nil {
+				log.Panic(err)
+			}
+		}
+		keyrings, err := LoadKeyrings(config)
+		if err != nil {
+			log.Panic(err)
+		}
+		defaultKeyrings = keyrings
+	})
+	return defaultKeyrings
+}
+
+//Jwks returns the public keys other services can verify our access tokens with
+func Jwks() *JwkSet {
+	return DefaultKeyrings().get(AccessPurpose).Jwks()
+}
+
+//SigningMethodEdDSA signs tokens with Ed25519 keys, jwt-go v3 doesn't ship it
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+func (m *signingMethodEdDSA) Verify(signingString string, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyringTestSuite struct {
+	suite.Suite
+	rsaPem     string
+	ed25519Pem string
+}
+
+func (suite *KeyringTestSuite) SetupSuite() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Nil(err)
+	suite.rsaPem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	suite.Nil(err)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	suite.Nil(err)
+	suite.ed25519Pem = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+//setLegacySecrets sets the env secrets of every purpose, and returns a function that unsets them
+func (suite *KeyringTestSuite) setLegacySecrets() func() {
+	for _, env := range legacySecrets {
+		os.Setenv(env, strings.Repeat("s", minHmacSecretLength))
+	}
+	return func() {
+		for _, env := range legacySecrets {
+			os.Unsetenv(env)
+		}
+	}
+}
+
+func (suite *KeyringTestSuite) TestSignAndParse() {
+	rsaKey, err := NewRsaKey("rsa", suite.rsaPem, time.Time{})
+	suite.Nil(err)
+	edKey, err := NewEd25519Key("ed", suite.ed25519Pem, time.Time{})
+	suite.Nil(err)
+
+	for _, key := range []*SigningKey{NewHmacKey("hmac", "secret", time.Time{}), rsaKey, edKey} {
+		suite.Run(key.Method.Alg(), func() {
+			ring, err := NewKeyring(key.Kid, key)
+			suite.Nil(err)
+			signed, err := ring.Sign(jwt.MapClaims{"user_id": "1"})
+			suite.Nil(err)
+
+			token, err := ring.Parse(signed)
+			suite.Nil(err)
+			suite.Equal(key.Kid, token.Header["kid"])
+			suite.Equal("1", token.Claims.(jwt.MapClaims)["user_id"])
+		})
+	}
+}
+
+func (suite *KeyringTestSuite) TestRotation() {
+	old := NewHmacKey("old", "old secret", time.Now().Add(time.Hour))
+	oldRing, err := NewKeyring("old", old)
+	suite.Nil(err)
+	oldToken, err := oldRing.Sign(jwt.MapClaims{"user_id": "1"})
+	suite.Nil(err)
+
+	edKey, err := NewEd25519Key("new", suite.ed25519Pem, time.Time{})
+	suite.Nil(err)
+	ring, err := NewKeyring("new", edKey, old)
+	suite.Nil(err)
+	_, err = ring.Parse(oldToken)
+	suite.Nil(err)
+
+	old.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = ring.Parse(oldToken)
+	suite.NotNil(err)
+}
+
+func (suite *KeyringTestSuite) TestRejectsUnknownKidAndAlgConfusion() {
+	edKey, err := NewEd25519Key("ed", suite.ed25519Pem, time.Time{})
+	suite.Nil(err)
+	ring, err := NewKeyring("ed", edKey)
+	suite.Nil(err)
+
+	other, err := NewKeyring("other", NewHmacKey("other", "secret", time.Time{}))
+	suite.Nil(err)
+	signed, err := other.Sign(jwt.MapClaims{"user_id": "1"})
+	suite.Nil(err)
+	_, err = ring.Parse(signed)
+	suite.NotNil(err)
+
+	//an HMAC token claiming the kid of the asymmetric key
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "1"})
+	forged.Header["kid"] = "ed"
+	signed, err = forged.SignedString([]byte("secret"))
+	suite.Nil(err)
+	_, err = ring.Parse(signed)
+	suite.NotNil(err)
+}
+
+func (suite *KeyringTestSuite) TestLegacyTokensWithoutKid() {
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "1"})
+	signed, err := legacy.SignedString([]byte("secret"))
+	suite.Nil(err)
+
+	ring, err := NewKeyring(legacyKid, NewHmacKey(legacyKid, "secret", time.Time{}))
+	suite.Nil(err)
+	_, err = ring.Parse(signed)
+	suite.Nil(err)
+}
+
+func (suite *KeyringTestSuite) TestLoadKeyringsAndJwks() {
+	defer suite.setLegacySecrets()()
+	config, err := json.Marshal(map[string]interface{}{
+		AccessPurpose: map[string]interface{}{
+			"current": "2022-06",
+			"keys": []map[string]interface{}{
+				{"kid": "2022-06", "alg": "EdDSA", "privateKey": suite.ed25519Pem},
+				{"kid": "2022-01", "alg": "RS256", "privateKey": suite.rsaPem},
+				{"kid": legacyKid, "alg": "HS256", "secret": strings.Repeat("s", minHmacSecretLength)},
+			},
+		},
+	})
+	suite.Nil(err)
+
+	keyrings, err := LoadKeyrings(config)
+	suite.Nil(err)
+	suite.NotNil(keyrings.get(RefreshPurpose))
+	suite.NotNil(keyrings.get(ResetToken.purpose()))
+
+	jwks := keyrings.get(AccessPurpose).Jwks()
+	suite.Len(jwks.Keys, 2)
+	kinds := map[string]string{}
+	for _, key := range jwks.Keys {
+		kinds[key.Kid] = key.Kty
+	}
+	suite.Equal(map[string]string{"2022-06": "OKP", "2022-01": "RSA"}, kinds)
+
+	_, err = LoadKeyrings([]byte(`{"access": {"current": "missing", "keys": []}}`))
+	suite.NotNil(err)
+	_, err = LoadKeyrings([]byte(`{"access": {"current": "hmac", "keys": [{"kid": "hmac", "alg": "HS256"}]}}`))
+	suite.NotNil(err)
+	_, err = LoadKeyrings([]byte(`{"access": {"current": "hmac", "keys": [{"kid": "hmac", "alg": "HS256",
+		"secret": "short"}]}}`))
+	suite.NotNil(err)
+}
+
+func (suite *KeyringTestSuite) TestExpiredCurrentKey() {
+	_, err := NewKeyring("old", NewHmacKey("old", "secret", time.Now().Add(-time.Minute)))
+	suite.NotNil(err)
+}
+
+func (suite *KeyringTestSuite) TestLoadKeyringsRequiresLegacySecrets() {
+	defer suite.setLegacySecrets()()
+	_, err := LoadKeyrings(nil)
+	suite.Nil(err)
+
+	//without a secret anyone could sign magic links
+	os.Unsetenv(legacySecrets[MagicLinkToken.purpose()])
+	_, err = LoadKeyrings(nil)
+	suite.NotNil(err)
+
+	os.Setenv(legacySecrets[MagicLinkToken.purpose()], "short")
+	_, err = LoadKeyrings(nil)
+	suite.NotNil(err)
+}
+
+func TestKeyringTestSuite(t *testing.T) {
+	suite.Run(t, new(KeyringTestSuite))
+}
@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+)
+
+//memoryEntry is a string value or a hash, like the redis values the redis store keeps
+type memoryEntry struct {
+	value     string
+	fields    map[string]string
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+//memoryAuthStoreOperator keeps the auth store in memory with the same keys and expiry as the redis store,
+//it is meant for local runs and tests without redis. State is lost on restart and isn't shared between instances.
+type memoryAuthStoreOperator struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+var _ AuthStoreOperatorInterface = &memoryAuthStoreOperator{}
+
+func NewMemoryStore() *memoryAuthStoreOperator {
+	return &memoryAuthStoreOperator{entries: map[string]*memoryEntry{}}
+}
+
+//get returns the entry of key if it hasn't expired, the caller must hold the lock
+func (ms *memoryAuthStoreOperator) get(key string) (*memoryEntry, bool) {
+	entry, ok := ms.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if entry.expired(time.Now()) {
+		delete(ms.entries, key)
+		return nil, false
+	}
+	return entry, true
+}
+
+//del deletes the keys and returns how many existed, the caller must hold the lock
+func (ms *memoryAuthStoreOperator) del(keys ...string) int {
+	deleted := 0
+	for _, key := range keys {
+		if _, ok := ms.get(key); ok {
+			delete(ms.entries, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+//keys returns the live keys matching the filter, the caller must hold the lock
+func (ms *memoryAuthStoreOperator) keys(match func(key string) bool) []string {
+	keys := []string{}
+	for key := range ms.entries {
+		if _, ok := ms.get(key); ok && match(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (ms *memoryAuthStoreOperator) CreateAuthTokens(userId string, td *TokenDetails) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.entries[td.TokenUuid] = &memoryEntry{value: userId, expiresAt: time.Unix(td.AtExpires, 0)}
+	ms.entries[td.RefreshUuid] = &memoryEntry{value: userId, expiresAt: time.Unix(td.RtExpires, 0)}
+	ms.entries[td.CsrfUuid] = &memoryEntry{value: userId, expiresAt: time.Unix(td.CsrfExpires, 0)}
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) FetchAuth(tokenUuid string, csrfUuid string) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	access, ok := ms.get(tokenUuid)
+	if !ok {
+		return "", customErr.NoAuth("access token not found")
+	}
+	csrf, ok := ms.get(csrfUuid)
+	if !ok {
+		return "", customErr.NoAuth("csrf token not found")
+	}
+	if access.value != csrf.value {
+		return "", customErr.NoAuth("csrf token doesn't match the access token")
+	}
+	return access.value, nil
+}
+
+func (ms *memoryAuthStoreOperator) FetchRefresh(refreshUuid string) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	refresh, ok := ms.get(refreshUuid)
+	if !ok {
+		return "", customErr.NoAuth("refresh token not found")
+	}
+	return refresh.value, nil
+}
+
+func (ms *memoryAuthStoreOperator) DeleteRefresh(refreshUuid string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.del(refreshUuid) == 0 {
+		return customErr.NoAuth("refresh token not found")
+	}
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) DeleteTokens(authD *AccessDetails) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	uuid := strings.Split(authD.TokenUuid, "@@")[0]
+	refreshUuid := fmt.Sprintf("%s++%s", uuid, authD.UserId)
+	deletedAt := ms.del(authD.TokenUuid)
+	deletedCsrf := ms.del(authD.CsrfUuid)
+	deletedRt := ms.del(refreshUuid)
+	if deletedAt != 1 || deletedRt != 1 || deletedCsrf != 1 {
+		return customErr.Internal("something went wrong")
+	}
+	if authD.SessionId != "" {
+		ms.del(sessionKey(authD.UserId, authD.SessionId))
+	}
+	return nil
+}
+
+//DeleteAllUserTokens matches the keys the way the redis store scans them
+func (ms *memoryAuthStoreOperator) DeleteAllUserTokens(userId string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	sessionPrefix := sessionKey(userId, "")
+	keys := ms.keys(func(key string) bool {
+		return strings.HasSuffix(key, "++"+userId) || strings.HasSuffix(key, "@@"+userId) ||
+			strings.HasSuffix(key, "$$"+userId) || strings.HasPrefix(key, sessionPrefix)
+	})
+	ms.del(keys...)
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) SaveSession(session *Session, td *TokenDetails) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := sessionKey(session.UserId, session.ID)
+	now := time.Now()
+	entry, ok := ms.get(key)
+	if !ok {
+		entry = &memoryEntry{fields: map[string]string{"created_at": strconv.FormatInt(now.Unix(), 10)}}
+		ms.entries[key] = entry
+	}
+	entry.fields["user_agent"] = session.UserAgent
+	entry.fields["ip"] = session.IP
+	entry.fields["last_seen"] = strconv.FormatInt(now.Unix(), 10)
+	entry.fields["access_uuid"] = td.TokenUuid
+	entry.fields["refresh_uuid"] = td.RefreshUuid
+	entry.fields["csrf_uuid"] = td.CsrfUuid
+	entry.expiresAt = time.Unix(td.RtExpires, 0)
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) TouchSession(userId string, sessionId string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	entry, ok := ms.get(sessionKey(userId, sessionId))
+	if !ok {
+		return customErr.NoAuth("session was revoked")
+	}
+	entry.fields["last_seen"] = strconv.FormatInt(time.Now().Unix(), 10)
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) FetchSessions(userId string) ([]*Session, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	prefix := sessionKey(userId, "")
+	sessions := []*Session{}
+	for _, key := range ms.keys(func(key string) bool { return strings.HasPrefix(key, prefix) }) {
+		fields := ms.entries[key].fields
+		createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(fields["last_seen"], 10, 64)
+		sessions = append(sessions, &Session{
+			ID:        strings.TrimPrefix(key, prefix),
+			UserId:    userId,
+			UserAgent: fields["user_agent"],
+			IP:        fields["ip"],
+			CreatedAt: time.Unix(createdAt, 0),
+			LastSeen:  time.Unix(lastSeen, 0),
+		})
+	}
+	return sessions, nil
+}
+
+func (ms *memoryAuthStoreOperator) DeleteSession(userId string, sessionId string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := sessionKey(userId, sessionId)
+	entry, ok := ms.get(key)
+	if !ok {
+		return customErr.NotFound("session not found")
+	}
+	ms.del(key, entry.fields["access_uuid"], entry.fields["refresh_uuid"], entry.fields["csrf_uuid"])
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) ConsumeTokenId(jti string, expiresAt int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	expires := time.Unix(expiresAt, 0)
+	if !time.Now().Before(expires) {
+		return customErr.NoAuth("token expired")
+	}
+	key := fmt.Sprintf("jti:%s", jti)
+	if _, ok := ms.get(key); ok {
+		return customErr.NoAuth("this link was already used")
+	}
+	ms.entries[key] = &memoryEntry{value: "1", expiresAt: expires}
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) RotateRefresh(userId string, familyId string, refreshUuid string, expiresAt int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := familyKey(userId, familyId)
+	family, ok := ms.get(key)
+	if !ok {
+		family = &memoryEntry{fields: map[string]string{}}
+		ms.entries[key] = family
+	}
+	family.fields[refreshUuid] = "1"
+	family.expiresAt = time.Unix(expiresAt, 0)
+	if ms.del(refreshUuid) == 0 {
+		return customErr.NoAuth("refresh token not found")
+	}
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) IsRotatedRefresh(userId string, familyId string, refreshUuid string) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	family, ok := ms.get(familyKey(userId, familyId))
+	if !ok {
+		return false, nil
+	}
+	_, rotated := family.fields[refreshUuid]
+	return rotated, nil
+}
+
+func (ms *memoryAuthStoreOperator) RevokeFamily(userId string, familyId string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := sessionKey(userId, familyId)
+	entry, ok := ms.get(key)
+	if !ok {
+		return nil
+	}
+	ms.del(key, entry.fields["access_uuid"], entry.fields["refresh_uuid"], entry.fields["csrf_uuid"])
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) SaveChallenge(id string, data string, expiresAt int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	expires := time.Unix(expiresAt, 0)
+	if !time.Now().Before(expires) {
+		return customErr.Internal("challenge expired before it was saved")
+	}
+	ms.entries[challengeKey(id)] = &memoryEntry{value: data, expiresAt: expires}
+	return nil
+}
+
+func (ms *memoryAuthStoreOperator) TakeChallenge(id string) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := challengeKey(id)
+	entry, ok := ms.get(key)
+	if !ok {
+		return "", customErr.NoAuth("challenge not found or expired")
+	}
+	ms.del(key)
+	return entry.value, nil
+}
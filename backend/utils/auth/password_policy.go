@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//The rules a password can fail, they are sent to the client in the rules extension of the error
+const (
+	PasswordTooShort     = "MIN_LENGTH"
+	PasswordTooLong      = "MAX_LENGTH"
+	PasswordPersonalInfo = "PERSONAL_INFO"
+	PasswordBreached     = "BREACHED"
+)
+
+const (
+	defaultPasswordMinLength = 8
+	//argon2id takes any length, the limit only keeps requests from making us hash megabytes
+	defaultPasswordMaxLength = 256
+	//the parts of the username and email shorter than this are too common to reject a password for
+	personalInfoMinLength = 3
+)
+
+//go:embed breached_passwords.txt
+var bundledBreachedPasswords string
+
+//PasswordPolicy is what a password must pass before it is hashed, Check returns the rules it fails
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int
+	RejectPersonal bool
+	Breached       *BreachedPasswords
+}
+
+//Check returns the rules the password of the user with the username and email fails, none if it is fine
+func (p *PasswordPolicy) Check(password string, username string, email string) []string {
+	failed := []string{}
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		failed = append(failed, PasswordTooShort)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		failed = append(failed, PasswordTooLong)
+	}
+	if p.RejectPersonal && containsPersonalInfo(password, username, email) {
+		failed = append(failed, PasswordPersonalInfo)
+	}
+	if p.Breached != nil && p.Breached.Contains(password) {
+		failed = append(failed, PasswordBreached)
+	}
+	return failed
+}
+
+//Describe turns failed rules into a sentence for the user
+func (p *PasswordPolicy) Describe(failed []string) string {
+	reasons := []string{}
+	for _, rule := range failed {
+		switch rule {
+		case PasswordTooShort:
+			reasons = append(reasons, fmt.Sprintf("be at least %d characters long", p.MinLength))
+		case PasswordTooLong:
+			reasons = append(reasons, fmt.Sprintf("be at most %d bytes long", p.MaxLength))
+		case PasswordPersonalInfo:
+			reasons = append(reasons, "not contain your username or email")
+		case PasswordBreached:
+			reasons = append(reasons, "not be a common password or one that appeared in a data breach")
+		}
+	}
+	return "the password must " + strings.Join(reasons, ", ")
+}
+
+func containsPersonalInfo(password string, username string, email string) bool {
+	password = strings.ToLower(password)
+	parts := []string{username}
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		parts = append(parts, email[:at], email)
+	} else {
+		parts = append(parts, email)
+	}
+	for _, part := range parts {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if utf8.RuneCountInString(part) >= personalInfoMinLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
+
+//BreachedPasswords is a set of SHA-1 password hashes bucketed by their first 5 hex characters,
+//the same k-anonymity ranges the pwned passwords api serves, so a big list can be split or
+//swapped for api lookups without touching the callers
+type BreachedPasswords struct {
+	ranges map[string]map[string]struct{}
+}
+
+//LoadBreachedPasswords reads one SHA-1 hash per line, optionally followed by :count,
+//blank lines and lines starting with # are skipped
+func LoadBreachedPasswords(r io.Reader) (*BreachedPasswords, error) {
+	b := &BreachedPasswords{ranges: map[string]map[string]struct{}{}}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.IndexByte(text, ':'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.ToUpper(text)
+		if _, err := hex.DecodeString(text); err != nil || len(text) != sha1.Size*2 {
+			return nil, fmt.Errorf("line %d isn't a SHA-1 hash", line)
+		}
+		b.add(text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *BreachedPasswords) add(hash string) {
+	prefix, suffix := hash[:5], hash[5:]
+	if b.ranges[prefix] == nil {
+		b.ranges[prefix] = map[string]struct{}{}
+	}
+	b.ranges[prefix][suffix] = struct{}{}
+}
+
+//Contains tells if the password is in the list
+func (b *BreachedPasswords) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	_, found := b.ranges[hash[:5]][hash[5:]]
+	return found
+}
+
+var (
+	defaultPasswordPolicy     *PasswordPolicy
+	defaultPasswordPolicyOnce sync.Once
+)
+
+//DefaultPasswordPolicy is configured by PASSWORD_MIN_LENGTH (8 by default), PASSWORD_REJECT_PERSONAL
+//(true by default) and PASSWORD_BREACHED_FILE, a list that replaces the bundled one
+func DefaultPasswordPolicy() *PasswordPolicy {
+	defaultPasswordPolicyOnce.Do(func() {
+		policy := &PasswordPolicy{MinLength: defaultPasswordMinLength, MaxLength: defaultPasswordMaxLength,
+			RejectPersonal: true}
+		if min := strings.TrimSpace(os.Getenv("PASSWORD_MIN_LENGTH")); min != "" {
+			n, err := strconv.Atoi(min)
+			if err != nil {
+				log.Panic(fmt.Errorf("PASSWORD_MIN_LENGTH: %w", err))
+			}
+			policy.MinLength = n
+		}
+		if reject := strings.TrimSpace(os.Getenv("PASSWORD_REJECT_PERSONAL")); reject != "" {
+			b, err := strconv.ParseBool(reject)
+			if err != nil {
+				log.Panic(fmt.Errorf("PASSWORD_REJECT_PERSONAL: %w", err))
+			}
+			policy.RejectPersonal = b
+		}
+		var list io.Reader = strings.NewReader(bundledBreachedPasswords)
+		if path := strings.TrimSpace(os.Getenv("PASSWORD_BREACHED_FILE")); path != "" {
+			f, err := os.Open(path)
+			if err != nil {
+				log.Panic(err)
+			}
+			defer f.Close()
+			list = f
+		}
+		breached, err := LoadBreachedPasswords(list)
+		if err != nil {
+			log.Panic(err)
+		}
+		policy.Breached = breached
+		defaultPasswordPolicy = policy
+	})
+	return defaultPasswordPolicy
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordPolicyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PasswordPolicyTestSuite) TestBundledList() {
+	breached, err := LoadBreachedPasswords(strings.NewReader(bundledBreachedPasswords))
+	suite.Nil(err)
+	suite.True(breached.Contains("password"))
+	suite.True(breached.Contains("123456"))
+	suite.False(breached.Contains("correct horse battery staple"))
+}
+
+func (suite *PasswordPolicyTestSuite) TestLoadBreachedPasswords() {
+	//sha1("hunter2") with a count, in lower case
+	list := "# comment\n\nf3bbbd66a63d4bf1747940578ec3d0103530e21d:17\n"
+	breached, err := LoadBreachedPasswords(strings.NewReader(list))
+	suite.Nil(err)
+	suite.True(breached.Contains("hunter2"))
+	suite.False(breached.Contains("hunter3"))
+
+	_, err = LoadBreachedPasswords(strings.NewReader("not a hash\n"))
+	suite.NotNil(err)
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck() {
+	breached, err := LoadBreachedPasswords(strings.NewReader(bundledBreachedPasswords))
+	suite.Nil(err)
+	policy := &PasswordPolicy{MinLength: 8, MaxLength: 72, RejectPersonal: true, Breached: breached}
+
+	suite.Empty(policy.Check("correct horse battery staple", "foo", "foo@bar.com"))
+	suite.Equal([]string{PasswordTooShort}, policy.Check("", "foo", "foo@bar.com"))
+	suite.Equal([]string{PasswordTooShort, PasswordBreached}, policy.Check("123456", "foo", "foo@bar.com"))
+	suite.Equal([]string{PasswordTooLong}, policy.Check(strings.Repeat("x", 73), "foo", "foo@bar.com"))
+	suite.Equal([]string{PasswordPersonalInfo}, policy.Check("my name is FOO!", "foo", "x@bar.com"))
+	suite.Equal([]string{PasswordPersonalInfo}, policy.Check("mailbox-someone1", "bar", "someone1@bar.com"))
+	//parts this short are everywhere
+	suite.Empty(policy.Check("a long passphrase", "a", "a@bar.com"))
+
+	policy.RejectPersonal = false
+	policy.Breached = nil
+	suite.Empty(policy.Check("foo123456", "foo", "foo@bar.com"))
+}
+
+func (suite *PasswordPolicyTestSuite) TestDescribe() {
+	policy := &PasswordPolicy{MinLength: 10}
+	suite.Equal("the password must be at least 10 characters long, not contain your username or email",
+		policy.Describe([]string{PasswordTooShort, PasswordPersonalInfo}))
+}
+
+func TestPasswordPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordPolicyTestSuite))
+}
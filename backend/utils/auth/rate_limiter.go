@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/go-redis/redis/v7"
+	"github.com/matoous/go-nanoid/v2"
+)
+
+//Policy limits the attempts of an action per subject (an email, an ip...) in a sliding window.
+//After Free attempts every further attempt has to wait twice as long as the one before,
+//and reaching Max attempts locks the subject out.
+type Policy struct {
+	Name      string
+	Window    time.Duration
+	Free      int
+	Max       int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Lockout   time.Duration
+}
+
+//Delay returns how long to wait after the last attempt when there were attempts in the window
+func (p Policy) Delay(attempts int) time.Duration {
+	if attempts < p.Free || p.BaseDelay == 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.Free; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+type RateLimiterInterface interface {
+	//Allow returns a RateLimited error if the subject is locked out or has to wait before trying again,
+	//it doesn't count as an attempt
+	Allow(policy Policy, subject string) error
+	//Take checks and records an attempt in one step, so concurrent attempts can't all slip past the check.
+	//It returns a RateLimited error without recording anything like Allow, and true if the attempt locked
+	//the subject out.
+	Take(policy Policy, subject string) (bool, error)
+	//Refund takes back the newest attempt of the subject, for an attempt that turned out to be legitimate.
+	//A lockout the attempt caused stays.
+	Refund(policy Policy, subject string) error
+	//Clear forgets the attempts and the lockout of the subject
+	Clear(policy Policy, subject string) error
+}
+
+var _ RateLimiterInterface = &redisRateLimiter{}
+
+type redisRateLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) *redisRateLimiter {
+	return &redisRateLimiter{client: client}
+}
+
+func attemptsKey(policy Policy, subject string) string {
+	return fmt.Sprintf("ratelimit:%s:%s", policy.Name, subject)
+}
+
+func lockoutKey(policy Policy, subject string) string {
+	return fmt.Sprintf("lockout:%s:%s", policy.Name, subject)
+}
+
+func (rl *redisRateLimiter) Allow(policy Policy, subject string) error {
+	ttl, err := rl.client.TTL(lockoutKey(policy, subject)).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if ttl > 0 {
+		return customErr.RateLimited(fmt.Sprintf("too many %s attempts, locked out", policy.Name), ttl)
+	}
+
+	key := attemptsKey(policy, subject)
+	now := time.Now()
+	err = rl.trim(key, policy, now)
+	if err != nil {
+		return err
+	}
+	attempts, err := rl.client.ZCard(key).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	delay := policy.Delay(int(attempts))
+	if delay == 0 {
+		return nil
+	}
+	last, err := rl.client.ZRevRangeWithScores(key, 0, 0).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	if len(last) == 0 {
+		return nil
+	}
+	next := time.Unix(0, int64(last[0].Score)*int64(time.Millisecond)).Add(delay)
+	if now.Before(next) {
+		return customErr.RateLimited(fmt.Sprintf("too many %s attempts, slow down", policy.Name), next.Sub(now))
+	}
+	return nil
+}
+
+//takeScript is Allow and the recording of the attempt run as one redis script.
+//It replies {0, lockout ms left}, {1, ms to wait} or {2, 1 if the attempt locked the subject out else 0}.
+var takeScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local free = tonumber(ARGV[4])
+local max = tonumber(ARGV[5])
+local base = tonumber(ARGV[6])
+local maxDelay = tonumber(ARGV[7])
+local lockout = tonumber(ARGV[8])
+
+local ttl = redis.call('PTTL', KEYS[2])
+if ttl > 0 then
+	return {0, ttl}
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. (now - window))
+local attempts = redis.call('ZCARD', KEYS[1])
+if attempts > 0 and attempts >= free and base > 0 then
+	local delay = base
+	local i = free
+	while i < attempts and delay < maxDelay do
+		delay = delay * 2
+		i = i + 1
+	end
+	if maxDelay > 0 and delay > maxDelay then
+		delay = maxDelay
+	end
+	local last = redis.call('ZREVRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	local nextAttempt = tonumber(last[2]) + delay
+	if now < nextAttempt then
+		return {1, nextAttempt - now}
+	end
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[3])
+redis.call('PEXPIRE', KEYS[1], window)
+if max == 0 or attempts + 1 < max then
+	return {2, 0}
+end
+-- the attempts start over once the lockout is over
+if lockout > 0 then
+	redis.call('SET', KEYS[2], 1, 'PX', lockout)
+else
+	redis.call('SET', KEYS[2], 1)
+end
+redis.call('DEL', KEYS[1])
+return {2, 1}
+`)
+
+func (rl *redisRateLimiter) Take(policy Policy, subject string) (bool, error) {
+	member, err := gonanoid.New()
+	if err != nil {
+		return false, customErr.Internal(err.Error())
+	}
+	ms := func(d time.Duration) int64 { return int64(d / time.Millisecond) }
+	reply, err := takeScript.Run(rl.client,
+		[]string{attemptsKey(policy, subject), lockoutKey(policy, subject)},
+		time.Now().UnixNano()/int64(time.Millisecond), ms(policy.Window), member, policy.Free, policy.Max,
+		ms(policy.BaseDelay), ms(policy.MaxDelay), ms(policy.Lockout)).Result()
+	if err != nil {
+		return false, customErr.Internal(err.Error())
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, customErr.Internal(fmt.Sprintf("unexpected rate limiter reply %v", reply))
+	}
+	status, _ := values[0].(int64)
+	value, _ := values[1].(int64)
+	switch status {
+	case 0:
+		return false, customErr.RateLimited(fmt.Sprintf("too many %s attempts, locked out", policy.Name),
+			time.Duration(value)*time.Millisecond)
+	case 1:
+		return false, customErr.RateLimited(fmt.Sprintf("too many %s attempts, slow down", policy.Name),
+			time.Duration(value)*time.Millisecond)
+	}
+	return value == 1, nil
+}
+
+func (rl *redisRateLimiter) Refund(policy Policy, subject string) error {
+	_, err := rl.client.ZPopMax(attemptsKey(policy, subject), 1).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+func (rl *redisRateLimiter) Clear(policy Policy, subject string) error {
+	_, err := rl.client.Del(attemptsKey(policy, subject), lockoutKey(policy, subject)).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+//trim drops the attempts that slid out of the window
+func (rl *redisRateLimiter) trim(key string, policy Policy, now time.Time) error {
+	windowStart := now.Add(-policy.Window).UnixNano() / int64(time.Millisecond)
+	_, err := rl.client.ZRemRangeByScore(key, "-inf", "("+strconv.FormatInt(windowStart, 10)).Result()
+	if err != nil {
+		return customErr.Internal(err.Error())
+	}
+	return nil
+}
+
+var _ RateLimiterInterface = &memoryRateLimiter{}
+
+//memoryRateLimiter keeps the attempts in memory, it is the limiter of the memory auth store
+type memoryRateLimiter struct {
+	mu       sync.Mutex
+	attempts map[string][]time.Time
+	lockouts map[string]time.Time
+}
+
+func NewMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{attempts: map[string][]time.Time{}, lockouts: map[string]time.Time{}}
+}
+
+func (rl *memoryRateLimiter) Allow(policy Policy, subject string) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.allow(policy, subject, time.Now())
+}
+
+func (rl *memoryRateLimiter) Take(policy Policy, subject string) (bool, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	err := rl.allow(policy, subject, now)
+	if err != nil {
+		return false, err
+	}
+	key := attemptsKey(policy, subject)
+	attempts := append(rl.attempts[key], now)
+	rl.attempts[key] = attempts
+	if policy.Max == 0 || len(attempts) < policy.Max {
+		return false, nil
+	}
+	rl.lockouts[lockoutKey(policy, subject)] = now.Add(policy.Lockout)
+	delete(rl.attempts, key)
+	return true, nil
+}
+
+//allow is Allow for a caller that holds the lock
+func (rl *memoryRateLimiter) allow(policy Policy, subject string, now time.Time) error {
+	if until, ok := rl.lockouts[lockoutKey(policy, subject)]; ok && now.Before(until) {
+		return customErr.RateLimited(fmt.Sprintf("too many %s attempts, locked out", policy.Name), until.Sub(now))
+	}
+
+	attempts := rl.trim(attemptsKey(policy, subject), policy, now)
+	delay := policy.Delay(len(attempts))
+	if delay == 0 || len(attempts) == 0 {
+		return nil
+	}
+	next := attempts[len(attempts)-1].Add(delay)
+	if now.Before(next) {
+		return customErr.RateLimited(fmt.Sprintf("too many %s attempts, slow down", policy.Name), next.Sub(now))
+	}
+	return nil
+}
+
+func (rl *memoryRateLimiter) Refund(policy Policy, subject string) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	key := attemptsKey(policy, subject)
+	attempts := rl.attempts[key]
+	if len(attempts) <= 1 {
+		delete(rl.attempts, key)
+	} else {
+		rl.attempts[key] = attempts[:len(attempts)-1]
+	}
+	return nil
+}
+
+func (rl *memoryRateLimiter) Clear(policy Policy, subject string) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	delete(rl.attempts, attemptsKey(policy, subject))
+	delete(rl.lockouts, lockoutKey(policy, subject))
+	return nil
+}
+
+//trim drops the attempts that slid out of the window, the caller must hold the lock
+func (rl *memoryRateLimiter) trim(key string, policy Policy, now time.Time) []time.Time {
+	windowStart := now.Add(-policy.Window)
+	kept := []time.Time{}
+	for _, attempt := range rl.attempts[key] {
+		if !attempt.Before(windowStart) {
+			kept = append(kept, attempt)
+		}
+	}
+	if len(kept) == 0 {
+		delete(rl.attempts, key)
+	} else {
+		rl.attempts[key] = kept
+	}
+	return kept
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/matoous/go-nanoid/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+//RateLimiterTestSuite is the behaviour every RateLimiterInterface implementation has to share
+type RateLimiterTestSuite struct {
+	suite.Suite
+	newLimiter func() RateLimiterInterface
+	limiter    RateLimiterInterface
+	subject    string
+}
+
+func (suite *RateLimiterTestSuite) SetupTest() {
+	suite.limiter = suite.newLimiter()
+	//subjects of earlier runs against a shared redis don't get in the way
+	id, err := gonanoid.New()
+	suite.Nil(err)
+	suite.subject = id
+}
+
+func (suite *RateLimiterTestSuite) TestPolicyDelay() {
+	policy := Policy{Free: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	suite.Equal(time.Duration(0), policy.Delay(0))
+	suite.Equal(time.Duration(0), policy.Delay(2))
+	suite.Equal(time.Second, policy.Delay(3))
+	suite.Equal(2*time.Second, policy.Delay(4))
+	suite.Equal(8*time.Second, policy.Delay(6))
+	suite.Equal(10*time.Second, policy.Delay(7))
+	suite.Equal(10*time.Second, policy.Delay(50))
+
+	suite.Equal(time.Duration(0), Policy{Free: 1}.Delay(5))
+}
+
+func (suite *RateLimiterTestSuite) TestSlowDown() {
+	policy := Policy{Name: "test", Window: time.Minute, Free: 1, Max: 3, BaseDelay: time.Minute, Lockout: time.Minute}
+
+	suite.Nil(suite.limiter.Allow(policy, suite.subject))
+	locked, err := suite.limiter.Take(policy, suite.subject)
+	suite.Nil(err)
+	suite.False(locked)
+	suite.NotNil(suite.limiter.Allow(policy, suite.subject))
+	suite.Nil(suite.limiter.Allow(policy, suite.subject+"b"))
+
+	//a rejected attempt isn't recorded, so it doesn't bring the lockout closer
+	for i := 0; i < 3; i++ {
+		_, err = suite.limiter.Take(policy, suite.subject)
+		suite.NotNil(err)
+	}
+
+	suite.Nil(suite.limiter.Clear(policy, suite.subject))
+	suite.Nil(suite.limiter.Allow(policy, suite.subject))
+}
+
+func (suite *RateLimiterTestSuite) TestLockout() {
+	policy := Policy{Name: "test", Window: time.Minute, Max: 3, Lockout: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		locked, err := suite.limiter.Take(policy, suite.subject)
+		suite.Nil(err)
+		suite.False(locked)
+	}
+	locked, err := suite.limiter.Take(policy, suite.subject)
+	suite.Nil(err)
+	suite.True(locked)
+	_, err = suite.limiter.Take(policy, suite.subject)
+	suite.NotNil(err)
+	suite.NotNil(suite.limiter.Allow(policy, suite.subject))
+
+	suite.Nil(suite.limiter.Clear(policy, suite.subject))
+	suite.Nil(suite.limiter.Allow(policy, suite.subject))
+}
+
+func (suite *RateLimiterTestSuite) TestRefund() {
+	policy := Policy{Name: "test", Window: time.Minute, Free: 2, BaseDelay: time.Minute}
+
+	for i := 0; i < 5; i++ {
+		_, err := suite.limiter.Take(policy, suite.subject)
+		suite.Nil(err)
+		suite.Nil(suite.limiter.Refund(policy, suite.subject))
+	}
+	suite.Nil(suite.limiter.Allow(policy, suite.subject))
+	suite.Nil(suite.limiter.Refund(policy, suite.subject))
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.limiter.Take(policy, suite.subject)
+		suite.Nil(err)
+	}
+	suite.NotNil(suite.limiter.Allow(policy, suite.subject))
+	suite.Nil(suite.limiter.Refund(policy, suite.subject))
+	suite.Nil(suite.limiter.Allow(policy, suite.subject))
+}
+
+func (suite *RateLimiterTestSuite) TestConcurrentTakes() {
+	policy := Policy{Name: "test", Window: time.Minute, Max: 5, Lockout: time.Minute}
+
+	taken := make(chan bool, 50)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.limiter.Take(policy, suite.subject)
+			taken <- err == nil
+		}()
+	}
+	wg.Wait()
+	close(taken)
+	allowed := 0
+	for ok := range taken {
+		if ok {
+			allowed++
+		}
+	}
+	suite.Equal(policy.Max, allowed)
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	suite.Run(t, &RateLimiterTestSuite{newLimiter: func() RateLimiterInterface { return NewMemoryRateLimiter() }})
+}
+
+//TestRedisRateLimiter runs the same suite against the redis in REDIS_URI, if there is one
+func TestRedisRateLimiter(t *testing.T) {
+	if os.Getenv("REDIS_URI") == "" {
+		t.Skip("REDIS_URI isn't set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: os.Getenv("REDIS_URI"), Password: os.Getenv("REDIS_PASSWORD")})
+	defer client.Close()
+	if err := client.Ping().Err(); err != nil {
+		t.Skipf("redis isn't reachable: %v", err)
+	}
+	suite.Run(t, &RateLimiterTestSuite{newLimiter: func() RateLimiterInterface { return NewRedisRateLimiter(client) }})
+}
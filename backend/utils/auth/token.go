@@ -3,7 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -15,28 +15,24 @@ import (
 	"github.com/matoous/go-nanoid/v2"
 )
 
-var (
-	accessSecret        = os.Getenv("ACCESS_SECRET")
-	refreshSecret       = os.Getenv("REFRESH_SECRET")
-	csrfSecret          = os.Getenv("CSRF_SECRET")
-	validationSecret    = os.Getenv("VALIDATION_SECRET")
-	passwordResetSecret = os.Getenv("PASSWORD_RESET_SECRET")
-)
-
 type AccessDetails struct {
 	TokenUuid string
 	CsrfUuid  string
 	UserId    string
 	UserRole  model.Role
+	SessionId string
 }
 
 type RefreshDetails struct {
 	RefreshUuid string
 	UserId      string
 	Role        model.Role
+	SessionId   string
+	ExpiresAt   int64
 }
 
 type TokenDetails struct {
+	SessionId    string
 	AccessToken  string
 	RefreshToken string
 	CsrfToken    string
@@ -49,31 +45,53 @@ type TokenDetails struct {
 	CsrfExpires  int64
 }
 
+//StatelessDetails are the claims of a stateless token, the jti lets single use tokens be consumed.
+//Email is only set in email change tokens, it is the address being confirmed
+type StatelessDetails struct {
+	UserId    string
+	Jti       string
+	ExpiresAt int64
+	Email     string
+}
+
 type StatelessToken string
 
 const (
 	ResetToken        StatelessToken = "RESET_PASSWORD"
 	ValidateUserToken StatelessToken = "VALIDATE_USER"
+	TwoFactorToken    StatelessToken = "TWO_FACTOR"
+	UnlockToken       StatelessToken = "UNLOCK_ACCOUNT"
+	EmailChangeToken  StatelessToken = "CHANGE_EMAIL"
+	MagicLinkToken    StatelessToken = "MAGIC_LINK"
+	NotMeToken        StatelessToken = "NOT_ME"
 )
 
+//purpose is the name of the keyring of the token kind
+func (kind StatelessToken) purpose() string {
+	return strings.ToLower(string(kind))
+}
+
 type TokenOperatorInterface interface {
-	CreateTokens(userId string, userRole model.Role) (*TokenDetails, error)
+	CreateTokens(userId string, userRole model.Role, sessionId string) (*TokenDetails, error)
 	ExtractAccessTokenMetadata(c context.Context) (*AccessDetails, error)
 	ExtractRefreshMetadata(ctx context.Context) (*RefreshDetails, error)
-	ExtractStatelessTokenMetadata(ctx context.Context, tokenString string, kind StatelessToken) (string, error)
+	ExtractStatelessTokenMetadata(ctx context.Context, tokenString string, kind StatelessToken) (*StatelessDetails, error)
 	CreateStatelessToken(userId string, kind StatelessToken) (string, error)
+	CreateEmailChangeToken(userId string, newEmail string) (string, error)
 }
 
 //Token implements the TokenInterface
 var _ TokenOperatorInterface = &tokenOperator{}
 
 type tokenOperator struct {
-	sc *securecookie.SecureCookie
+	sc   *securecookie.SecureCookie
+	keys *Keyrings
 }
 
 func NewTokenOperator(sc *securecookie.SecureCookie) *tokenOperator {
 	return &tokenOperator{
-		sc: sc,
+		sc:   sc,
+		keys: DefaultKeyrings(),
 	}
 }
 
@@ -85,10 +103,8 @@ func (t *tokenOperator) createCsrfToken(userId string, td *TokenDetails) (*Token
 	csrfClaims["user_id"] = userId
 	csrfClaims["exp"] = td.CsrfExpires
 	csrfClaims["csrf_uuid"] = td.CsrfUuid
-	unsignedTK := jwt.NewWithClaims(jwt.SigningMethodHS256, csrfClaims)
-
 	var err error
-	td.CsrfToken, err = unsignedTK.SignedString([]byte(csrfSecret))
+	td.CsrfToken, err = t.keys.get(CsrfPurpose).Sign(csrfClaims)
 	if err != nil {
 		return nil, customErr.Internal(err.Error())
 	}
@@ -106,11 +122,10 @@ func (t *tokenOperator) createRefreshToken(userId string, userRole model.Role, t
 	rtClaims["user_id"] = userId
 	rtClaims["exp"] = td.RtExpires
 	rtClaims["user_role"] = userRole
-
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtClaims)
+	rtClaims["session_id"] = td.SessionId
 
 	var err error
-	td.RefreshToken, err = rt.SignedString([]byte(refreshSecret))
+	td.RefreshToken, err = t.keys.get(RefreshPurpose).Sign(rtClaims)
 	if err != nil {
 		return nil, customErr.Internal(err.Error())
 	}
@@ -129,8 +144,8 @@ func (t *tokenOperator) createAccessToken(userId string, userRole model.Role, td
 	atClaims["user_id"] = userId
 	atClaims["exp"] = td.AtExpires
 	atClaims["user_role"] = userRole
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	td.AccessToken, err = at.SignedString([]byte(accessSecret))
+	atClaims["session_id"] = td.SessionId
+	td.AccessToken, err = t.keys.get(AccessPurpose).Sign(atClaims)
 	if err != nil {
 		return nil, customErr.Internal(err.Error())
 	}
@@ -138,79 +153,101 @@ func (t *tokenOperator) createAccessToken(userId string, userRole model.Role, td
 }
 
 func (t *tokenOperator) CreateStatelessToken(userId string, kind StatelessToken) (string, error) {
+	return t.createStatelessToken(userId, kind, jwt.MapClaims{})
+}
+
+//CreateEmailChangeToken signs the new email into the token so the link only confirms the address it was sent to
+func (t *tokenOperator) CreateEmailChangeToken(userId string, newEmail string) (string, error) {
+	return t.createStatelessToken(userId, EmailChangeToken, jwt.MapClaims{"email": newEmail})
+}
+
+func (t *tokenOperator) createStatelessToken(userId string, kind StatelessToken, tkClaims jwt.MapClaims) (string, error) {
 	var exp int64
 
-	tokenSecret := ""
 	switch kind {
 	case ResetToken:
-		tokenSecret = passwordResetSecret
 		exp = time.Now().Add(time.Minute * 15).Unix() //expires after 15 minutes
 	case ValidateUserToken:
-		tokenSecret = validationSecret
 		exp = time.Now().Add(time.Hour * 24 * 7).Unix() //expires after 7 days
+	case TwoFactorToken:
+		exp = time.Now().Add(time.Minute * 5).Unix() //expires after 5 minutes
+	case UnlockToken:
+		exp = time.Now().Add(time.Hour).Unix() //expires after 1 hour
+	case EmailChangeToken:
+		exp = time.Now().Add(time.Hour).Unix() //expires after 1 hour
+	case MagicLinkToken:
+		exp = time.Now().Add(time.Minute * 15).Unix() //expires after 15 minutes
+	case NotMeToken:
+		exp = time.Now().Add(time.Hour * 24 * 7).Unix() //expires after 7 days
+	}
+	ring := t.keys.get(kind.purpose())
+	if ring == nil {
+		return "", customErr.Internal(fmt.Sprintf("no keyring for %s tokens", kind))
 	}
 
 	tkExpires := exp
-	tkClaims := jwt.MapClaims{}
 	tkClaims["user_id"] = userId
 	tkClaims["exp"] = tkExpires
-
-	unsignedTK := jwt.NewWithClaims(jwt.SigningMethodHS256, tkClaims)
-
-	var err error
-	token, err := unsignedTK.SignedString([]byte(tokenSecret))
+	jti, err := gonanoid.New()
 	if err != nil {
 		return "", customErr.Internal(err.Error())
 	}
+	tkClaims["jti"] = jti
+
+	token, err := ring.Sign(tkClaims)
+	if err != nil {
+		return "", err
+	}
 	return token, nil
 }
 func (t *tokenOperator) ExtractStatelessTokenMetadata(ctx context.Context, tokenString string,
-	kind StatelessToken) (string, error) {
-	tokenSecret := ""
-	switch kind {
-	case ResetToken:
-		tokenSecret = passwordResetSecret
-	case ValidateUserToken:
-		tokenSecret = validationSecret
+	kind StatelessToken) (*StatelessDetails, error) {
+	ring := t.keys.get(kind.purpose())
+	if ring == nil {
+		return nil, customErr.Internal(fmt.Sprintf("no keyring for %s tokens", kind))
 	}
-	token, err := t.verifyStatelessToken(ctx, tokenString, tokenSecret)
+	token, err := t.verifyStatelessToken(ctx, tokenString, ring)
 	if err != nil {
-		return "", customErr.NoAuth(err.Error())
+		return nil, customErr.NoAuth(err.Error())
 	}
 
-	userId, err := extractStatelessToken(token)
+	details, err := extractStatelessToken(token)
 	if err != nil {
-		return "", customErr.NoAuth(err.Error())
+		return nil, customErr.NoAuth(err.Error())
 	}
 
-	return userId, nil
+	return details, nil
 }
 
 func (t *tokenOperator) verifyStatelessToken(ctx context.Context, resetToken string,
-	secret string) (*jwt.Token, error) {
-	token, err := t.parse(ctx, resetToken, secret)
+	ring *Keyring) (*jwt.Token, error) {
+	token, err := t.parse(ctx, resetToken, ring)
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
 
-func extractStatelessToken(token *jwt.Token) (string, error) {
+func extractStatelessToken(token *jwt.Token) (*StatelessDetails, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if ok && token.Valid {
 		userId, userOk := claims["user_id"].(string)
 		if !ok || !userOk {
-			return "", customErr.NoAuth("unauthorized")
+			return nil, customErr.NoAuth("unauthorized")
 
 		}
-		return userId, nil
+		//tokens issued before jtis were added have none
+		jti, _ := claims["jti"].(string)
+		exp, _ := claims["exp"].(float64)
+		email, _ := claims["email"].(string)
+		return &StatelessDetails{UserId: userId, Jti: jti, ExpiresAt: int64(exp), Email: email}, nil
 	}
 
-	return "", customErr.NoAuth("something went wrong")
+	return nil, customErr.NoAuth("something went wrong")
 }
 
-func (t *tokenOperator) CreateTokens(userId string, userRole model.Role) (*TokenDetails, error) {
-	td := &TokenDetails{}
+func (t *tokenOperator) CreateTokens(userId string, userRole model.Role, sessionId string) (*TokenDetails, error) {
+	td := &TokenDetails{SessionId: sessionId}
 
 	td, err := t.createAccessToken(userId, userRole, td)
 	if err != nil {
@@ -227,37 +264,37 @@ func (t *tokenOperator) CreateTokens(userId string, userRole model.Role) (*Token
 	return td, nil
 }
 
-func (t *tokenOperator) verifyCsrfToken(ctx context.Context, secret string) (*jwt.Token, error) {
+func (t *tokenOperator) verifyCsrfToken(ctx context.Context, ring *Keyring) (*jwt.Token, error) {
 	csrfTk, err := t.getCsrfTokenFromHeader(ctx)
 	if err != nil {
 		return nil, err
 	}
-	token, err := t.parse(ctx, csrfTk, secret)
+	token, err := t.parse(ctx, csrfTk, ring)
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
 
-func (t *tokenOperator) verifyAccessToken(ctx context.Context, secret string) (*jwt.Token, error) {
+func (t *tokenOperator) verifyAccessToken(ctx context.Context, ring *Keyring) (*jwt.Token, error) {
 	tokenMap, err := t.getTokensFromCookie(ctx)
 	if err != nil {
 		return nil, err
 	}
 	tokenString := tokenMap["access_token"]
-	token, err := t.parse(ctx, tokenString, secret)
+	token, err := t.parse(ctx, tokenString, ring)
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
-func (t *tokenOperator) verifyRefreshToken(ctx context.Context, secret string) (*jwt.Token, error) {
+func (t *tokenOperator) verifyRefreshToken(ctx context.Context, ring *Keyring) (*jwt.Token, error) {
 	tokenMap, err := t.getTokensFromCookie(ctx)
 	if err != nil {
 		return nil, err
 	}
 	tokenString := tokenMap["refresh_token"]
-	token, err := t.parse(ctx, tokenString, secret)
+	token, err := t.parse(ctx, tokenString, ring)
 	if err != nil {
 		return nil, err
 	}
@@ -273,9 +310,12 @@ func extractAccessToken(token *jwt.Token, ad *AccessDetails) (*AccessDetails, er
 			return nil, customErr.NoAuth("unauthorized")
 
 		}
+		//tokens issued before sessions were tracked have no session id
+		sessionId, _ := claims["session_id"].(string)
 		ad.TokenUuid = accessUuid
 		ad.UserId = userId
 		ad.UserRole = model.Role(role)
+		ad.SessionId = sessionId
 		return ad, nil
 	}
 
@@ -294,9 +334,13 @@ func extractRefreshToken(token *jwt.Token) (*RefreshDetails, error) {
 			return nil, customErr.NoAuth("unauthorized")
 
 		}
+		sessionId, _ := claims["session_id"].(string)
+		exp, _ := claims["exp"].(float64)
 		rd.RefreshUuid = refreshUuid
 		rd.UserId = userId
 		rd.Role = model.Role(role)
+		rd.SessionId = sessionId
+		rd.ExpiresAt = int64(exp)
 		return rd, nil
 	}
 
@@ -337,11 +381,11 @@ func extract(accesToken *jwt.Token, csrfToken *jwt.Token) (*AccessDetails, error
 }
 
 func (t *tokenOperator) ExtractAccessTokenMetadata(ctx context.Context) (*AccessDetails, error) {
-	token, err := t.verifyAccessToken(ctx, accessSecret)
+	token, err := t.verifyAccessToken(ctx, t.keys.get(AccessPurpose))
 	if err != nil {
 		return nil, customErr.NoAuth(err.Error())
 	}
-	csrf, err := t.verifyCsrfToken(ctx, csrfSecret)
+	csrf, err := t.verifyCsrfToken(ctx, t.keys.get(CsrfPurpose))
 	if err != nil {
 		return nil, customErr.NoAuth(err.Error())
 	}
@@ -353,7 +397,7 @@ func (t *tokenOperator) ExtractAccessTokenMetadata(ctx context.Context) (*Access
 }
 
 func (t *tokenOperator) ExtractRefreshMetadata(ctx context.Context) (*RefreshDetails, error) {
-	token, err := t.verifyRefreshToken(ctx, refreshSecret)
+	token, err := t.verifyRefreshToken(ctx, t.keys.get(RefreshPurpose))
 	if err != nil {
 		return nil, customErr.NoAuth(err.Error())
 	}
@@ -391,13 +435,8 @@ func (t *tokenOperator) getCsrfTokenFromHeader(ctx context.Context) (string, err
 	return csrfTk, nil
 }
 
-func (t *tokenOperator) parse(ctx context.Context, tokenString string, secret string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+func (t *tokenOperator) parse(ctx context.Context, tokenString string, ring *Keyring) (*jwt.Token, error) {
+	token, err := ring.Parse(tokenString)
 	if err != nil {
 		return nil, customErr.NoAuth(err.Error())
 
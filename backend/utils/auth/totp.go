@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	customErr "github.com/gasser707/go-gql-server/errors"
+	"github.com/matoous/go-nanoid/v2"
+)
+
+//TOTP parameters from RFC 6238, these are the defaults every authenticator app supports
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	totpSkew   = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+//GenerateTotpSecret returns a new random base32 encoded TOTP secret
+func GenerateTotpSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", customErr.Internal(err.Error())
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+//TotpUri returns the otpauth uri authenticator apps use to enroll the secret, usually shown as a QR code
+func TotpUri(issuer string, account string, secret string) string {
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, account))
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", totpPeriod))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+//TotpCode returns the code of the secret at time t
+func TotpCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", customErr.Internal(err.Error())
+	}
+	return hotp(key, uint64(t.Unix()/totpPeriod)), nil
+}
+
+//ValidateTotp checks the code against the secret allowing one period of clock skew
+func ValidateTotp(secret string, code string, t time.Time) bool {
+	_, ok := MatchTotp(secret, code, t)
+	return ok
+}
+
+//MatchTotp is ValidateTotp that also returns the time step of the code, a code is only accepted once by
+//refusing steps that aren't after the last one used
+func MatchTotp(secret string, code string, t time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	counter := t.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		expected := hotp(key, uint64(counter+int64(i)))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+//hotp is the RFC 4226 HMAC-SHA1 one time password with dynamic truncation
+func hotp(key []byte, counter uint64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+const recoveryCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+//GenerateRecoveryCodes returns n one time codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		code, err := gonanoid.Generate(recoveryCodeAlphabet, 10)
+		if err != nil {
+			return nil, customErr.Internal(err.Error())
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+//HashRecoveryCode returns the hex sha256 of the code ignoring case and dashes, only hashes are stored
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
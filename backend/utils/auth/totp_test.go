@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TotpTestSuite struct {
+	suite.Suite
+}
+
+//the SHA1 vectors of RFC 6238 appendix B, truncated to 6 digits
+func (suite *TotpTestSuite) TestRfcVectors() {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := TotpCode(secret, time.Unix(unix, 0))
+		suite.Nil(err)
+		suite.Equal(expected, code)
+	}
+}
+
+func (suite *TotpTestSuite) TestValidateTotp() {
+	secret, err := GenerateTotpSecret()
+	suite.Nil(err)
+	now := time.Now()
+	code, err := TotpCode(secret, now)
+	suite.Nil(err)
+
+	suite.True(ValidateTotp(secret, code, now))
+	suite.True(ValidateTotp(secret, code, now.Add(totpPeriod*time.Second)))
+	suite.False(ValidateTotp(secret, code, now.Add(3*totpPeriod*time.Second)))
+	suite.False(ValidateTotp(secret, "12345", now))
+}
+
+func (suite *TotpTestSuite) TestMatchTotpReturnsTheTimeStepOfTheCode() {
+	secret, err := GenerateTotpSecret()
+	suite.Nil(err)
+	now := time.Now()
+	code, err := TotpCode(secret, now)
+	suite.Nil(err)
+
+	counter, ok := MatchTotp(secret, code, now)
+	suite.True(ok)
+	suite.Equal(now.Unix()/totpPeriod, counter)
+	//the same code a period later is still the step it was made in
+	counter, ok = MatchTotp(secret, code, now.Add(totpPeriod*time.Second))
+	suite.True(ok)
+	suite.Equal(now.Unix()/totpPeriod, counter)
+}
+
+func (suite *TotpTestSuite) TestTotpUri() {
+	uri := TotpUri("Shotify", "foo@bar.com", "ABC")
+	suite.Equal("otpauth://totp/Shotify:foo@bar.com?algorithm=SHA1&digits=6&issuer=Shotify&period=30&secret=ABC", uri)
+}
+
+func (suite *TotpTestSuite) TestRecoveryCodes() {
+	codes, err := GenerateRecoveryCodes(10)
+	suite.Nil(err)
+	suite.Len(codes, 10)
+	suite.Len(codes[0], 11)
+	suite.Equal(HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(codes[0])))
+	suite.Equal(HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ReplaceAll(codes[0], "-", "")))
+	suite.NotEqual(HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+}
+
+func TestTotpTestSuite(t *testing.T) {
+	suite.Run(t, new(TotpTestSuite))
+}
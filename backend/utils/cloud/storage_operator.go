@@ -67,8 +67,9 @@ func (c *GcsClient) DeleteImage(path string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
+	//an object that is already gone counts as deleted, so a purge that failed halfway can be retried
 	o := c.client.Bucket(utils.BucketName).Object(path)
-	if err := o.Delete(ctx); err != nil {
+	if err := o.Delete(ctx); err != nil && err != gcs.ErrObjectNotExist {
 		return customErr.Internal(err.Error())
 	}
 
@@ -1,6 +1,8 @@
 package emails
 
 import (
+	"time"
+
 	"github.com/matcornic/hermes/v2"
 )
 
@@ -31,10 +33,16 @@ func NewEmailFactory() *emailFactory {
 type EmailType string
 
 const (
-	Welcome       EmailType = "Welcome"
-	ResetPassword EmailType = "ResetPassword"
-	Receipt       EmailType = "Receipt"
-	Promotion     EmailType = "Promotion"
+	Welcome         EmailType = "Welcome"
+	ResetPassword   EmailType = "ResetPassword"
+	Receipt         EmailType = "Receipt"
+	Promotion       EmailType = "Promotion"
+	PasswordChanged EmailType = "PasswordChanged"
+	AccountLocked   EmailType = "AccountLocked"
+	EmailChange     EmailType = "EmailChange"
+	EmailChanged    EmailType = "EmailChanged"
+	MagicLink       EmailType = "MagicLink"
+	NewDevice       EmailType = "NewDevice"
 )
 
 type EmailInterface interface {
@@ -58,6 +66,13 @@ type ReceiptEmailInterface interface {
 	GetPaymentMethod() string
 }
 
+type NewDeviceEmailInterface interface {
+	EmailInterface
+	GetDevice() string
+	GetIP() string
+	GetTime() time.Time
+}
+
 func (e Email) GetType() EmailType {
 	return e.Type
 }
@@ -96,6 +111,18 @@ func (e ReceiptEmail) GetPaymentMethod() string {
 	return e.PaymentMethod
 }
 
+func (e NewDeviceEmail) GetDevice() string {
+	return e.Device
+}
+
+func (e NewDeviceEmail) GetIP() string {
+	return e.IP
+}
+
+func (e NewDeviceEmail) GetTime() time.Time {
+	return e.Time
+}
+
 type Email struct {
 	Type   EmailType
 	Sender string
@@ -117,6 +144,14 @@ type ReceiptEmail struct {
 	PaymentMethod string
 }
 
+//NewDeviceEmail tells about a login from a device the user never logged in from, Link is the "this wasn't me" link
+type NewDeviceEmail struct {
+	Email
+	Device string
+	IP     string
+	Time   time.Time
+}
+
 func (f *emailFactory) GenerateEmailContent(email EmailInterface) string {
 	var emailContent hermes.Email
 	switch email.GetType() {
@@ -124,6 +159,18 @@ func (f *emailFactory) GenerateEmailContent(email EmailInterface) string {
 		emailContent = f.generateWelcomeEmail(email)
 	case ResetPassword:
 		emailContent = f.generateResetPasswordEmail(email.(ResetPassEmailInterface))
+	case PasswordChanged:
+		emailContent = f.generatePasswordChangedEmail(email)
+	case AccountLocked:
+		emailContent = f.generateAccountLockedEmail(email)
+	case EmailChange:
+		emailContent = f.generateEmailChangeEmail(email)
+	case EmailChanged:
+		emailContent = f.generateEmailChangedEmail(email)
+	case MagicLink:
+		emailContent = f.generateMagicLinkEmail(email)
+	case NewDevice:
+		emailContent = f.generateNewDeviceEmail(email.(NewDeviceEmailInterface))
 	default:
 		emailContent = f.generateWelcomeEmail(email)
 	}
@@ -182,3 +229,161 @@ func (f *emailFactory) generateResetPasswordEmail(email ResetPassEmailInterface)
 	}
 	return emailContent
 }
+
+func (f *emailFactory) generatePasswordChangedEmail(email EmailInterface) hermes.Email {
+	emailContent := hermes.Email{
+		Body: hermes.Body{
+			Name: email.GetName(),
+			Intros: []string{
+				"Your password was changed and you were logged out of all your devices.",
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "If you didn't change it, reset your password right away:",
+					Button: hermes.Button{
+						Color: "#DC4D2F",
+						Text:  "Reset your password",
+						Link:  email.GetVerificationLink(),
+					},
+				},
+			},
+			Outros: []string{
+				"Need help, or have questions? Just reply to this email, we'd love to help.",
+			},
+		},
+	}
+	return emailContent
+}
+
+func (f *emailFactory) generateAccountLockedEmail(email EmailInterface) hermes.Email {
+	emailContent := hermes.Email{
+		Body: hermes.Body{
+			Name: email.GetName(),
+			Intros: []string{
+				"There were too many failed login attempts on your account so we locked it for a while.",
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "If it was you, click here to unlock your account right away:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Unlock your account",
+						Link:  email.GetVerificationLink(),
+					},
+				},
+			},
+			Outros: []string{
+				"If it wasn't you, someone may be guessing your password, consider changing it.",
+			},
+		},
+	}
+	return emailContent
+}
+
+func (f *emailFactory) generateEmailChangeEmail(email EmailInterface) hermes.Email {
+	emailContent := hermes.Email{
+		Body: hermes.Body{
+			Name: email.GetName(),
+			Intros: []string{
+				"You asked to use this address for your Shotify account.",
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "To confirm your new email, please click here:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Confirm your email",
+						Link:  email.GetVerificationLink(),
+					},
+				},
+			},
+			Outros: []string{
+				"If you didn't ask for this, you can ignore this email.",
+			},
+		},
+	}
+	return emailContent
+}
+
+func (f *emailFactory) generateEmailChangedEmail(email EmailInterface) hermes.Email {
+	emailContent := hermes.Email{
+		Body: hermes.Body{
+			Name: email.GetName(),
+			Intros: []string{
+				"The email of your account was changed and you were logged out of your other devices, " +
+					"we won't send emails to this address anymore.",
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "If you didn't change it, reset your password right away:",
+					Button: hermes.Button{
+						Color: "#DC4D2F",
+						Text:  "Reset your password",
+						Link:  email.GetVerificationLink(),
+					},
+				},
+			},
+			Outros: []string{
+				"Need help, or have questions? Just reply to this email, we'd love to help.",
+			},
+		},
+	}
+	return emailContent
+}
+
+func (f *emailFactory) generateMagicLinkEmail(email EmailInterface) hermes.Email {
+	emailContent := hermes.Email{
+		Body: hermes.Body{
+			Name: email.GetName(),
+			Intros: []string{
+				"Someone asked for a link to sign in to your Shotify account.",
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "To sign in without your password, please click here. The link works once " +
+						"and expires in 15 minutes:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Sign in to Shotify",
+						Link:  email.GetVerificationLink(),
+					},
+				},
+			},
+			Outros: []string{
+				"If you didn't ask for this, you can ignore this email, nobody can sign in without the link.",
+			},
+		},
+	}
+	return emailContent
+}
+
+func (f *emailFactory) generateNewDeviceEmail(email NewDeviceEmailInterface) hermes.Email {
+	emailContent := hermes.Email{
+		Body: hermes.Body{
+			Name: email.GetName(),
+			Intros: []string{
+				"Your Shotify account was just signed in to from a device you didn't use before.",
+			},
+			Dictionary: []hermes.Entry{
+				{Key: "Device", Value: email.GetDevice()},
+				{Key: "IP address", Value: email.GetIP()},
+				{Key: "Time", Value: email.GetTime().UTC().Format("January 2, 2006 15:04 MST")},
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "If it was you, there's nothing to do. If it wasn't, click here to log out " +
+						"of all your devices and get an email to reset your password:",
+					Button: hermes.Button{
+						Color: "#DC4D2F",
+						Text:  "This wasn't me",
+						Link:  email.GetVerificationLink(),
+					},
+				},
+			},
+			Outros: []string{
+				"Need help, or have questions? Just reply to this email, we'd love to help.",
+			},
+		},
+	}
+	return emailContent
+}
@@ -12,3 +12,6 @@ const BaseGcsUrl = "https://storage.googleapis.com"
 var BucketName = os.Getenv("BUCKET_NAME")
 
 var CookieKey = "cookie-name"
+
+//DeviceCookieKey is the cookie that tells the devices of a user apart in the login history
+var DeviceCookieKey = "device-id"
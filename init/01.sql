@@ -11,7 +11,19 @@ CREATE TABLE users (
 	verified Boolean NOT NULL DEFAULT false,
 	email VARCHAR(80) NOT NULL,
 	UNIQUE(email),
-	password VARCHAR(500) NOT NULL
+	password VARCHAR(500) NOT NULL,
+	totp_secret VARCHAR(64) NOT NULL DEFAULT '',
+	totp_enabled Boolean NOT NULL DEFAULT false,
+	totp_last_counter BIGINT NOT NULL DEFAULT 0,
+	deletion_requested_at TIMESTAMP NULL DEFAULT NULL,
+	deleted_at TIMESTAMP NULL DEFAULT NULL,
+	suspended_at TIMESTAMP NULL DEFAULT NULL,
+	suspended_until TIMESTAMP NULL DEFAULT NULL,
+	suspension_reason VARCHAR(300) NOT NULL DEFAULT '',
+	show_email Boolean NOT NULL DEFAULT false,
+	private_profile Boolean NOT NULL DEFAULT false,
+	handle VARCHAR(30) NULL DEFAULT NULL,
+	UNIQUE(handle)
 );
 
 CREATE TABLE images (
@@ -47,6 +59,76 @@ CREATE TABLE labels (
     UNIQUE(tag, image_id)
 );
 
+CREATE TABLE recovery_codes (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	code_hash CHAR(64) NOT NULL,
+	used Boolean NOT NULL DEFAULT false,
+	UNIQUE(user_id, code_hash)
+);
+
+CREATE TABLE access_tokens (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	scopes VARCHAR(300) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP NULL DEFAULT NULL,
+	expires_at TIMESTAMP NULL DEFAULT NULL,
+	UNIQUE(token_hash)
+);
+
+CREATE TABLE security_events (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	kind VARCHAR(50) NOT NULL,
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	user_agent VARCHAR(300) NOT NULL DEFAULT '',
+	details VARCHAR(300) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE passkeys (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	credential_id VARBINARY(255) NOT NULL UNIQUE,
+	public_key BLOB NOT NULL,
+	attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+	aaguid VARBINARY(16) NOT NULL,
+	sign_count INT UNSIGNED NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP NULL
+);
+
+CREATE TABLE login_events (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	method VARCHAR(20) NOT NULL,
+	success BOOLEAN NOT NULL,
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	user_agent VARCHAR(300) NOT NULL DEFAULT '',
+	device CHAR(64) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE handle_history (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	user_id int NOT NULL,
+	handle VARCHAR(30) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(handle)
+);
+
+CREATE TABLE follows (
+	id int NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	follower_id int NOT NULL,
+	followed_id int NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(follower_id, followed_id)
+);
+
 
 ALTER TABLE images ADD CONSTRAINT image_user_fkey FOREIGN KEY (user_id) REFERENCES users(id);
 
@@ -57,3 +139,22 @@ ALTER TABLE sales ADD CONSTRAINT sale_buyer_fkey FOREIGN KEY (buyer_id) REFERENC
 
 
 ALTER TABLE labels ADD CONSTRAINT label_image_fkey FOREIGN KEY (image_id) REFERENCES images(id) ON DELETE CASCADE;
+ALTER TABLE recovery_codes ADD CONSTRAINT recovery_code_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE access_tokens ADD CONSTRAINT access_token_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE security_events ADD CONSTRAINT security_event_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE passkeys ADD CONSTRAINT passkey_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE login_events ADD CONSTRAINT login_event_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE handle_history ADD CONSTRAINT handle_history_user_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE follows ADD CONSTRAINT follow_follower_fkey FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE follows ADD CONSTRAINT follow_followed_fkey FOREIGN KEY (followed_id) REFERENCES users(id) ON DELETE CASCADE;
+
+
+CREATE INDEX users_created_idx ON users(created_at, id);
+CREATE INDEX images_created_idx ON images(created_at, id);
+CREATE INDEX sales_created_idx ON sales(created_at, id);
+CREATE INDEX security_events_user_idx ON security_events(user_id, created_at);
+CREATE INDEX users_deletion_idx ON users(deletion_requested_at);
+CREATE INDEX login_events_user_idx ON login_events(user_id, created_at);
+CREATE INDEX login_events_device_idx ON login_events(user_id, device);
+CREATE INDEX follows_follower_idx ON follows(follower_id, created_at);
+CREATE INDEX follows_followed_idx ON follows(followed_id, created_at);